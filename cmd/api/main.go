@@ -9,6 +9,7 @@ import (
 
 	"creative-service/internal/config"
 	"creative-service/internal/httpapi"
+	"creative-service/internal/meta"
 	"creative-service/internal/s3"
 	"creative-service/internal/secrets"
 	"creative-service/internal/service"
@@ -55,52 +56,120 @@ func main() {
 	}
 	log.Println("S3 client initialized for bucket:", cfg.S3BucketName)
 
-	sem := service.NewSemaphore(cfg.MaxConcurrency)
+	sched := service.NewScheduler(cfg.MaxConcurrency, cfg.MaxConcurrencyPerAdAccount, cfg.MaxConcurrencyPerToken)
+
+	meta.DefaultUsageTracker.Threshold = float64(cfg.MetaThrottleThreshold)
+	meta.DefaultUsageTracker.MaxDelay = cfg.MetaMaxThrottleDelay
+	meta.DefaultUsageTracker.MaxWait = cfg.MetaMaxThrottleWait
+	meta.DefaultBreakers.Failures = cfg.MetaBreakerFailures
+	meta.DefaultBreakers.OpenFor = cfg.MetaBreakerOpenFor
+
+	metaClients := meta.NewClientFactory(cfg.BaseURL, cfg.APIVersion, cfg.HTTPTimeout)
 	tokens := secrets.EnvResolver{}
 
 	creativeSync := &service.CreativeSyncService{
 		Store: st,
 		Tokens: tokens,
 		S3: s3Client,
-		BaseURL: cfg.BaseURL,
-		APIVersion: cfg.APIVersion,
-		HTTPTimeout: cfg.HTTPTimeout,
-		Sem: sem,
+		Meta: metaClients,
+		Sched: sched,
 	}
 
 	campaigns := &service.CampaignService{
 		Store: st,
 		Tokens: tokens,
-		BaseURL: cfg.BaseURL,
-		APIVersion: cfg.APIVersion,
-		HTTPTimeout: cfg.HTTPTimeout,
-		Sem: sem,
+		Meta: metaClients,
+		Sched: sched,
 	}
 
 	adsets := &service.AdSetService{
 		Store: st,
 		Tokens: tokens,
-		BaseURL: cfg.BaseURL,
-		APIVersion: cfg.APIVersion,
-		HTTPTimeout: cfg.HTTPTimeout,
-		Sem: sem,
+		Meta: metaClients,
+		Sched: sched,
 	}
 
 	ads := &service.AdService{
 		Store: st,
 		Tokens: tokens,
-		BaseURL: cfg.BaseURL,
-		APIVersion: cfg.APIVersion,
-		HTTPTimeout: cfg.HTTPTimeout,
-		Sem: sem,
+		Meta: metaClients,
+		Sched: sched,
+	}
+
+	bulk := &service.BulkService{
+		Store: st,
+		Tokens: tokens,
+		Meta: metaClients,
+		Sched: sched,
+	}
+
+	copies := &service.CopyService{
+		Store: st,
+		Tokens: tokens,
+		Meta: metaClients,
+		Sched: sched,
+	}
+
+	targetingSvc := &service.TargetingService{
+		Store: st,
+		Tokens: tokens,
+		Meta: metaClients,
+		Sched: sched,
+		CacheTTL: cfg.TargetingCacheTTL,
+	}
+
+	scheduled := &service.ScheduledActionService{
+		Store: st,
+		Tokens: tokens,
+		Meta: metaClients,
+		Sched: sched,
+		Campaigns: campaigns,
+		AdSets: adsets,
+		Ads: ads,
+	}
+
+	insights := &service.InsightsService{
+		Store: st,
+		Tokens: tokens,
+		Meta: metaClients,
+		Sched: sched,
+	}
+
+	reports := &service.ReportService{
+		Store: st,
+		Tokens: tokens,
+		Meta: metaClients,
+		Sched: sched,
 	}
 
+	launches := &service.LaunchService{
+		Store: st,
+		Campaigns: campaigns,
+		AdSets: adsets,
+		Ads: ads,
+		Creatives: creativeSync,
+	}
+
+	apiKeys := &service.APIKeyService{Store: st}
+
 	h := &httpapi.Handler{
 		CreativeSync: creativeSync,
 		Store: st,
 		Campaigns: campaigns,
 		AdSets: adsets,
 		Ads: ads,
+		Bulk: bulk,
+		Launches: launches,
+		Copies: copies,
+		Targeting: targetingSvc,
+		Scheduled: scheduled,
+		Insights: insights,
+		Reports: reports,
+		MetaUsage: meta.DefaultUsageTracker,
+		MetaBreakers: meta.DefaultBreakers,
+		Scheduler: sched,
+		APIKeys: apiKeys,
+		IdempotencyTTL: cfg.IdempotencyTTL,
 	}
 	router := httpapi.NewRouter(h)
 
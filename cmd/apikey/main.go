@@ -0,0 +1,67 @@
+// Command apikey cria API keys direto no banco. Serve para gerar a primeira
+// chave admin; as demais podem ser criadas via POST /v1/api-keys.
+//
+//	go run ./cmd/apikey -name ops -scopes admin
+//	go run ./cmd/apikey -name frontend-acme -scopes read,write -clients 3f1c...,9a7e...
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"creative-service/internal/config"
+	"creative-service/internal/service"
+	"creative-service/internal/storage"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	name := flag.String("name", "", "nome da chave (quem vai usá-la)")
+	scopes := flag.String("scopes", "read", "scopes separados por vírgula: read, write, admin")
+	clients := flag.String("clients", "", "client_uuids liberados, separados por vírgula (obrigatório sem admin)")
+	flag.Parse()
+
+	cfg := config.Load()
+	if cfg.DatabaseURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+
+	keys := &service.APIKeyService{Store: storage.New(pool)}
+	out, err := keys.CreateAPIKey(ctx, service.CreateAPIKeyInput{
+		Name:        *name,
+		Scopes:      splitFlag(*scopes),
+		ClientUUIDs: splitFlag(*clients),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// A chave só é exibida aqui; o banco guarda apenas o hash
+	fmt.Fprintf(os.Stderr, "key_id: %s\nscopes: %s\n", out.APIKey.KeyID, strings.Join(out.APIKey.Scopes, ","))
+	fmt.Println(out.Key)
+}
+
+func splitFlag(v string) []string {
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"creative-service/internal/auth"
+	"creative-service/internal/config"
+	"creative-service/internal/meta"
+	"creative-service/internal/secrets"
+	"creative-service/internal/service"
+	"creative-service/internal/storage"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+const (
+	schedulerBatch = 20 // quantas ações agendadas cada ciclo reivindica
+	jobWorkers     = 2  // jobs (reports etc.) executados em paralelo
+
+	syncEnqueueInterval = 5 * time.Minute // verificação de contas com insights_sync vencido
+	idempotencyPurge    = time.Hour       // limpeza das Idempotency-Keys expiradas
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	cfg := config.Load()
+	if cfg.DatabaseURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	// Migrations rodam na API; o worker só consome as tabelas
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Jobs e ações agendadas agem em nome do sistema, não de uma API key
+	ctx = auth.WithSystem(ctx)
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+
+	st := storage.New(pool)
+	sched := service.NewScheduler(cfg.MaxConcurrency, cfg.MaxConcurrencyPerAdAccount, cfg.MaxConcurrencyPerToken)
+
+	meta.DefaultUsageTracker.Threshold = float64(cfg.MetaThrottleThreshold)
+	meta.DefaultUsageTracker.MaxDelay = cfg.MetaMaxThrottleDelay
+	meta.DefaultUsageTracker.MaxWait = cfg.MetaMaxThrottleWait
+	meta.DefaultBreakers.Failures = cfg.MetaBreakerFailures
+	meta.DefaultBreakers.OpenFor = cfg.MetaBreakerOpenFor
+
+	metaClients := meta.NewClientFactory(cfg.BaseURL, cfg.APIVersion, cfg.HTTPTimeout)
+	tokens := secrets.EnvResolver{}
+
+	campaigns := &service.CampaignService{
+		Store:  st,
+		Tokens: tokens,
+		Meta:   metaClients,
+		Sched:  sched,
+	}
+
+	adsets := &service.AdSetService{
+		Store:  st,
+		Tokens: tokens,
+		Meta:   metaClients,
+		Sched:  sched,
+	}
+
+	ads := &service.AdService{
+		Store:  st,
+		Tokens: tokens,
+		Meta:   metaClients,
+		Sched:  sched,
+	}
+
+	scheduled := &service.ScheduledActionService{
+		Store:     st,
+		Tokens:    tokens,
+		Meta:      metaClients,
+		Sched:     sched,
+		Campaigns: campaigns,
+		AdSets:    adsets,
+		Ads:       ads,
+	}
+
+	reports := &service.ReportService{
+		Store:  st,
+		Tokens: tokens,
+		Meta:   metaClients,
+		Sched:  sched,
+	}
+
+	insightsSync := &service.InsightsSyncService{
+		Store:        st,
+		Tokens:       tokens,
+		Meta:         metaClients,
+		Sched:        sched,
+		Interval:     cfg.InsightsSyncInterval,
+		BackfillDays: cfg.InsightsSyncBackfillDays,
+		LookbackDays: cfg.InsightsSyncLookbackDays,
+	}
+
+	jobs := &service.JobRunner{
+		Store: st,
+		Handlers: map[string]service.JobHandler{
+			service.JobTypeInsightsReport: reports.RunReportJob,
+			service.JobTypeInsightsSync:   insightsSync.RunSyncJob,
+		},
+	}
+
+	log.Println("worker started, pid", os.Getpid(), "scheduler interval", cfg.SchedulerInterval)
+
+	var wg sync.WaitGroup
+	for i := 0; i < jobWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runJobs(ctx, jobs, cfg.SchedulerInterval)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		runInsightsSync(ctx, insightsSync, syncEnqueueInterval)
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		runIdempotencyPurge(ctx, st, idempotencyPurge)
+	}()
+	runScheduler(ctx, scheduled, cfg.SchedulerInterval)
+	wg.Wait()
+	log.Println("worker stopped")
+}
+
+// runJobs consome a fila de jobs; com a fila vazia espera o intervalo
+func runJobs(ctx context.Context, jobs *service.JobRunner, interval time.Duration) {
+	for ctx.Err() == nil {
+		ran, err := jobs.RunNext(ctx)
+		if err != nil {
+			log.Printf("jobs: %v", err)
+		}
+		if ran && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// runScheduler executa as ações vencidas a cada intervalo até o contexto ser
+// cancelado. Quando um ciclo enche o lote, roda de novo sem esperar.
+func runScheduler(ctx context.Context, scheduled *service.ScheduledActionService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := scheduled.RunDue(ctx, schedulerBatch)
+		if err != nil {
+			log.Printf("scheduler: %v", err)
+		} else if n > 0 {
+			log.Printf("scheduler: processed %d scheduled actions", n)
+		}
+		if n == schedulerBatch && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runInsightsSync enfileira periodicamente o insights_sync das contas vencidas
+// (o intervalo por conta é INSIGHTS_SYNC_INTERVAL; aqui só se verifica a fila)
+func runInsightsSync(ctx context.Context, sync *service.InsightsSyncService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := sync.EnqueueDue(ctx)
+		if err != nil {
+			log.Printf("insights sync: %v", err)
+		} else if n > 0 {
+			log.Printf("insights sync: enqueued %d ad accounts", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runIdempotencyPurge apaga as Idempotency-Keys expiradas (IDEMPOTENCY_TTL)
+func runIdempotencyPurge(ctx context.Context, st *storage.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := st.PurgeIdempotencyKeys(ctx)
+		if err != nil {
+			log.Printf("idempotency purge: %v", err)
+		} else if n > 0 {
+			log.Printf("idempotency purge: removed %d expired keys", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+)
+
+// ======= API KEYS =======
+// As chaves têm 256 bits aleatórios, então basta guardar o sha256: não há o que
+// quebrar por força bruta e a busca é por igualdade (índice único em key_hash).
+// O texto da chave só aparece uma vez, na criação.
+
+const keyPrefix = "csk_"
+
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"  // GETs, estimativas e validações
+	ScopeWrite Scope = "write" // cria, altera e remove objetos (inclui read)
+	ScopeAdmin Scope = "admin" // todos os clientes e gestão de API keys (inclui write)
+)
+
+var scopeRank = map[Scope]int{ScopeRead: 1, ScopeWrite: 2, ScopeAdmin: 3}
+
+func ValidScope(s Scope) bool {
+	_, ok := scopeRank[s]
+	return ok
+}
+
+var (
+	ErrUnauthenticated = errors.New("missing or invalid api key")
+	ErrForbidden       = errors.New("api key is not allowed to access this client")
+)
+
+// Principal é a API key autenticada do request
+type Principal struct {
+	KeyID       string
+	Name        string
+	Scopes      []Scope
+	ClientUUIDs []string // clientes liberados; ignorado com ScopeAdmin
+}
+
+// Has diz se a chave cobre scope; admin inclui write, que inclui read
+func (p *Principal) Has(scope Scope) bool {
+	for _, s := range p.Scopes {
+		if scopeRank[s] >= scopeRank[scope] {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) CanAccessClient(clientUUID string) bool {
+	return p.Has(ScopeAdmin) || slices.Contains(p.ClientUUIDs, clientUUID)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// systemPrincipal identifica processos internos (worker): acesso a todos os clientes
+var systemPrincipal = &Principal{KeyID: "system", Name: "system", Scopes: []Scope{ScopeAdmin}}
+
+// WithSystem marca ctx como chamada interna do worker (jobs, ações agendadas,
+// sync de insights). Sem ele nem API key, CheckClient recusa.
+func WithSystem(ctx context.Context) context.Context {
+	return WithPrincipal(ctx, systemPrincipal)
+}
+
+// CheckClient devolve ErrForbidden quando a API key do contexto não tem acesso ao
+// cliente e ErrUnauthenticated quando o contexto não tem principal (rota sem
+// Authenticate ou processo interno sem WithSystem).
+func CheckClient(ctx context.Context, clientUUID string) error {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if !p.CanAccessClient(clientUUID) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// GenerateKey cria uma chave nova e devolve o texto (para quem pediu) e o hash (para o banco)
+func GenerateKey() (key, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key = keyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, HashKey(key), nil
+}
+
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// DisplayPrefix é o trecho da chave guardado em claro para identificá-la em listagens
+func DisplayPrefix(key string) string {
+	if len(key) <= len(keyPrefix)+6 {
+		return key
+	}
+	return key[:len(keyPrefix)+6]
+}
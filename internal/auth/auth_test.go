@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPrincipalHas(t *testing.T) {
+	tests := []struct {
+		scopes []Scope
+		scope  Scope
+		want   bool
+	}{
+		{scopes: []Scope{ScopeRead}, scope: ScopeRead, want: true},
+		{scopes: []Scope{ScopeRead}, scope: ScopeWrite, want: false},
+		{scopes: []Scope{ScopeWrite}, scope: ScopeRead, want: true},
+		{scopes: []Scope{ScopeWrite}, scope: ScopeAdmin, want: false},
+		{scopes: []Scope{ScopeAdmin}, scope: ScopeWrite, want: true},
+		{scopes: []Scope{ScopeRead, ScopeAdmin}, scope: ScopeAdmin, want: true},
+		{scopes: []Scope{"owner"}, scope: ScopeRead, want: false},
+		{scopes: nil, scope: ScopeRead, want: false},
+	}
+	for _, tt := range tests {
+		p := &Principal{Scopes: tt.scopes}
+		if got := p.Has(tt.scope); got != tt.want {
+			t.Errorf("%v.Has(%s) = %v, want %v", tt.scopes, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestCheckClient(t *testing.T) {
+	restricted := &Principal{KeyID: "k1", Scopes: []Scope{ScopeWrite}, ClientUUIDs: []string{"client-a"}}
+	admin := &Principal{KeyID: "k2", Scopes: []Scope{ScopeAdmin}}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		client  string
+		wantErr error
+	}{
+		{name: "no principal", ctx: context.Background(), client: "client-a", wantErr: ErrUnauthenticated},
+		{name: "system", ctx: WithSystem(context.Background()), client: "client-a"},
+		{name: "admin key", ctx: WithPrincipal(context.Background(), admin), client: "client-b"},
+		{name: "allowed client", ctx: WithPrincipal(context.Background(), restricted), client: "client-a"},
+		{name: "other client", ctx: WithPrincipal(context.Background(), restricted), client: "client-b", wantErr: ErrForbidden},
+		{name: "job without client", ctx: WithPrincipal(context.Background(), restricted), client: "", wantErr: ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckClient(tt.ctx, tt.client)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	key, hash, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, keyPrefix) || HashKey(key) != hash {
+		t.Fatalf("key %q does not match hash %q", key, hash)
+	}
+	if other, _, _ := GenerateKey(); other == key {
+		t.Error("two calls returned the same key")
+	}
+	if prefix := DisplayPrefix(key); prefix != key[:len(keyPrefix)+6] {
+		t.Errorf("display prefix = %q", prefix)
+	}
+}
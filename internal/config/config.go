@@ -18,7 +18,26 @@ type Config struct {
 	S3AccessKeyID  string
 	S3SecretAccessKey  string
 
-	MaxConcurrency int
+	MaxConcurrency             int
+	MaxConcurrencyPerAdAccount int // vagas simultâneas de uma mesma ad account
+	MaxConcurrencyPerToken     int // vagas simultâneas de um mesmo token (token_ref)
+
+	TargetingCacheTTL time.Duration
+
+	SchedulerInterval time.Duration
+
+	InsightsSyncInterval     time.Duration
+	InsightsSyncBackfillDays int
+	InsightsSyncLookbackDays int
+
+	MetaThrottleThreshold int           // % de uso da Meta a partir do qual as chamadas são espaçadas
+	MetaMaxThrottleDelay  time.Duration // espera máxima por chamada perto do limite
+	MetaMaxThrottleWait   time.Duration
+
+	MetaBreakerFailures int // falhas seguidas que abrem o circuito de uma conta/família
+	MetaBreakerOpenFor  time.Duration
+
+	IdempotencyTTL time.Duration // retenção das respostas gravadas por Idempotency-Key
 }
 
 func Load() Config {
@@ -35,7 +54,26 @@ func Load() Config {
 		S3AccessKeyID:  os.Getenv("AWS_ACCESS_KEY_ID"),
 		S3SecretAccessKey:  os.Getenv("AWS_SECRET_ACCESS_KEY"),
 
-		MaxConcurrency: atoiDefault(getenv("MAX_CONCURRENCY", "3"), 3),
+		MaxConcurrency:             atoiDefault(getenv("MAX_CONCURRENCY", "3"), 3),
+		MaxConcurrencyPerAdAccount: atoiDefault(getenv("MAX_CONCURRENCY_PER_AD_ACCOUNT", "2"), 2),
+		MaxConcurrencyPerToken:     atoiDefault(getenv("MAX_CONCURRENCY_PER_TOKEN", "4"), 4),
+
+		TargetingCacheTTL: durationDefault(getenv("TARGETING_CACHE_TTL", "1h"), time.Hour),
+
+		SchedulerInterval: durationDefault(getenv("SCHEDULER_INTERVAL", "15s"), 15*time.Second),
+
+		InsightsSyncInterval:     durationDefault(getenv("INSIGHTS_SYNC_INTERVAL", "6h"), 6*time.Hour),
+		InsightsSyncBackfillDays: atoiDefault(getenv("INSIGHTS_SYNC_BACKFILL_DAYS", "90"), 90),
+		InsightsSyncLookbackDays: atoiDefault(getenv("INSIGHTS_SYNC_LOOKBACK_DAYS", "7"), 7),
+
+		MetaThrottleThreshold: atoiDefault(getenv("META_THROTTLE_THRESHOLD", "75"), 75),
+		MetaMaxThrottleDelay:  durationDefault(getenv("META_MAX_THROTTLE_DELAY", "5s"), 5*time.Second),
+		MetaMaxThrottleWait:   durationDefault(getenv("META_MAX_THROTTLE_WAIT", "30s"), 30*time.Second),
+
+		MetaBreakerFailures: atoiDefault(getenv("META_BREAKER_FAILURES", "5"), 5),
+		MetaBreakerOpenFor:  durationDefault(getenv("META_BREAKER_OPEN_FOR", "30s"), 30*time.Second),
+
+		IdempotencyTTL: durationDefault(getenv("IDEMPOTENCY_TTL", "24h"), 24*time.Hour),
 	}
 }
 
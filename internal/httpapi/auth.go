@@ -0,0 +1,146 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"creative-service/internal/auth"
+	"creative-service/internal/service"
+	"creative-service/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ======= AUTENTICAÇÃO =======
+// Todas as rotas, menos /v1/health, exigem uma API key em
+// "Authorization: Bearer csk_..." (ou X-API-Key). O Principal vai para o contexto
+// e os services conferem o cliente de cada ad_account_id (auth.CheckClient).
+
+// keyAuthenticator resolve uma API key no Principal dela (APIKeyService)
+type keyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*auth.Principal, error)
+}
+
+// Authenticate valida a API key e guarda o Principal no contexto do request
+func (h *Handler) Authenticate(next http.Handler) http.Handler {
+	return authenticate(h.APIKeys, next)
+}
+
+func authenticate(keys keyAuthenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := apiKeyFromRequest(r)
+		if key == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="creative-service"`)
+			writeErr(w, 401, "missing_api_key")
+			return
+		}
+
+		p, err := keys.Authenticate(r.Context(), key)
+		if errors.Is(err, auth.ErrUnauthenticated) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="creative-service", error="invalid_token"`)
+			writeErr(w, 401, "invalid_api_key")
+			return
+		}
+		if err != nil {
+			log.Printf("auth: %v", err)
+			writeErr(w, 500, "internal_error")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), p)))
+	})
+}
+
+func apiKeyFromRequest(r *http.Request) string {
+	if v := r.Header.Get("Authorization"); v != "" {
+		scheme, token, ok := strings.Cut(v, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// RequireScope barra com 403 as API keys sem o scope (admin inclui write, que inclui read)
+func RequireScope(scope auth.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := auth.FromContext(r.Context())
+			if !ok || !p.Has(scope) {
+				writeJSON(w, 403, map[string]any{"error": "insufficient_scope", "required_scope": scope})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// checkAdAccount confere o acesso da API key a uma ad account em handlers que
+// vão direto ao banco, sem passar por um service
+func (h *Handler) checkAdAccount(r *http.Request, adAccountID string) error {
+	adAccount, err := h.Store.GetAdAccount(r.Context(), adAccountID)
+	if err != nil {
+		return err
+	}
+	return auth.CheckClient(r.Context(), adAccount.ClientUUID)
+}
+
+// ======= API KEYS (admin) =======
+
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name        string   `json:"name"`
+		Scopes      []string `json:"scopes"`
+		ClientUUIDs []string `json:"client_uuids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, "invalid_json")
+		return
+	}
+
+	out, err := h.APIKeys.CreateAPIKey(r.Context(), service.CreateAPIKeyInput{
+		Name:        req.Name,
+		Scopes:      req.Scopes,
+		ClientUUIDs: req.ClientUUIDs,
+	})
+	if err != nil {
+		writeServiceErr(w, 400, err)
+		return
+	}
+
+	writeJSON(w, 201, out)
+}
+
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.APIKeys.ListAPIKeys(r.Context())
+	if err != nil {
+		writeErr(w, 500, "failed to list api keys")
+		return
+	}
+
+	writeJSON(w, 200, map[string]any{"api_keys": keys, "count": len(keys)})
+}
+
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID := chi.URLParam(r, "key_id")
+	if keyID == "" {
+		writeErr(w, 400, "missing_key_id")
+		return
+	}
+
+	if err := h.APIKeys.RevokeAPIKey(r.Context(), keyID); err != nil {
+		if errors.Is(err, storage.ErrAPIKeyNotFound) {
+			writeErr(w, 404, "api_key_not_found")
+			return
+		}
+		writeServiceErr(w, 500, err)
+		return
+	}
+
+	writeJSON(w, 200, map[string]any{"success": true, "key_id": keyID})
+}
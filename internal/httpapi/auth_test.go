@@ -0,0 +1,195 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"creative-service/internal/auth"
+)
+
+// okHandler responde 200 quando o middleware deixa o request passar
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, 200, map[string]any{"ok": true})
+})
+
+// errorCode devolve o campo "error" do body
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+	}
+	code, _ := body["error"].(string)
+	return code
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *auth.Principal // nil: request sem Authenticate
+		scope      auth.Scope
+		wantStatus int
+	}{
+		{name: "no principal", scope: auth.ScopeRead, wantStatus: 403},
+		{name: "read key on read route", principal: &auth.Principal{Scopes: []auth.Scope{auth.ScopeRead}}, scope: auth.ScopeRead, wantStatus: 200},
+		{name: "read key on write route", principal: &auth.Principal{Scopes: []auth.Scope{auth.ScopeRead}}, scope: auth.ScopeWrite, wantStatus: 403},
+		{name: "write key on read route", principal: &auth.Principal{Scopes: []auth.Scope{auth.ScopeWrite}}, scope: auth.ScopeRead, wantStatus: 200},
+		{name: "write key on admin route", principal: &auth.Principal{Scopes: []auth.Scope{auth.ScopeWrite}}, scope: auth.ScopeAdmin, wantStatus: 403},
+		{name: "admin key on admin route", principal: &auth.Principal{Scopes: []auth.Scope{auth.ScopeAdmin}}, scope: auth.ScopeAdmin, wantStatus: 200},
+		{name: "key without scopes", principal: &auth.Principal{}, scope: auth.ScopeRead, wantStatus: 403},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/v1/campaigns", nil)
+			if tt.principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), tt.principal))
+			}
+			rec := httptest.NewRecorder()
+			RequireScope(tt.scope)(okHandler).ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == 403 && errorCode(t, rec) != "insufficient_scope" {
+				t.Errorf("error = %q, want insufficient_scope", errorCode(t, rec))
+			}
+		})
+	}
+}
+
+func TestAuthenticateWithoutKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		value  string
+	}{
+		{name: "no header"},
+		{name: "basic auth", header: "Authorization", value: "Basic dXNlcjpwYXNz"},
+		{name: "bearer without token", header: "Authorization", value: "Bearer"},
+		{name: "blank x-api-key", header: "X-API-Key", value: "  "},
+	}
+
+	// Sem chave o middleware responde antes de consultar o banco
+	h := &Handler{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/v1/campaigns", nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			h.Authenticate(okHandler).ServeHTTP(rec, r)
+
+			if rec.Code != 401 || errorCode(t, rec) != "missing_api_key" {
+				t.Fatalf("got %d %s, want 401 missing_api_key", rec.Code, rec.Body.String())
+			}
+			if rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("missing WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+// fakeKeys resolve as API keys de um mapa; errKey simula falha do banco
+type fakeKeys map[string]*auth.Principal
+
+const errKey = "csk_db_down"
+
+func (k fakeKeys) Authenticate(_ context.Context, key string) (*auth.Principal, error) {
+	if key == errKey {
+		return nil, errors.New("connection refused")
+	}
+	p, ok := k[key]
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+	return p, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	principal := &auth.Principal{KeyID: "k1", Scopes: []auth.Scope{auth.ScopeWrite}, ClientUUIDs: []string{"client-a"}}
+	keys := fakeKeys{"csk_valid": principal}
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "bearer", header: "Authorization", value: "Bearer csk_valid", wantStatus: 200},
+		{name: "lowercase scheme", header: "Authorization", value: "bearer csk_valid", wantStatus: 200},
+		{name: "x-api-key", header: "X-API-Key", value: "csk_valid", wantStatus: 200},
+		{name: "unknown key", header: "Authorization", value: "Bearer csk_unknown", wantStatus: 401, wantError: "invalid_api_key"},
+		{name: "store error", header: "X-API-Key", value: errKey, wantStatus: 500, wantError: "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *auth.Principal
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = auth.FromContext(r.Context())
+				okHandler.ServeHTTP(w, r)
+			})
+			r := httptest.NewRequest("GET", "/v1/campaigns", nil)
+			r.Header.Set(tt.header, tt.value)
+			rec := httptest.NewRecorder()
+			authenticate(keys, next).ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantError != "" {
+				if code := errorCode(t, rec); code != tt.wantError {
+					t.Errorf("error = %q, want %q", code, tt.wantError)
+				}
+				if got != nil {
+					t.Error("next handler ran for a rejected key")
+				}
+				return
+			}
+			if got != principal || !got.CanAccessClient("client-a") {
+				t.Errorf("principal = %+v", got)
+			}
+		})
+	}
+}
+
+func TestRejectDryRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		header     string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "no dry run", target: "/v1/scheduled-actions", wantStatus: 200},
+		{name: "dry_run=false", target: "/v1/scheduled-actions?dry_run=false", wantStatus: 200},
+		{name: "dry_run=true", target: "/v1/scheduled-actions?dry_run=true", wantStatus: 400, wantError: "dry_run_not_supported"},
+		{name: "header", target: "/v1/scheduled-actions", header: "1", wantStatus: 400, wantError: "dry_run_not_supported"},
+		{name: "invalid value", target: "/v1/scheduled-actions?dry_run=maybe", wantStatus: 400, wantError: "invalid_dry_run"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tt.target, nil)
+			if tt.header != "" {
+				r.Header.Set("X-Dry-Run", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			RejectDryRun(okHandler).ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantError != "" && errorCode(t, rec) != tt.wantError {
+				t.Errorf("error = %q, want %q", errorCode(t, rec), tt.wantError)
+			}
+		})
+	}
+}
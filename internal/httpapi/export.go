@@ -0,0 +1,478 @@
+package httpapi
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ======= EXPORT (CSV / XLSX) =======
+// Listas e insights aceitam Accept: text/csv (ou o MIME do XLSX) ou ?format=csv|xlsx.
+// ?fields= escolhe e ordena as colunas. Os services devolvem a lista inteira, que
+// fica em memória como no JSON; o arquivo é que é escrito linha a linha, sem um
+// segundo buffer com o CSV/XLSX completo.
+
+const (
+	formatCSV  = "csv"
+	formatXLSX = "xlsx"
+
+	mimeCSV  = "text/csv"
+	mimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	exportFlushEvery = 500 // linhas entre flushes da resposta
+)
+
+// exportFormat devolve "" (JSON), csv ou xlsx; ok=false para ?format= inválido
+func exportFormat(r *http.Request) (string, bool) {
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case "":
+	case "json":
+		return "", true
+	case formatCSV:
+		return formatCSV, true
+	case formatXLSX:
+		return formatXLSX, true
+	default:
+		return "", false
+	}
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		switch mediaType {
+		case mimeCSV:
+			return formatCSV, true
+		case mimeXLSX:
+			return formatXLSX, true
+		}
+	}
+	return "", true
+}
+
+// rowSource entrega as linhas uma a uma para emit
+type rowSource func(emit func(row map[string]any) error) error
+
+// writeExport escreve as linhas no formato pedido. columns vazio usa as chaves
+// da primeira linha (ordem alfabética). Depois do primeiro byte não há como
+// mudar o status: erros no meio do stream só são logados e o arquivo fica truncado.
+func writeExport(w http.ResponseWriter, format, name string, columns []string, rows rowSource) {
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102"), format)
+	contentType := mimeCSV + "; charset=utf-8"
+	if format == formatXLSX {
+		contentType = mimeXLSX
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(200)
+
+	flusher, _ := w.(http.Flusher)
+	bw := bufio.NewWriter(w)
+
+	var tw tableWriter
+	if format == formatXLSX {
+		tw = newXLSXWriter(bw)
+	} else {
+		tw = newCSVWriter(bw)
+	}
+
+	n := 0
+	headerDone := false
+	writeHeader := func() error {
+		headerDone = true
+		cells := make([]any, len(columns))
+		for i, c := range columns {
+			cells[i] = c
+		}
+		return tw.WriteRow(columns, cells)
+	}
+
+	err := rows(func(row map[string]any) error {
+		if !headerDone {
+			if len(columns) == 0 {
+				columns = sortedKeys(row)
+			}
+			if err := writeHeader(); err != nil {
+				return err
+			}
+		}
+
+		cells := make([]any, len(columns))
+		for i, c := range columns {
+			cells[i] = row[c]
+		}
+		if err := tw.WriteRow(columns, cells); err != nil {
+			return err
+		}
+
+		if n++; n%exportFlushEvery == 0 {
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			if err := bw.Flush(); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		return nil
+	})
+	if err == nil && !headerDone {
+		err = writeHeader()
+	}
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		log.Printf("export %s: %v", filename, err)
+	}
+}
+
+// writeExportList exporta uma lista de structs já carregada (colunas pelas tags
+// json); cada item vira linha só na hora de ser escrito
+func writeExportList(w http.ResponseWriter, r *http.Request, format, name string, items any) {
+	known := jsonColumns(items)
+	columns := splitList(r.URL.Query().Get("fields"))
+	if len(columns) == 0 {
+		columns = known
+	} else if unknown := unknownColumns(columns, known); len(unknown) > 0 {
+		writeJSON(w, 400, map[string]any{"error": "invalid_fields", "fields": unknown})
+		return
+	}
+
+	writeExport(w, format, name, columns, func(emit func(map[string]any) error) error {
+		v := reflect.ValueOf(items)
+		for i := 0; i < v.Len(); i++ {
+			row, err := toRow(v.Index(i).Interface())
+			if err != nil {
+				return err
+			}
+			if err := emit(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeExportRows exporta linhas já em map (insights)
+func writeExportRows(w http.ResponseWriter, format, name string, columns []string, rows []map[string]any) {
+	if len(columns) == 0 {
+		columns = unionKeys(rows)
+	}
+	writeExport(w, format, name, columns, func(emit func(map[string]any) error) error {
+		for _, row := range rows {
+			if err := emit(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func toRow(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRow(b)
+}
+
+func decodeRow(b []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var row map[string]any
+	if err := dec.Decode(&row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// jsonColumns lista os nomes json dos campos do tipo dos itens de um slice
+func jsonColumns(items any) []string {
+	t := reflect.TypeOf(items)
+	if t == nil || t.Kind() != reflect.Slice {
+		return nil
+	}
+	t = t.Elem()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var columns []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+// unknownColumns devolve as colunas pedidas que o tipo não tem (known vazio aceita tudo)
+func unknownColumns(columns, known []string) []string {
+	if len(known) == 0 {
+		return nil
+	}
+	valid := make(map[string]struct{}, len(known))
+	for _, c := range known {
+		valid[c] = struct{}{}
+	}
+	var unknown []string
+	for _, c := range columns {
+		if _, ok := valid[c]; !ok {
+			unknown = append(unknown, c)
+		}
+	}
+	return unknown
+}
+
+func sortedKeys(row map[string]any) []string {
+	keys := make([]string, 0, len(row))
+	for k := range row {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func unionKeys(rows []map[string]any) []string {
+	seen := map[string]struct{}{}
+	for _, row := range rows {
+		for k := range row {
+			seen[k] = struct{}{}
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// cellString formata um valor para CSV; objetos e listas viram JSON compacto.
+// Texto que começa com =, +, - ou @ seria lido como fórmula pelo Excel/Sheets
+// (nomes de campanha e ads vêm do usuário) e ganha um ' na frente; números
+// ("-12.5", que a Meta devolve como string) ficam como estão.
+func cellString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeFormula(x)
+	case json.Number:
+		return x.String()
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func escapeFormula(s string) string {
+	if s == "" || !strings.ContainsRune("=+-@", rune(s[0])) {
+		return s
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil && !strings.ContainsAny(s, "xXnN") { // hex, NaN, Inf
+		return s
+	}
+	return "'" + s
+}
+
+type tableWriter interface {
+	WriteRow(columns []string, cells []any) error
+	Flush() error
+	Close() error
+}
+
+// ======= CSV =======
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteRow(_ []string, cells []any) error {
+	record := make([]string, len(cells))
+	for i, v := range cells {
+		record[i] = cellString(v)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ======= XLSX =======
+// Planilha mínima (uma aba, sem estilos) escrita direto no zip: o XML da aba é
+// gerado linha a linha, com strings inline para não precisar de sharedStrings.
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+	xlsxSheetEnd = `</sheetData></worksheet>`
+
+	// Excel perde precisão acima de 15 dígitos (IDs da Meta têm mais)
+	xlsxMaxNumberDigits = 15
+)
+
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	err   error
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	x := &xlsxWriter{zw: zip.NewWriter(w)}
+	for _, f := range []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		fw, err := x.zw.Create(f.name)
+		if err != nil {
+			x.err = err
+			return x
+		}
+		if _, err := io.WriteString(fw, f.body); err != nil {
+			x.err = err
+			return x
+		}
+	}
+
+	// A aba é o último arquivo do zip: fica aberta até Close
+	x.sheet, x.err = x.zw.Create("xl/worksheets/sheet1.xml")
+	if x.err == nil {
+		_, x.err = io.WriteString(x.sheet, xlsxSheetStart)
+	}
+	return x
+}
+
+func (x *xlsxWriter) WriteRow(columns []string, cells []any) error {
+	if x.err != nil {
+		return x.err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("<row>")
+	for i, v := range cells {
+		if num, ok := xlsxNumber(columns[i], v); ok {
+			b.WriteString("<c><v>")
+			b.WriteString(num)
+			b.WriteString("</v></c>")
+			continue
+		}
+		b.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		_ = xml.EscapeText(&b, []byte(cellString(v)))
+		b.WriteString("</t></is></c>")
+	}
+	b.WriteString("</row>")
+
+	_, x.err = x.sheet.Write(b.Bytes())
+	return x.err
+}
+
+func (x *xlsxWriter) Flush() error {
+	if x.err != nil {
+		return x.err
+	}
+	return x.zw.Flush()
+}
+
+func (x *xlsxWriter) Close() error {
+	if x.err != nil {
+		return x.err
+	}
+	if _, err := io.WriteString(x.sheet, xlsxSheetEnd); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+// xlsxNumber decide se a célula vai como número. A Meta devolve métricas como
+// string ("12.34"); IDs ficam como texto para não perder dígitos.
+func xlsxNumber(column string, v any) (string, bool) {
+	var s string
+	switch x := v.(type) {
+	case json.Number:
+		s = x.String()
+	case float64:
+		s = strconv.FormatFloat(x, 'f', -1, 64)
+	case string:
+		if column == "id" || strings.HasSuffix(column, "_id") {
+			return "", false
+		}
+		if len(x) > 1 && x[0] == '0' && x[1] != '.' { // CEP, códigos com zero à esquerda
+			return "", false
+		}
+		s = x
+	default:
+		return "", false
+	}
+
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return "", false
+	}
+	if digits := strings.TrimLeft(strings.NewReplacer("-", "", ".", "").Replace(s), "0"); len(digits) > xlsxMaxNumberDigits {
+		return "", false
+	}
+	if strings.ContainsAny(s, "xXnN") { // hex, NaN, Inf
+		return "", false
+	}
+	return s, true
+}
@@ -0,0 +1,110 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCellString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   any
+		want string
+	}{
+		{name: "nil", in: nil, want: ""},
+		{name: "plain text", in: "Black Friday", want: "Black Friday"},
+		{name: "formula", in: "=HYPERLINK(\"http://x\")", want: "'=HYPERLINK(\"http://x\")"},
+		{name: "plus", in: "+55 11", want: "'+55 11"},
+		{name: "minus text", in: "-promo", want: "'-promo"},
+		{name: "at", in: "@SUM(A1)", want: "'@SUM(A1)"},
+		{name: "negative number as string", in: "-12.5", want: "-12.5"},
+		{name: "positive sign number", in: "+3", want: "+3"},
+		{name: "signed infinity is text", in: "-Inf", want: "'-Inf"},
+		{name: "signed hex is text", in: "-0x1p3", want: "'-0x1p3"},
+		{name: "json number", in: json.Number("-7"), want: "-7"},
+		{name: "float", in: -1.5, want: "-1.5"},
+		{name: "bool", in: true, want: "true"},
+		{name: "object", in: map[string]any{"a": "=1"}, want: `{"a":"=1"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cellString(tt.in); got != tt.want {
+				t.Errorf("cellString(%v) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXLSXNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		column string
+		in     any
+		want   string
+		wantOK bool
+	}{
+		{name: "metric string", column: "spend", in: "12.34", want: "12.34", wantOK: true},
+		{name: "negative", column: "spend", in: "-1", want: "-1", wantOK: true},
+		{name: "json number", column: "count", in: json.Number("42"), want: "42", wantOK: true},
+		{name: "float", column: "ctr", in: 0.5, want: "0.5", wantOK: true},
+		{name: "zero", column: "spend", in: "0", want: "0", wantOK: true},
+		{name: "decimal below one", column: "spend", in: "0.25", want: "0.25", wantOK: true},
+		{name: "id column stays text", column: "id", in: "120200000000001", wantOK: false},
+		{name: "suffix id column stays text", column: "campaign_id", in: "1", wantOK: false},
+		{name: "leading zero stays text", column: "zip", in: "01310", wantOK: false},
+		{name: "too many digits", column: "value", in: "1234567890123456", wantOK: false},
+		{name: "fifteen digits", column: "value", in: "123456789012345", want: "123456789012345", wantOK: true},
+		{name: "hex", column: "value", in: "0x10", wantOK: false},
+		{name: "nan", column: "value", in: "NaN", wantOK: false},
+		{name: "inf", column: "value", in: "Inf", wantOK: false},
+		{name: "text", column: "name", in: "abc", wantOK: false},
+		{name: "bool", column: "active", in: true, wantOK: false},
+		{name: "nil", column: "spend", in: nil, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := xlsxNumber(tt.column, tt.in)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("xlsxNumber(%q, %v) = %q, %v; want %q, %v", tt.column, tt.in, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWriteExportListFields(t *testing.T) {
+	type item struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	items := []item{{ID: "1", Name: "=cmd"}}
+
+	tests := []struct {
+		name       string
+		fields     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "all columns", wantStatus: 200, wantBody: "id,name\n1,'=cmd\n"},
+		{name: "selected columns", fields: "name", wantStatus: 200, wantBody: "name\n'=cmd\n"},
+		{name: "unknown column", fields: "name,budget", wantStatus: 400, wantBody: `"fields":["budget"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/?fields="+tt.fields, nil)
+			w := httptest.NewRecorder()
+			writeExportList(w, r, formatCSV, "items", items)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
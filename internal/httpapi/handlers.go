@@ -2,11 +2,18 @@ package httpapi
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"creative-service/internal/auth"
+	"creative-service/internal/meta"
 	"creative-service/internal/service"
 	"creative-service/internal/storage"
+	"creative-service/internal/targeting"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -17,19 +24,42 @@ type Handler struct {
 	Campaigns    *service.CampaignService
 	AdSets       *service.AdSetService
 	Ads          *service.AdService
+	Bulk         *service.BulkService
+	Launches     *service.LaunchService
+	Copies       *service.CopyService
+	Targeting    *service.TargetingService
+	Scheduled    *service.ScheduledActionService
+	Insights     *service.InsightsService
+	Reports      *service.ReportService
+	MetaUsage    *meta.UsageTracker
+	MetaBreakers *meta.BreakerSet
+	Scheduler    *service.Scheduler
+	APIKeys      *service.APIKeyService
+
+	IdempotencyTTL time.Duration // por quanto tempo uma Idempotency-Key é lembrada
 }
 
+// Health é público (load balancer, orquestrador) e só diz se o processo está
+// de pé; circuitos e fila do scheduler ficam em GetMetaStatus, para admins
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, 200, map[string]any{"ok": true})
 }
 
 func (h *Handler) ListClients(w http.ResponseWriter, r *http.Request) {
-	clients, err := h.Store.ListClients(r.Context())
+	all, err := h.Store.ListClients(r.Context())
 	if err != nil {
 		writeErr(w, 500, "failed to list clients")
 		return
 	}
 
+	// Só os clientes liberados para a API key
+	clients := make([]storage.Client, 0, len(all))
+	for _, c := range all {
+		if auth.CheckClient(r.Context(), c.ClientUUID) == nil {
+			clients = append(clients, c)
+		}
+	}
+
 	writeJSON(w, 200, map[string]any{
 		"clients": clients,
 		"count":   len(clients),
@@ -62,7 +92,7 @@ func (h *Handler) CreateImageCreative(w http.ResponseWriter, r *http.Request) {
 		ImageName:    hdr.Filename,
 		ImageBytes:   b,
 	})
-	if err != nil { writeErr(w, 400, err.Error()); return }
+	if err != nil { writeServiceErr(w, 400, err); return }
 	writeJSON(w, 200, out)
 }
 
@@ -106,7 +136,7 @@ func (h *Handler) CreateVideoCreative(w http.ResponseWriter, r *http.Request) {
 		ThumbBytes:   thumbBytes,
 	})
 	if err != nil {
-		writeErr(w, 400, err.Error())
+		writeServiceErr(w, 400, err)
 		return
 	}
 
@@ -122,6 +152,10 @@ func (h *Handler) CreateCampaign(w http.ResponseWriter, r *http.Request) {
 		SpecialAdCategories []string `json:"special_ad_categories"`
 		BuyingType          string   `json:"buying_type"`
 		IsAdSetBudgetSharingEnabled bool `json:"is_adset_budget_sharing_enabled"`
+		DailyBudget         int      `json:"daily_budget"`
+		LifetimeBudget      int      `json:"lifetime_budget"`
+		SpendCap            int      `json:"spend_cap"`
+		BidStrategy         string   `json:"bid_strategy"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeErr(w, 400, "invalid_json"); return
@@ -134,6 +168,9 @@ func (h *Handler) CreateCampaign(w http.ResponseWriter, r *http.Request) {
 	if req.SpecialAdCategories == nil { req.SpecialAdCategories = []string{} }
 	if req.BuyingType == "" { req.BuyingType = "AUCTION" }
 
+	dryRun, ok := dryRunRequested(r)
+	if !ok { writeErr(w, 400, "invalid_dry_run"); return }
+
 	out, err := h.Campaigns.CreateCampaign(r.Context(), service.CreateCampaignInput{
 		AdAccountID:         req.AdAccountID,
 		Name:                req.Name,
@@ -142,23 +179,58 @@ func (h *Handler) CreateCampaign(w http.ResponseWriter, r *http.Request) {
 		SpecialAdCategories: req.SpecialAdCategories,
 		BuyingType:			 req.BuyingType,
 		IsAdSetBudgetSharingEnabled: req.IsAdSetBudgetSharingEnabled,
+		DailyBudget:         req.DailyBudget,
+		LifetimeBudget:      req.LifetimeBudget,
+		SpendCap:            req.SpendCap,
+		BidStrategy:         req.BidStrategy,
+		DryRun:              dryRun,
 	})
-	if err != nil { writeErr(w, 400, err.Error()); return }
+	if err != nil { writeServiceErr(w, 400, err); return }
+	if out.DryRun != nil { writeDryRun(w, out.DryRun); return }
 	writeJSON(w, 200, out)
 }
 
-func (h *Handler) CreateAdSet(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		AdAccountID      string         `json:"ad_account_id"`
-		CampaignID       string         `json:"campaign_id"`
-		Name             string         `json:"name"`
-		BillingEvent     string         `json:"billing_event"`
-		OptimizationGoal string         `json:"optimization_goal"`
-		BidAmount        int            `json:"bid_amount"`
-		DailyBudget      int            `json:"daily_budget"`
-		Targeting        map[string]any `json:"targeting"`
-		Status           string         `json:"status"`
+// createAdSetRequest é o body de CreateAdSet, reaproveitado por EstimateAdSet
+type createAdSetRequest struct {
+	AdAccountID      string          `json:"ad_account_id"`
+	CampaignID       string          `json:"campaign_id"`
+	Name             string          `json:"name"`
+	BillingEvent     string          `json:"billing_event"`
+	OptimizationGoal string          `json:"optimization_goal"`
+	BidStrategy      string          `json:"bid_strategy"`
+	BidAmount        int             `json:"bid_amount"`
+	BidConstraints   map[string]any  `json:"bid_constraints"`
+	DailyBudget      int             `json:"daily_budget"`
+	LifetimeBudget   int                     `json:"lifetime_budget"`
+	StartTime        string                  `json:"start_time"`
+	EndTime          string                  `json:"end_time"`
+	AdSetSchedule    []service.ScheduleBlock `json:"adset_schedule"`
+	Targeting        *targeting.Spec         `json:"targeting"`
+	Status           string                  `json:"status"`
+}
+
+func (req createAdSetRequest) input() service.CreateAdSetInput {
+	return service.CreateAdSetInput{
+		AdAccountID:      req.AdAccountID,
+		CampaignID:       req.CampaignID,
+		Name:             req.Name,
+		BillingEvent:     req.BillingEvent,
+		OptimizationGoal: req.OptimizationGoal,
+		BidStrategy:      req.BidStrategy,
+		BidAmount:        req.BidAmount,
+		BidConstraints:   req.BidConstraints,
+		DailyBudget:      req.DailyBudget,
+		LifetimeBudget:   req.LifetimeBudget,
+		StartTime:        req.StartTime,
+		EndTime:          req.EndTime,
+		AdSetSchedule:    req.AdSetSchedule,
+		Targeting:        req.Targeting,
+		Status:           req.Status,
 	}
+}
+
+func (h *Handler) CreateAdSet(w http.ResponseWriter, r *http.Request) {
+	var req createAdSetRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeErr(w, 400, "invalid_json"); return
 	}
@@ -168,21 +240,33 @@ func (h *Handler) CreateAdSet(w http.ResponseWriter, r *http.Request) {
 	if req.Name == "" { writeErr(w, 400, "missing_name"); return }
 	if req.BillingEvent == "" { writeErr(w, 400, "missing_billing_event"); return }
 	if req.OptimizationGoal == "" { writeErr(w, 400, "missing_optimization_goal"); return }
-	if req.DailyBudget == 0 { writeErr(w, 400, "missing_daily_budget"); return }
 	if req.Status == "" { req.Status = "PAUSED" }
 
-	out, err := h.AdSets.CreateAdSet(r.Context(), service.CreateAdSetInput{
-		AdAccountID:      req.AdAccountID,
-		CampaignID:       req.CampaignID,
-		Name:             req.Name,
-		BillingEvent:     req.BillingEvent,
-		OptimizationGoal: req.OptimizationGoal,
-		BidAmount:        req.BidAmount,
-		DailyBudget:      req.DailyBudget,
-		Targeting:        req.Targeting,
-		Status:           req.Status,
-	})
-	if err != nil { writeErr(w, 400, err.Error()); return }
+	dryRun, ok := dryRunRequested(r)
+	if !ok { writeErr(w, 400, "invalid_dry_run"); return }
+
+	in := req.input()
+	in.DryRun = dryRun
+
+	out, err := h.AdSets.CreateAdSet(r.Context(), in)
+	if err != nil { writeServiceErr(w, 400, err); return }
+	if out.DryRun != nil { writeDryRun(w, out.DryRun); return }
+	writeJSON(w, 200, out)
+}
+
+// EstimateAdSet recebe o mesmo body de CreateAdSet e devolve tamanho de público
+// e resultados diários estimados, sem criar nada na Meta
+func (h *Handler) EstimateAdSet(w http.ResponseWriter, r *http.Request) {
+	var req createAdSetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, "invalid_json"); return
+	}
+
+	if req.AdAccountID == "" { writeErr(w, 400, "missing_ad_account_id"); return }
+	if req.OptimizationGoal == "" { writeErr(w, 400, "missing_optimization_goal"); return }
+
+	out, err := h.AdSets.EstimateAdSet(r.Context(), req.input())
+	if err != nil { writeServiceErr(w, 400, err); return }
 	writeJSON(w, 200, out)
 }
 
@@ -204,14 +288,19 @@ func (h *Handler) CreateAd(w http.ResponseWriter, r *http.Request) {
 	if req.Name == "" { writeErr(w, 400, "missing_name"); return }
 	if req.Status == "" { req.Status = "PAUSED" }
 
+	dryRun, ok := dryRunRequested(r)
+	if !ok { writeErr(w, 400, "invalid_dry_run"); return }
+
 	out, err := h.Ads.CreateAd(r.Context(), service.CreateAdInput{
 		AdAccountID: req.AdAccountID,
 		AdSetID:     req.AdSetID,
 		CreativeID:  req.CreativeID,
 		Name:        req.Name,
 		Status:      req.Status,
+		DryRun:      dryRun,
 	})
-	if err != nil { writeErr(w, 400, err.Error()); return }
+	if err != nil { writeServiceErr(w, 400, err); return }
+	if out.DryRun != nil { writeDryRun(w, out.DryRun); return }
 	writeJSON(w, 200, out)
 }
 
@@ -219,16 +308,42 @@ func (h *Handler) ListCreatives(w http.ResponseWriter, r *http.Request) {
 	adAccountID := r.URL.Query().Get("ad_account_id")
 	typeFilter := r.URL.Query().Get("type")
 
+	format, ok := exportFormat(r)
+	if !ok {
+		writeErr(w, 400, "invalid_format")
+		return
+	}
+
 	if typeFilter != "" && typeFilter != "image" && typeFilter != "video" {
 		writeErr(w, 400, "invalid_type_filter"); return
 	}
 
-	creatives, err := h.Store.ListCreatives(r.Context(), adAccountID, typeFilter)
+	if adAccountID != "" {
+		if err := h.checkAdAccount(r, adAccountID); err != nil {
+			writeServiceErr(w, 400, err)
+			return
+		}
+	}
+
+	all, err := h.Store.ListCreatives(r.Context(), adAccountID, typeFilter)
 	if err != nil {
 		writeErr(w, 500, "failed to list creatives"); 
 		return 
 	}
 
+	// Sem ad_account_id a listagem cobre todos os clientes: filtra pela API key
+	creatives := all[:0]
+	for _, c := range all {
+		if auth.CheckClient(r.Context(), c.ClientUUID) == nil {
+			creatives = append(creatives, c)
+		}
+	}
+
+	if format != "" {
+		writeExportList(w, r, format, "creatives", creatives)
+		return
+	}
+
 	writeJSON(w, 200, map[string]any{
 		"creatives": creatives,
 		"count": len(creatives),
@@ -243,7 +358,7 @@ func (h *Handler) GetCreative(w http.ResponseWriter, r *http.Request) {
 	}
 
 	creative, err := h.Store.GetCreative(r.Context(), creativeID)
-	if err != nil { 
+	if err != nil || auth.CheckClient(r.Context(), creative.ClientUUID) != nil { 
 		writeErr(w, 404, "creative_not_found"); 
 		return 
 	}
@@ -259,6 +374,11 @@ func (h *Handler) ListAdAccountsByClient(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := auth.CheckClient(r.Context(), clientUUID); err != nil {
+		writeServiceErr(w, 403, err)
+		return
+	}
+
 	adAccounts, err := h.Store.ListAdAccountsByClient(r.Context(), clientUUID)
 	if err != nil {
 		writeErr(w, 500, "failed to list ad accounts")
@@ -279,9 +399,15 @@ func (h *Handler) SoftDeleteCreative(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.Store.SoftDeleteCreative(r.Context(), creativeID)
+	creative, err := h.Store.GetCreative(r.Context(), creativeID)
+	if err != nil || auth.CheckClient(r.Context(), creative.ClientUUID) != nil {
+		writeErr(w, 404, "creative_not_found")
+		return
+	}
+
+	err = h.Store.SoftDeleteCreative(r.Context(), creativeID)
 	if err != nil {
-		writeErr(w, 404, err.Error())
+		writeServiceErr(w, 404, err)
 		return
 	}
 
@@ -298,11 +424,22 @@ func (h *Handler) ListCampaigns(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	format, ok := exportFormat(r)
+	if !ok {
+		writeErr(w, 400, "invalid_format")
+		return
+	}
+
 	out, err := h.Campaigns.ListCampaigns(r.Context(), service.ListCampaignsInput{
 		AdAccountID: adAccountID,
 	})
 	if err != nil {
-		writeErr(w, 500, err.Error())
+		writeServiceErr(w, 500, err)
+		return
+	}
+
+	if format != "" {
+		writeExportList(w, r, format, "campaigns", out.Campaigns)
 		return
 	}
 
@@ -316,11 +453,22 @@ func (h *Handler) ListAdSets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	format, ok := exportFormat(r)
+	if !ok {
+		writeErr(w, 400, "invalid_format")
+		return
+	}
+
 	out, err := h.AdSets.ListAdSets(r.Context(), service.ListAdSetsInput{
 		AdAccountID: adAccountID,
 	})
 	if err != nil {
-		writeErr(w, 500, err.Error())
+		writeServiceErr(w, 500, err)
+		return
+	}
+
+	if format != "" {
+		writeExportList(w, r, format, "adsets", out.AdSets)
 		return
 	}
 
@@ -334,11 +482,22 @@ func (h *Handler) ListAds(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	format, ok := exportFormat(r)
+	if !ok {
+		writeErr(w, 400, "invalid_format")
+		return
+	}
+
 	out, err := h.Ads.ListAds(r.Context(), service.ListAdsInput{
 		AdAccountID: adAccountID,
 	})
 	if err != nil {
-		writeErr(w, 500, err.Error())
+		writeServiceErr(w, 500, err)
+		return
+	}
+
+	if format != "" {
+		writeExportList(w, r, format, "ads", out.Ads)
 		return
 	}
 
@@ -360,9 +519,13 @@ return
 }
 
 var req struct {
-AdAccountID string  `json:"ad_account_id"`
-Name        *string `json:"name"`
-Status      *string `json:"status"`
+AdAccountID    string  `json:"ad_account_id"`
+Name           *string `json:"name"`
+Status         *string `json:"status"`
+DailyBudget    *int    `json:"daily_budget"`
+LifetimeBudget *int    `json:"lifetime_budget"`
+SpendCap       *int    `json:"spend_cap"`
+BidStrategy    *string `json:"bid_strategy"`
 }
 if err := json.Unmarshal(body, &req); err != nil {
 writeErr(w, 400, "invalid_json")
@@ -374,13 +537,29 @@ writeErr(w, 400, "missing_ad_account_id")
 return
 }
 
-if err := h.Campaigns.UpdateCampaign(r.Context(), service.UpdateCampaignInput{
-AdAccountID: req.AdAccountID,
-CampaignID:  campaignID,
-Name:        req.Name,
-Status:      req.Status,
-}); err != nil {
-writeErr(w, 500, err.Error())
+dryRun, ok := dryRunRequested(r)
+if !ok {
+writeErr(w, 400, "invalid_dry_run")
+return
+}
+
+dr, err := h.Campaigns.UpdateCampaign(r.Context(), service.UpdateCampaignInput{
+AdAccountID:    req.AdAccountID,
+CampaignID:     campaignID,
+Name:           req.Name,
+Status:         req.Status,
+DailyBudget:    req.DailyBudget,
+LifetimeBudget: req.LifetimeBudget,
+SpendCap:       req.SpendCap,
+BidStrategy:    req.BidStrategy,
+DryRun:         dryRun,
+})
+if err != nil {
+writeServiceErr(w, 500, err)
+return
+}
+if dr != nil {
+writeDryRun(w, dr)
 return
 }
 
@@ -402,11 +581,23 @@ writeErr(w, 400, "missing_ad_account_id")
 return
 }
 
-if err := h.Campaigns.DeleteCampaign(r.Context(), service.DeleteCampaignInput{
+dryRun, ok := dryRunRequested(r)
+if !ok {
+writeErr(w, 400, "invalid_dry_run")
+return
+}
+
+dr, err := h.Campaigns.DeleteCampaign(r.Context(), service.DeleteCampaignInput{
 AdAccountID: adAccountID,
 CampaignID:  campaignID,
-}); err != nil {
-writeErr(w, 500, err.Error())
+DryRun:      dryRun,
+})
+if err != nil {
+writeServiceErr(w, 500, err)
+return
+}
+if dr != nil {
+writeDryRun(w, dr)
 return
 }
 
@@ -433,6 +624,13 @@ AdAccountID string  `json:"ad_account_id"`
 Name        *string `json:"name"`
 Status      *string `json:"status"`
 DailyBudget *int    `json:"daily_budget"`
+LifetimeBudget *int    `json:"lifetime_budget"`
+StartTime      *string `json:"start_time"`
+EndTime        *string `json:"end_time"`
+AdSetSchedule  *[]service.ScheduleBlock `json:"adset_schedule"`
+BidStrategy    *string        `json:"bid_strategy"`
+BidAmount      *int           `json:"bid_amount"`
+BidConstraints map[string]any `json:"bid_constraints"`
 }
 if err := json.Unmarshal(body, &req); err != nil {
 writeErr(w, 400, "invalid_json")
@@ -444,14 +642,33 @@ writeErr(w, 400, "missing_ad_account_id")
 return
 }
 
-if err := h.AdSets.UpdateAdSet(r.Context(), service.UpdateAdSetInput{
+dryRun, ok := dryRunRequested(r)
+if !ok {
+writeErr(w, 400, "invalid_dry_run")
+return
+}
+
+dr, err := h.AdSets.UpdateAdSet(r.Context(), service.UpdateAdSetInput{
 AdAccountID: req.AdAccountID,
 AdSetID:     adsetID,
 Name:        req.Name,
 Status:      req.Status,
 DailyBudget: req.DailyBudget,
-}); err != nil {
-writeErr(w, 500, err.Error())
+LifetimeBudget: req.LifetimeBudget,
+StartTime:      req.StartTime,
+EndTime:        req.EndTime,
+AdSetSchedule:  req.AdSetSchedule,
+BidStrategy:    req.BidStrategy,
+BidAmount:      req.BidAmount,
+BidConstraints: req.BidConstraints,
+DryRun:         dryRun,
+})
+if err != nil {
+writeServiceErr(w, 500, err)
+return
+}
+if dr != nil {
+writeDryRun(w, dr)
 return
 }
 
@@ -473,11 +690,23 @@ writeErr(w, 400, "missing_ad_account_id")
 return
 }
 
-if err := h.AdSets.DeleteAdSet(r.Context(), service.DeleteAdSetInput{
+dryRun, ok := dryRunRequested(r)
+if !ok {
+writeErr(w, 400, "invalid_dry_run")
+return
+}
+
+dr, err := h.AdSets.DeleteAdSet(r.Context(), service.DeleteAdSetInput{
 AdAccountID: adAccountID,
 AdSetID:     adsetID,
-}); err != nil {
-writeErr(w, 500, err.Error())
+DryRun:      dryRun,
+})
+if err != nil {
+writeServiceErr(w, 500, err)
+return
+}
+if dr != nil {
+writeDryRun(w, dr)
 return
 }
 
@@ -514,13 +743,25 @@ writeErr(w, 400, "missing_ad_account_id")
 return
 }
 
-if err := h.Ads.UpdateAd(r.Context(), service.UpdateAdInput{
+dryRun, ok := dryRunRequested(r)
+if !ok {
+writeErr(w, 400, "invalid_dry_run")
+return
+}
+
+dr, err := h.Ads.UpdateAd(r.Context(), service.UpdateAdInput{
 AdAccountID: req.AdAccountID,
 AdID:        adID,
 Name:        req.Name,
 Status:      req.Status,
-}); err != nil {
-writeErr(w, 500, err.Error())
+DryRun:      dryRun,
+})
+if err != nil {
+writeServiceErr(w, 500, err)
+return
+}
+if dr != nil {
+writeDryRun(w, dr)
 return
 }
 
@@ -542,13 +783,714 @@ writeErr(w, 400, "missing_ad_account_id")
 return
 }
 
-if err := h.Ads.DeleteAd(r.Context(), service.DeleteAdInput{
+dryRun, ok := dryRunRequested(r)
+if !ok {
+writeErr(w, 400, "invalid_dry_run")
+return
+}
+
+dr, err := h.Ads.DeleteAd(r.Context(), service.DeleteAdInput{
 AdAccountID: adAccountID,
 AdID:        adID,
-}); err != nil {
-writeErr(w, 500, err.Error())
+DryRun:      dryRun,
+})
+if err != nil {
+writeServiceErr(w, 500, err)
+return
+}
+if dr != nil {
+writeDryRun(w, dr)
 return
 }
 
 writeJSON(w, 200, map[string]any{"success": true})
 }
+
+// ======= BULK Status =======
+
+func (h *Handler) BulkUpdateStatus(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Status  string               `json:"status"`
+		Targets []service.BulkTarget `json:"targets"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, "invalid_json")
+		return
+	}
+
+	if req.Status == "" {
+		writeErr(w, 400, "missing_status")
+		return
+	}
+	if len(req.Targets) == 0 {
+		writeErr(w, 400, "missing_targets")
+		return
+	}
+
+	dryRun, ok := dryRunRequested(r)
+	if !ok {
+		writeErr(w, 400, "invalid_dry_run")
+		return
+	}
+
+	out, err := h.Bulk.UpdateStatus(r.Context(), service.BulkStatusInput{
+		Targets: req.Targets,
+		Status:  req.Status,
+		DryRun:  dryRun,
+	})
+	if err != nil {
+		writeServiceErr(w, 400, err)
+		return
+	}
+
+	writeJSON(w, 200, out)
+}
+
+// ======= LAUNCHES =======
+
+// CreateLaunch aceita o spec como JSON puro (só creatives existentes) ou como
+// multipart/form-data com o spec no campo "spec" e os arquivos dos creatives novos
+// em campos referenciados por creative.file / creative.thumbnail. Com dry run
+// devolve o plano (200 se todos os passos são válidos, 422 se não) sem criar nada.
+func (h *Handler) CreateLaunch(w http.ResponseWriter, r *http.Request) {
+	dryRun, ok := dryRunRequested(r)
+	if !ok {
+		writeErr(w, 400, "invalid_dry_run")
+		return
+	}
+
+	in := service.LaunchInput{Files: map[string]service.LaunchFile{}}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(1024 << 20); err != nil {
+			writeErr(w, 400, "invalid_multipart")
+			return
+		}
+		if err := json.Unmarshal([]byte(r.FormValue("spec")), &in.Spec); err != nil {
+			writeErr(w, 400, "invalid_spec")
+			return
+		}
+
+		for field, headers := range r.MultipartForm.File {
+			if len(headers) == 0 {
+				continue
+			}
+			f, err := headers[0].Open()
+			if err != nil {
+				writeErr(w, 400, "invalid_file")
+				return
+			}
+			b, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				writeErr(w, 400, "invalid_file")
+				return
+			}
+			in.Files[field] = service.LaunchFile{Name: headers[0].Filename, Bytes: b}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&in.Spec); err != nil {
+		writeErr(w, 400, "invalid_json")
+		return
+	}
+
+	if dryRun {
+		plan, err := h.Launches.Plan(r.Context(), in)
+		if err != nil {
+			writeServiceErr(w, 400, err)
+			return
+		}
+		status := 200
+		if !plan.Valid {
+			status = 422
+		}
+		writeJSON(w, status, plan)
+		return
+	}
+
+	launch, err := h.Launches.Launch(r.Context(), in)
+	if err != nil {
+		// Launch registrado mas com falha: devolve o registro com o resultado do rollback
+		if errors.Is(err, service.ErrLaunchFailed) {
+			status, body := serviceErrResponse(w, 400, err)
+			body["launch"] = launch
+			writeJSON(w, status, body)
+			return
+		}
+		writeServiceErr(w, 400, err)
+		return
+	}
+
+	writeJSON(w, 200, launch)
+}
+
+func (h *Handler) ListLaunches(w http.ResponseWriter, r *http.Request) {
+	adAccountID := r.URL.Query().Get("ad_account_id")
+	if adAccountID == "" {
+		writeErr(w, 400, "missing_ad_account_id")
+		return
+	}
+
+	launches, err := h.Launches.ListLaunches(r.Context(), adAccountID)
+	if errors.Is(err, auth.ErrForbidden) {
+		writeServiceErr(w, 403, err)
+		return
+	}
+	if err != nil {
+		writeErr(w, 500, "failed to list launches")
+		return
+	}
+
+	writeJSON(w, 200, map[string]any{
+		"launches": launches,
+		"count":    len(launches),
+	})
+}
+
+func (h *Handler) GetLaunch(w http.ResponseWriter, r *http.Request) {
+	launchID := chi.URLParam(r, "launch_id")
+	if launchID == "" {
+		writeErr(w, 400, "missing_launch_id")
+		return
+	}
+
+	launch, err := h.Launches.GetLaunch(r.Context(), launchID)
+	if err != nil {
+		writeErr(w, 404, "launch_not_found")
+		return
+	}
+
+	writeJSON(w, 200, launch)
+}
+
+// UndoLaunch remove (soft delete) todos os objetos criados por um launch
+func (h *Handler) UndoLaunch(w http.ResponseWriter, r *http.Request) {
+	launchID := chi.URLParam(r, "launch_id")
+	if launchID == "" {
+		writeErr(w, 400, "missing_launch_id")
+		return
+	}
+
+	launch, err := h.Launches.Undo(r.Context(), launchID)
+	if err != nil {
+		writeServiceErr(w, 400, err)
+		return
+	}
+
+	writeJSON(w, 200, launch)
+}
+
+// ======= COPY Campaign / AdSet / Ad =======
+
+func (h *Handler) CopyCampaign(w http.ResponseWriter, r *http.Request) {
+	h.copyObject(w, r, "campaign", chi.URLParam(r, "campaign_id"))
+}
+
+func (h *Handler) CopyAdSet(w http.ResponseWriter, r *http.Request) {
+	h.copyObject(w, r, "adset", chi.URLParam(r, "adset_id"))
+}
+
+func (h *Handler) CopyAd(w http.ResponseWriter, r *http.Request) {
+	h.copyObject(w, r, "ad", chi.URLParam(r, "ad_id"))
+}
+
+func (h *Handler) copyObject(w http.ResponseWriter, r *http.Request, objectType, objectID string) {
+	if objectID == "" {
+		writeErr(w, 400, "missing_"+objectType+"_id")
+		return
+	}
+
+	var req struct {
+		AdAccountID   string `json:"ad_account_id"`
+		DeepCopy      bool   `json:"deep_copy"`
+		StatusOption  string `json:"status_option"`
+		RenameOptions struct {
+			RenameStrategy string `json:"rename_strategy"`
+			RenamePrefix   string `json:"rename_prefix"`
+			RenameSuffix   string `json:"rename_suffix"`
+		} `json:"rename_options"`
+		CampaignID        string `json:"campaign_id"` // novo pai (cópia de adset)
+		AdSetID           string `json:"adset_id"`    // novo pai (cópia de ad)
+		TargetAdAccountID string `json:"target_ad_account_id"`
+		TargetPixelID     string `json:"target_pixel_id"`
+		Async             bool   `json:"async"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, "invalid_json")
+		return
+	}
+
+	if req.AdAccountID == "" {
+		writeErr(w, 400, "missing_ad_account_id")
+		return
+	}
+
+	targetParentID := req.CampaignID
+	if objectType == "ad" {
+		targetParentID = req.AdSetID
+	}
+
+	out, err := h.Copies.Copy(r.Context(), service.CopyInput{
+		AdAccountID:       req.AdAccountID,
+		ObjectType:        objectType,
+		ObjectID:          objectID,
+		DeepCopy:          req.DeepCopy,
+		StatusOption:      req.StatusOption,
+		RenameStrategy:    req.RenameOptions.RenameStrategy,
+		RenamePrefix:      req.RenameOptions.RenamePrefix,
+		RenameSuffix:      req.RenameOptions.RenameSuffix,
+		TargetParentID:    targetParentID,
+		TargetAdAccountID: req.TargetAdAccountID,
+		TargetPixelID:     req.TargetPixelID,
+		Async:             req.Async,
+	})
+	if err != nil {
+		writeServiceErr(w, 400, err)
+		return
+	}
+
+	if out.Status == "pending" {
+		writeJSON(w, 202, out)
+		return
+	}
+	writeJSON(w, 200, out)
+}
+
+// GetCopy consulta uma cópia assíncrona (request set da Meta)
+func (h *Handler) GetCopy(w http.ResponseWriter, r *http.Request) {
+	requestSetID := chi.URLParam(r, "request_set_id")
+	if requestSetID == "" {
+		writeErr(w, 400, "missing_request_set_id")
+		return
+	}
+
+	adAccountID := r.URL.Query().Get("ad_account_id")
+	if adAccountID == "" {
+		writeErr(w, 400, "missing_ad_account_id")
+		return
+	}
+
+	out, err := h.Copies.GetCopy(r.Context(), service.GetCopyInput{
+		AdAccountID:  adAccountID,
+		RequestSetID: requestSetID,
+	})
+	if err != nil {
+		writeServiceErr(w, 500, err)
+		return
+	}
+
+	writeJSON(w, 200, out)
+}
+
+// ValidateTargeting valida um targeting spec sem chamar a Meta e devolve o spec
+// normalizado (como seria enviado no payload do adset)
+func (h *Handler) ValidateTargeting(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Targeting *targeting.Spec `json:"targeting"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, "invalid_json")
+		return
+	}
+	if req.Targeting == nil {
+		writeErr(w, 400, "missing_targeting")
+		return
+	}
+
+	errs := []targeting.FieldError{}
+	if err := req.Targeting.Validate(); err != nil {
+		var ve *targeting.ValidationError
+		if !errors.As(err, &ve) {
+			writeServiceErr(w, 400, err)
+			return
+		}
+		errs = ve.Errors
+	}
+
+	writeJSON(w, 200, map[string]any{
+		"valid":     len(errs) == 0,
+		"errors":    errs,
+		"targeting": req.Targeting,
+	})
+}
+
+// SearchTargeting busca IDs de interesses, keys de localização e categorias
+// (behaviors, demographics...) na Meta; type=sentence_lines descreve um spec
+// passado em ?targeting= (JSON)
+func (h *Handler) SearchTargeting(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	in := service.SearchTargetingInput{
+		AdAccountID: q.Get("ad_account_id"),
+		Type:        q.Get("type"),
+		Query:       q.Get("q"),
+		CountryCode: q.Get("country_code"),
+		Locale:      q.Get("locale"),
+	}
+	if in.AdAccountID == "" {
+		writeErr(w, 400, "missing_ad_account_id")
+		return
+	}
+	if in.Type == "" {
+		writeErr(w, 400, "missing_type")
+		return
+	}
+	in.LocationTypes = splitList(q.Get("location_types"))
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			writeErr(w, 400, "invalid_limit")
+			return
+		}
+		in.Limit = limit
+	}
+	if v := q.Get("targeting"); v != "" {
+		in.Targeting = &targeting.Spec{}
+		if err := json.Unmarshal([]byte(v), in.Targeting); err != nil {
+			writeErr(w, 400, "invalid_targeting_json")
+			return
+		}
+	}
+
+	out, err := h.Targeting.Search(r.Context(), in)
+	if err != nil {
+		writeServiceErr(w, 400, err)
+		return
+	}
+	writeJSON(w, 200, out)
+}
+
+// ======= SCHEDULED ACTIONS =======
+
+// CreateScheduledAction agenda uma mudança de status ou orçamento. Repetir com a
+// mesma idempotency_key devolve a ação já criada (200 em vez de 201).
+func (h *Handler) CreateScheduledAction(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AdAccountID    string                        `json:"ad_account_id"`
+		ObjectType     string                        `json:"object_type"`
+		ObjectID       string                        `json:"object_id"`
+		Action         string                        `json:"action"`
+		Params         service.ScheduledActionParams `json:"params"`
+		RunAt          string                        `json:"run_at"`
+		IdempotencyKey string                        `json:"idempotency_key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, "invalid_json")
+		return
+	}
+
+	if req.AdAccountID == "" {
+		writeErr(w, 400, "missing_ad_account_id")
+		return
+	}
+
+	action, created, err := h.Scheduled.Create(r.Context(), service.CreateScheduledActionInput{
+		AdAccountID:    req.AdAccountID,
+		ObjectType:     req.ObjectType,
+		ObjectID:       req.ObjectID,
+		Action:         req.Action,
+		Params:         req.Params,
+		RunAt:          req.RunAt,
+		IdempotencyKey: req.IdempotencyKey,
+	})
+	if err != nil {
+		writeServiceErr(w, 400, err)
+		return
+	}
+
+	if !created {
+		writeJSON(w, 200, action)
+		return
+	}
+	writeJSON(w, 201, action)
+}
+
+func (h *Handler) ListScheduledActions(w http.ResponseWriter, r *http.Request) {
+	adAccountID := r.URL.Query().Get("ad_account_id")
+	if adAccountID == "" {
+		writeErr(w, 400, "missing_ad_account_id")
+		return
+	}
+
+	actions, err := h.Scheduled.List(r.Context(), adAccountID, r.URL.Query().Get("status"))
+	if errors.Is(err, auth.ErrForbidden) {
+		writeServiceErr(w, 403, err)
+		return
+	}
+	if err != nil {
+		writeErr(w, 500, "failed to list scheduled actions")
+		return
+	}
+	if actions == nil {
+		actions = []storage.ScheduledAction{}
+	}
+
+	writeJSON(w, 200, map[string]any{"scheduled_actions": actions})
+}
+
+// GetScheduledAction devolve a ação com o histórico de tentativas
+func (h *Handler) GetScheduledAction(w http.ResponseWriter, r *http.Request) {
+	actionID := chi.URLParam(r, "action_id")
+	if actionID == "" {
+		writeErr(w, 400, "missing_action_id")
+		return
+	}
+
+	action, err := h.Scheduled.Get(r.Context(), actionID)
+	if err != nil {
+		writeErr(w, 404, "scheduled_action_not_found")
+		return
+	}
+
+	writeJSON(w, 200, action)
+}
+
+func (h *Handler) CancelScheduledAction(w http.ResponseWriter, r *http.Request) {
+	actionID := chi.URLParam(r, "action_id")
+	if actionID == "" {
+		writeErr(w, 400, "missing_action_id")
+		return
+	}
+
+	action, err := h.Scheduled.Cancel(r.Context(), actionID)
+	if err != nil {
+		if errors.Is(err, storage.ErrScheduledActionNotPending) {
+			writeErr(w, 409, "scheduled_action_not_pending")
+			return
+		}
+		writeErr(w, 404, "scheduled_action_not_found")
+		return
+	}
+
+	writeJSON(w, 200, action)
+}
+
+// ======= INSIGHTS =======
+
+// GetInsights consulta insights na Meta (síncrono). Listas vão separadas por vírgula:
+// ?fields=spend,ctr&breakdowns=age,gender&action_attribution_windows=7d_click,1d_view
+// O JSON vem paginado (?limit=, ?after= com o paging.after da página anterior);
+// exports sem ?limit= trazem todas as linhas.
+func (h *Handler) GetInsights(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	adAccountID := q.Get("ad_account_id")
+	if adAccountID == "" {
+		writeErr(w, 400, "missing_ad_account_id")
+		return
+	}
+
+	format, ok := exportFormat(r)
+	if !ok {
+		writeErr(w, 400, "invalid_format")
+		return
+	}
+
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeErr(w, 400, "invalid_limit")
+			return
+		}
+		limit = n
+	} else if format == "" {
+		limit = service.InsightsDefaultPageSize
+	}
+
+	out, err := h.Insights.GetInsights(r.Context(), service.InsightsInput{
+		AdAccountID:        adAccountID,
+		ObjectID:           q.Get("object_id"),
+		Level:              q.Get("level"),
+		Fields:             splitList(q.Get("fields")),
+		DatePreset:         q.Get("date_preset"),
+		Since:              q.Get("since"),
+		Until:              q.Get("until"),
+		TimeIncrement:      q.Get("time_increment"),
+		Breakdowns:         splitList(q.Get("breakdowns")),
+		AttributionWindows: splitList(q.Get("action_attribution_windows")),
+		Limit:              limit,
+		After:              q.Get("after"),
+	})
+	if err != nil {
+		writeServiceErr(w, 400, err)
+		return
+	}
+
+	if format != "" {
+		// ?fields= já define as colunas; breakdowns entram ao lado
+		var columns []string
+		if fields := splitList(q.Get("fields")); len(fields) > 0 {
+			columns = append(fields, splitList(q.Get("breakdowns"))...)
+		}
+		writeExportRows(w, format, "insights", columns, out.Rows)
+		return
+	}
+
+	writeJSON(w, 200, out)
+}
+
+// splitList separa listas de query string ("a, b,c") ignorando itens vazios
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// CreateReport enfileira um report assíncrono de insights (mesmos parâmetros de
+// GET /v1/insights, em JSON). O resultado sai em GET /v1/reports/{report_id}.
+func (h *Handler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	var req service.InsightsInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, 400, "invalid_json")
+		return
+	}
+	if req.AdAccountID == "" {
+		writeErr(w, 400, "missing_ad_account_id")
+		return
+	}
+
+	out, err := h.Reports.CreateReport(r.Context(), req)
+	if err != nil {
+		writeServiceErr(w, 400, err)
+		return
+	}
+
+	writeJSON(w, 202, out)
+}
+
+// GetReport devolve status/progresso; quando succeeded inclui as linhas
+// paginadas por ?limit= (padrão 100, máx. 1000) e ?offset=
+func (h *Handler) GetReport(w http.ResponseWriter, r *http.Request) {
+	reportID := chi.URLParam(r, "report_id")
+	if reportID == "" {
+		writeErr(w, 400, "missing_report_id")
+		return
+	}
+
+	format, ok := exportFormat(r)
+	if !ok {
+		writeErr(w, 400, "invalid_format")
+		return
+	}
+
+	limit, offset := 0, 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeErr(w, 400, "invalid_limit")
+			return
+		}
+		limit = n
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeErr(w, 400, "invalid_offset")
+			return
+		}
+		offset = n
+	}
+
+	out, err := h.Reports.GetReport(r.Context(), reportID, limit, offset)
+	if err != nil {
+		if errors.Is(err, service.ErrReportNotFound) {
+			writeErr(w, 404, "report_not_found")
+			return
+		}
+		writeServiceErr(w, 500, err)
+		return
+	}
+
+	if format != "" {
+		if out.Status != storage.JobSucceeded {
+			writeJSON(w, 409, map[string]any{"error": "report_not_ready", "status": out.Status})
+			return
+		}
+		// Export traz todas as linhas (limit/offset não se aplicam)
+		writeExport(w, format, "report-"+reportID, splitList(r.URL.Query().Get("fields")), func(emit func(map[string]any) error) error {
+			return h.Reports.EachReportRow(r.Context(), reportID, func(raw json.RawMessage) error {
+				row, err := decodeRow(raw)
+				if err != nil {
+					return err
+				}
+				return emit(row)
+			})
+		})
+		return
+	}
+
+	writeJSON(w, 200, out)
+}
+
+// CreativeLeaderboard ranqueia os creatives da conta por performance no período
+// (?metric=spend|impressions|clicks|conversions|ctr|cpa|roas, ?action_type= para CPA/ROAS)
+func (h *Handler) CreativeLeaderboard(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	adAccountID := q.Get("ad_account_id")
+	if adAccountID == "" {
+		writeErr(w, 400, "missing_ad_account_id")
+		return
+	}
+
+	format, ok := exportFormat(r)
+	if !ok {
+		writeErr(w, 400, "invalid_format")
+		return
+	}
+
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeErr(w, 400, "invalid_limit")
+			return
+		}
+		limit = n
+	}
+
+	out, err := h.Insights.CreativeLeaderboard(r.Context(), service.CreativeLeaderboardInput{
+		AdAccountID: adAccountID,
+		Since:       q.Get("since"),
+		Until:       q.Get("until"),
+		DatePreset:  q.Get("date_preset"),
+		Metric:      q.Get("metric"),
+		ActionType:  q.Get("action_type"),
+		Limit:       limit,
+	})
+	if err != nil {
+		writeServiceErr(w, 400, err)
+		return
+	}
+
+	if format != "" {
+		writeExportList(w, r, format, "creative-leaderboard", out.Creatives)
+		return
+	}
+
+	writeJSON(w, 200, out)
+}
+
+// GetMetaStatus mostra os circuit breakers da Meta com falhas recentes e a
+// fila do scheduler neste processo
+func (h *Handler) GetMetaStatus(w http.ResponseWriter, r *http.Request) {
+	breakers := h.MetaBreakers.Snapshot()
+	open := 0
+	for _, b := range breakers {
+		if b.State != meta.BreakerClosed {
+			open++
+		}
+	}
+	writeJSON(w, 200, map[string]any{
+		"scheduler":     h.Scheduler.Stats(),
+		"open_circuits": open,
+		"breakers":      breakers,
+	})
+}
+
+// GetMetaUsage mostra o último uso de rate limit informado pela Meta (headers
+// X-App-Usage, X-Ad-Account-Usage e X-Business-Use-Case-Usage) neste processo
+func (h *Handler) GetMetaUsage(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, 200, h.MetaUsage.Snapshot(r.URL.Query().Get("ad_account_id")))
+}
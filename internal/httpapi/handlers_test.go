@@ -0,0 +1,41 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/service"
+)
+
+func TestHealthAndMetaStatus(t *testing.T) {
+	breakers := meta.NewBreakerSet()
+	breakers.Failures = 1
+	breakers.Failure("act_1", "objects", errors.New("meta down"), time.Now())
+	h := &Handler{MetaBreakers: breakers, Scheduler: service.NewScheduler(2, 1, 1)}
+
+	// Health é público: nada de contas, circuitos ou fila
+	w := httptest.NewRecorder()
+	h.Health(w, httptest.NewRequest("GET", "/v1/health", nil))
+	if got := strings.TrimSpace(w.Body.String()); w.Code != 200 || got != `{"ok":true}` {
+		t.Errorf("health = %d %s, want 200 {\"ok\":true}", w.Code, got)
+	}
+
+	w = httptest.NewRecorder()
+	h.GetMetaStatus(w, httptest.NewRequest("GET", "/v1/meta/status", nil))
+	var status struct {
+		Scheduler    map[string]any       `json:"scheduler"`
+		OpenCircuits int                  `json:"open_circuits"`
+		Breakers     []meta.BreakerStatus `json:"breakers"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.OpenCircuits != 1 || len(status.Breakers) != 1 || status.Breakers[0].AdAccountID != "act_1" || status.Scheduler == nil {
+		t.Errorf("meta status = %+v", status)
+	}
+}
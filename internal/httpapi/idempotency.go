@@ -0,0 +1,269 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"creative-service/internal/auth"
+	"creative-service/internal/meta"
+	"creative-service/internal/storage"
+)
+
+// ======= IDEMPOTENCY-KEY =======
+// POSTs de criação aceitam o header Idempotency-Key. A primeira requisição com a
+// chave reserva o registro em idempotency_keys (migration 010), executa e grava a
+// resposta; os retries com o mesmo body recebem a resposta gravada, com body
+// diferente recebem 409. Erros que não chegaram à Meta não são gravados: a chave
+// é liberada para o retry. Um retry que chega com o original ainda em execução
+// espera até idempotencyWait pela resposta antes de desistir com 409.
+
+const (
+	idempotencyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+
+	defaultIdempotencyTTL = 24 * time.Hour
+	idempotencyLease      = time.Minute      // renovado a cada idempotencyLease/3 enquanto o handler roda
+	idempotencyWait       = 10 * time.Second // quanto um retry concorrente espera o original
+	idempotencyPoll       = 250 * time.Millisecond
+)
+
+// Idempotent aplica Idempotency-Key ao handler; sem o header passa direto
+func (h *Handler) Idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			writeErr(w, 400, "invalid_idempotency_key")
+			return
+		}
+		// Chaves são separadas por API key: outro cliente com a mesma chave não vê esta resposta
+		if p, ok := auth.FromContext(r.Context()); ok {
+			key = p.KeyID + ":" + key
+		}
+
+		hash, cleanup, err := hashRequest(r)
+		if err != nil {
+			writeErr(w, 400, "invalid_body")
+			return
+		}
+		defer cleanup()
+
+		ttl := h.IdempotencyTTL
+		if ttl <= 0 {
+			ttl = defaultIdempotencyTTL
+		}
+
+		ctx := r.Context()
+		method, path := r.Method, r.URL.Path
+		deadline := time.Now().Add(idempotencyWait)
+		for {
+			rec, started, err := h.Store.BeginIdempotentRequest(ctx, key, method, path, hash, idempotencyLease, ttl)
+			if err != nil {
+				writeServiceErr(w, 500, fmt.Errorf("idempotency key: %w", err))
+				return
+			}
+			if started {
+				break
+			}
+			if rec.RequestHash != hash {
+				writeErr(w, 409, "idempotency_key_reused")
+				return
+			}
+			if rec.Status == storage.IdempotencyCompleted {
+				replayResponse(w, rec)
+				return
+			}
+			if time.Now().After(deadline) {
+				w.Header().Set("Retry-After", retryAfterSeconds(time.Second))
+				writeErr(w, 409, "idempotency_key_in_progress")
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(idempotencyPoll):
+			}
+		}
+
+		// O registro é fechado mesmo com o request cancelado: o trabalho na Meta
+		// pode ter acontecido
+		bg := context.WithoutCancel(ctx)
+		stop := h.renewIdempotencyLease(bg, key, method, path)
+		completed := false
+		defer func() {
+			stop()
+			if completed {
+				return
+			}
+			// handler entrou em pânico ou a resposta não deve ser gravada
+			if err := h.Store.ReleaseIdempotentRequest(bg, key, method, path); err != nil {
+				log.Printf("idempotency: release %s %s: %v", method, path, err)
+			}
+		}()
+
+		rw, store := serveTracked(next, w, r)
+		if !store {
+			return
+		}
+		if err := h.Store.CompleteIdempotentRequest(bg, key, method, path, rw.status, rw.Header().Get("Content-Type"), rw.body.Bytes()); err != nil {
+			log.Printf("idempotency: complete %s %s: %v", method, path, err)
+			return
+		}
+		completed = true
+	})
+}
+
+// serveTracked roda o handler gravando a resposta e marcando se ele chegou a
+// chamar a Meta, e diz se a resposta deve ficar gravada na chave
+func serveTracked(next http.Handler, w http.ResponseWriter, r *http.Request) (*recordingWriter, bool) {
+	rw := &recordingWriter{ResponseWriter: w, status: 200}
+	ctx := meta.WithCallTracker(r.Context())
+	next.ServeHTTP(rw, r.WithContext(ctx))
+	return rw, storableResponse(rw.status, meta.CallAttempted(ctx))
+}
+
+// storableResponse decide se a resposta fica gravada na chave. 429 e 503
+// significam que nada foi criado (throttling, circuito aberto, Meta
+// indisponível). Erros sem chamada à Meta são locais (validação, banco, token)
+// e também liberam a chave. Sucessos e os demais erros depois de chamar a Meta,
+// inclusive 5xx de criação incerta, são gravados.
+func storableResponse(status int, metaCalled bool) bool {
+	if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+		return false
+	}
+	return status < 400 || metaCalled
+}
+
+// renewIdempotencyLease mantém locked_until à frente enquanto o handler roda, para
+// uploads longos não terem a chave retomada por um retry
+func (h *Handler) renewIdempotencyLease(ctx context.Context, key, method, path string) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(idempotencyLease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := h.Store.ExtendIdempotentRequest(ctx, key, method, path, idempotencyLease); err != nil {
+					log.Printf("idempotency: extend %s %s: %v", method, path, err)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func replayResponse(w http.ResponseWriter, rec storage.IdempotencyRecord) {
+	if rec.ResponseContentType != nil && *rec.ResponseContentType != "" {
+		w.Header().Set("Content-Type", *rec.ResponseContentType)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	status := 200
+	if rec.ResponseStatus != nil {
+		status = *rec.ResponseStatus
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(rec.ResponseBody)
+}
+
+// recordingWriter repassa a resposta ao cliente e guarda uma cópia para gravar
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(p)
+	return rw.ResponseWriter.Write(p)
+}
+
+// hashRequest resume query, dry run e body do request e devolve o body pronto para o
+// handler ler de novo. Multipart é lido parte a parte (nome, arquivo, conteúdo),
+// porque o boundary muda a cada envio do browser; o body bruto vai para um
+// arquivo temporário em vez da memória, já que vídeos chegam a 1GB.
+func hashRequest(r *http.Request) (hash string, cleanup func(), err error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n", r.URL.Query().Encode())
+	// X-Dry-Run não está na query: sem ele no hash, o retry real de um dry run receberia a validação gravada
+	dryRun, _ := dryRunRequested(r)
+	fmt.Fprintf(h, "dry_run=%t\n", dryRun)
+
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return "", nil, err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		h.Write(body)
+		return hex.EncodeToString(h.Sum(nil)), func() {}, nil
+	}
+
+	f, err := os.CreateTemp("", "idempotency-*")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() {
+		f.Close()
+		os.Remove(f.Name())
+	}
+
+	tee := io.TeeReader(r.Body, f)
+	mr := multipart.NewReader(tee, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			cleanup()
+			return "", nil, err
+		}
+		fmt.Fprintf(h, "part %q %q\n", part.FormName(), part.FileName())
+		n, err := io.Copy(h, part)
+		if err != nil {
+			cleanup()
+			return "", nil, err
+		}
+		fmt.Fprintf(h, "\n%d\n", n)
+	}
+	// epílogo depois do último boundary, para o handler receber o body inteiro
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	r.Body = f
+	return hex.EncodeToString(h.Sum(nil)), cleanup, nil
+}
@@ -0,0 +1,195 @@
+package httpapi
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/meta/metatest"
+)
+
+// multipartBody monta um upload com o boundary informado
+func multipartBody(t *testing.T, boundary, content string) (*bytes.Buffer, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.SetBoundary(boundary); err != nil {
+		t.Fatal(err)
+	}
+	_ = mw.WriteField("name", "creative")
+	fw, _ := mw.CreateFormFile("image", "a.png")
+	_, _ = fw.Write([]byte(content))
+	_ = mw.Close()
+	return &buf, mw.FormDataContentType()
+}
+
+func TestHashRequest(t *testing.T) {
+	type request struct {
+		target, contentType, dryRun string
+		body                        io.Reader
+	}
+	jsonReq := func(target, body, dryRun string) request {
+		return request{target: target, contentType: "application/json", dryRun: dryRun, body: strings.NewReader(body)}
+	}
+	upload := func(boundary, content string) request {
+		body, contentType := multipartBody(t, boundary, content)
+		return request{target: "/v1/creatives/image", contentType: contentType, body: body}
+	}
+
+	tests := []struct {
+		name     string
+		a, b     request
+		wantSame bool
+	}{
+		{name: "same body", a: jsonReq("/v1/campaigns", `{"name":"a"}`, ""), b: jsonReq("/v1/campaigns", `{"name":"a"}`, ""), wantSame: true},
+		{name: "different body", a: jsonReq("/v1/campaigns", `{"name":"a"}`, ""), b: jsonReq("/v1/campaigns", `{"name":"b"}`, "")},
+		{name: "dry run header", a: jsonReq("/v1/campaigns", `{"name":"a"}`, ""), b: jsonReq("/v1/campaigns", `{"name":"a"}`, "true")},
+		{name: "dry run query and header", a: jsonReq("/v1/campaigns?dry_run=true", `{}`, ""), b: jsonReq("/v1/campaigns", `{}`, "true")},
+		{name: "explicit dry_run=false", a: jsonReq("/v1/campaigns", `{}`, "false"), b: jsonReq("/v1/campaigns", `{}`, ""), wantSame: true},
+		{name: "multipart boundary is ignored", a: upload("aaaa", "png"), b: upload("bbbb", "png"), wantSame: true},
+		{name: "multipart content", a: upload("aaaa", "png"), b: upload("aaaa", "gif")},
+	}
+
+	hash := func(t *testing.T, req request) string {
+		r := httptest.NewRequest("POST", req.target, req.body)
+		r.Header.Set("Content-Type", req.contentType)
+		if req.dryRun != "" {
+			r.Header.Set("X-Dry-Run", req.dryRun)
+		}
+		h, cleanup, err := hashRequest(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cleanup()
+		// O handler precisa receber o body inteiro depois do hash
+		if _, err := io.ReadAll(r.Body); err != nil {
+			t.Fatalf("read body after hash: %v", err)
+		}
+		return h
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			same := hash(t, tt.a) == hash(t, tt.b)
+			if same != tt.wantSame {
+				t.Errorf("same hash = %v, want %v", same, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestHashRequestKeepsBody(t *testing.T) {
+	body, contentType := multipartBody(t, "boundary", "png bytes")
+	raw := body.String()
+	r := httptest.NewRequest("POST", "/v1/creatives/image", body)
+	r.Header.Set("Content-Type", contentType)
+
+	_, cleanup, err := hashRequest(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	got, _ := io.ReadAll(r.Body)
+	if string(got) != raw {
+		t.Errorf("body after hash differs from the original (%d vs %d bytes)", len(got), len(raw))
+	}
+}
+
+func TestStorableResponse(t *testing.T) {
+	tests := []struct {
+		status     int
+		metaCalled bool
+		want       bool
+	}{
+		{status: 201, want: true},
+		{status: 200, metaCalled: true, want: true},
+		{status: 400, want: false},
+		{status: 403, want: false},
+		{status: 500, want: false},
+		{status: 400, metaCalled: true, want: true},
+		{status: 502, metaCalled: true, want: true},
+		{status: 429, metaCalled: true, want: false},
+		{status: 503, metaCalled: true, want: false},
+	}
+	for _, tt := range tests {
+		if got := storableResponse(tt.status, tt.metaCalled); got != tt.want {
+			t.Errorf("storableResponse(%d, %v) = %v, want %v", tt.status, tt.metaCalled, got, tt.want)
+		}
+	}
+}
+
+func TestServeTracked(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	campaign := srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "c"})
+
+	// act_2 tem o circuito aberto: a chamada não sai do processo
+	mc := srv.Client("token")
+	mc.Breakers.Failures = 1
+	mc.Breakers.Failure("act_2", "objects", errors.New("meta down"), time.Now())
+
+	tests := []struct {
+		name      string
+		account   string // "" não chama a Meta
+		status    int
+		wantStore bool
+	}{
+		{name: "success without meta", status: 201, wantStore: true},
+		{name: "local error releases the key", status: 400},
+		{name: "error after calling meta is stored", account: "act_1", status: 400, wantStore: true},
+		{name: "success after calling meta is stored", account: "act_1", status: 200, wantStore: true},
+		{name: "throttling is never stored", account: "act_1", status: 429},
+		{name: "call blocked by the breaker does not count", account: "act_2", status: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.account != "" {
+					_, _ = mc.GetObject(meta.WithAdAccount(r.Context(), tt.account), campaign, nil)
+				}
+				writeJSON(w, tt.status, map[string]any{"status": tt.status})
+			})
+
+			w := httptest.NewRecorder()
+			rw, store := serveTracked(next, w, httptest.NewRequest("POST", "/v1/campaigns", nil))
+			if store != tt.wantStore {
+				t.Errorf("store = %v, want %v", store, tt.wantStore)
+			}
+			// A cópia gravada é a mesma resposta que o cliente recebeu
+			if rw.status != tt.status || w.Code != tt.status || rw.body.String() != w.Body.String() {
+				t.Errorf("recorded %d %q, sent %d %q", rw.status, rw.body.String(), w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestIdempotentKeyHeader(t *testing.T) {
+	calls := 0
+	h := (&Handler{}).Idempotent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		writeJSON(w, 201, map[string]any{})
+	}))
+
+	// Sem o header passa direto, sem tocar no banco
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/v1/campaigns", strings.NewReader(`{}`)))
+	if w.Code != 201 || calls != 1 {
+		t.Errorf("without key: status = %d, calls = %d", w.Code, calls)
+	}
+
+	r := httptest.NewRequest("POST", "/v1/campaigns", strings.NewReader(`{}`))
+	r.Header.Set(idempotencyHeader, strings.Repeat("k", maxIdempotencyKeyLen+1))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 400 || !strings.Contains(w.Body.String(), "invalid_idempotency_key") || calls != 1 {
+		t.Errorf("long key: status = %d %s, calls = %d", w.Code, w.Body.String(), calls)
+	}
+}
@@ -22,3 +22,20 @@ func AccessLog(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// RejectDryRun protege rotas de escrita sem dry run: ?dry_run=true ou X-Dry-Run
+// respondem 400 em vez de executar a operação de verdade
+func RejectDryRun(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dryRun, ok := dryRunRequested(r)
+		if !ok {
+			writeErr(w, http.StatusBadRequest, "invalid_dry_run")
+			return
+		}
+		if dryRun {
+			writeErr(w, http.StatusBadRequest, "dry_run_not_supported")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -2,7 +2,16 @@ package httpapi
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
+
+	"creative-service/internal/auth"
+	"creative-service/internal/meta"
+	"creative-service/internal/service"
+	"creative-service/internal/targeting"
 )
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
@@ -14,3 +23,135 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 func writeErr(w http.ResponseWriter, status int, msg string) {
 	writeJSON(w, status, map[string]any{"error": msg})
 }
+
+// writeServiceErr traduz erros de serviço: validação de targeting vira 400 com
+// a lista de campos, erros da Meta viram status e código estáveis (metaErrStatus);
+// contexto sem principal vira 401, API key sem acesso ao cliente e objeto de outra ad account viram 403; os demais seguem com status e a mensagem
+func writeServiceErr(w http.ResponseWriter, status int, err error) {
+	status, body := serviceErrResponse(w, status, err)
+	writeJSON(w, status, body)
+}
+
+// serviceErrResponse monta status e body de writeServiceErr (e grava Retry-After),
+// para handlers que acrescentam campos à resposta de erro
+func serviceErrResponse(w http.ResponseWriter, status int, err error) (int, map[string]any) {
+	if errors.Is(err, auth.ErrUnauthenticated) {
+		return 401, map[string]any{"error": "unauthenticated"}
+	}
+	if errors.Is(err, auth.ErrForbidden) {
+		return 403, map[string]any{"error": "client_not_allowed", "message": err.Error()}
+	}
+	if errors.Is(err, service.ErrObjectNotInAdAccount) {
+		return 403, map[string]any{"error": "object_not_in_ad_account", "message": err.Error()}
+	}
+
+	var ve *targeting.ValidationError
+	if errors.As(err, &ve) {
+		return 400, map[string]any{"error": err.Error(), "errors": ve.Errors}
+	}
+
+	var te *meta.ThrottledError
+	if errors.As(err, &te) {
+		w.Header().Set("Retry-After", retryAfterSeconds(te.RetryAfter))
+		return 429, map[string]any{"error": "meta_rate_limited", "message": err.Error()}
+	}
+
+	var ce *meta.CircuitOpenError
+	if errors.As(err, &ce) {
+		w.Header().Set("Retry-After", retryAfterSeconds(ce.RetryAfter))
+		return 503, map[string]any{"error": "meta_circuit_open", "message": err.Error()}
+	}
+
+	// Sem Retry-After: repetir pode duplicar o objeto, quem chamou precisa conferir antes
+	if errors.Is(err, meta.ErrCreateOutcomeUnknown) {
+		return 502, map[string]any{"error": "meta_create_outcome_unknown", "message": err.Error()}
+	}
+
+	var me *meta.Error
+	if errors.As(err, &me) {
+		status, code := metaErrStatus(me.Kind)
+		body := map[string]any{
+			"error":   code,
+			"message": me.Message,
+			"meta": map[string]any{
+				"code":       me.Code,
+				"subcode":    me.Subcode,
+				"type":       me.Type,
+				"fbtrace_id": me.FbTraceID,
+			},
+		}
+		if me.Message == "" {
+			body["message"] = err.Error()
+		}
+		if me.UserTitle != "" {
+			body["user_title"] = me.UserTitle
+		}
+		if me.UserMsg != "" {
+			body["user_msg"] = me.UserMsg
+		}
+		if me.Retryable() {
+			retryAfter := me.RetryAfter
+			if retryAfter <= 0 {
+				retryAfter = defaultMetaRetryAfter
+			}
+			w.Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+		}
+		return status, body
+	}
+
+	return status, map[string]any{"error": err.Error()}
+}
+
+const defaultMetaRetryAfter = 60 * time.Second
+
+// metaErrStatus mapeia o tipo de erro da Meta para o status HTTP e o código da resposta
+func metaErrStatus(kind meta.ErrorKind) (int, string) {
+	switch kind {
+	case meta.KindRateLimited:
+		return 429, "meta_rate_limited"
+	case meta.KindAuthExpired:
+		return 424, "meta_token_invalid" // token da conta, não credencial de quem chamou
+	case meta.KindPermissionDenied:
+		return 403, "meta_permission_denied"
+	case meta.KindInvalidParameter:
+		return 400, "meta_invalid_parameter"
+	case meta.KindNotFound:
+		return 404, "meta_object_not_found"
+	case meta.KindTransient:
+		return 503, "meta_unavailable"
+	}
+	return 502, "meta_error"
+}
+
+func retryAfterSeconds(d time.Duration) string {
+	secs := int(math.Ceil(d.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	return strconv.Itoa(secs)
+}
+
+// dryRunRequested lê ?dry_run= ou o header X-Dry-Run; ok=false para valor inválido
+func dryRunRequested(r *http.Request) (dryRun bool, ok bool) {
+	v := r.URL.Query().Get("dry_run")
+	if v == "" {
+		v = r.Header.Get("X-Dry-Run")
+	}
+	if v == "" {
+		return false, true
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, false
+	}
+	return b, true
+}
+
+// writeDryRun responde 200 quando a Meta aceitou o payload e 422 quando recusou
+func writeDryRun(w http.ResponseWriter, dr *service.DryRunResult) {
+	status := 200
+	if !dr.Valid {
+		status = 422
+	}
+	writeJSON(w, status, dr)
+}
@@ -0,0 +1,101 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"creative-service/internal/auth"
+	"creative-service/internal/meta"
+	"creative-service/internal/service"
+)
+
+func TestMetaErrorResponse(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantCode       string
+		wantRetryAfter string
+	}{
+		{name: "rate limited", err: &meta.Error{Kind: meta.KindRateLimited, Code: 17, Message: "User request limit reached"}, wantStatus: 429, wantCode: "meta_rate_limited", wantRetryAfter: "60"},
+		{name: "rate limited with retry-after", err: &meta.Error{Kind: meta.KindRateLimited, Code: 4, RetryAfter: 1500 * time.Millisecond}, wantStatus: 429, wantCode: "meta_rate_limited", wantRetryAfter: "2"},
+		{name: "token expired", err: &meta.Error{Kind: meta.KindAuthExpired, Code: 190}, wantStatus: 424, wantCode: "meta_token_invalid"},
+		{name: "permission", err: &meta.Error{Kind: meta.KindPermissionDenied, Code: 200}, wantStatus: 403, wantCode: "meta_permission_denied"},
+		{name: "invalid parameter", err: &meta.Error{Kind: meta.KindInvalidParameter, Code: 100}, wantStatus: 400, wantCode: "meta_invalid_parameter"},
+		{name: "not found", err: &meta.Error{Kind: meta.KindNotFound, Code: 100, Subcode: 33}, wantStatus: 404, wantCode: "meta_object_not_found"},
+		{name: "transient", err: &meta.Error{Kind: meta.KindTransient, Code: 2}, wantStatus: 503, wantCode: "meta_unavailable", wantRetryAfter: "60"},
+		{name: "unknown", err: &meta.Error{Kind: meta.KindUnknown, Code: 1234}, wantStatus: 502, wantCode: "meta_error"},
+		{name: "wrapped", err: fmt.Errorf("create campaign: %w", &meta.Error{Kind: meta.KindInvalidParameter, Code: 100}), wantStatus: 400, wantCode: "meta_invalid_parameter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			status, body := serviceErrResponse(w, 500, tt.err)
+
+			if status != tt.wantStatus || body["error"] != tt.wantCode {
+				t.Errorf("response = %d %v, want %d %s", status, body["error"], tt.wantStatus, tt.wantCode)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			if _, ok := body["meta"].(map[string]any); !ok {
+				t.Errorf("body without meta details: %v", body)
+			}
+		})
+	}
+}
+
+func TestMetaErrorResponseUserMessage(t *testing.T) {
+	err := &meta.Error{
+		Kind: meta.KindInvalidParameter, Code: 100, Subcode: 1885183, Message: "Invalid parameter",
+		UserTitle: "Budget too low", UserMsg: "Your budget must be at least $1.00", FbTraceID: "trace",
+	}
+	status, body := serviceErrResponse(httptest.NewRecorder(), 500, err)
+
+	if status != 400 || body["message"] != "Invalid parameter" {
+		t.Errorf("response = %d %v", status, body)
+	}
+	if body["user_title"] != "Budget too low" || body["user_msg"] != "Your budget must be at least $1.00" {
+		t.Errorf("user title/msg = %v / %v", body["user_title"], body["user_msg"])
+	}
+	details := body["meta"].(map[string]any)
+	if details["code"] != 100 || details["subcode"] != 1885183 || details["fbtrace_id"] != "trace" {
+		t.Errorf("meta details = %v", details)
+	}
+
+	// Sem message da Meta (resposta sem objeto error) vai o texto do erro
+	_, body = serviceErrResponse(httptest.NewRecorder(), 500, &meta.Error{Kind: meta.KindTransient, HTTPStatus: 503, ResponseBody: "upstream"})
+	if body["message"] != "meta http 503: upstream" {
+		t.Errorf("message = %v", body["message"])
+	}
+}
+
+func TestServiceErrResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantError  string
+	}{
+		{name: "no principal", err: fmt.Errorf("get ad account: %w", auth.ErrUnauthenticated), wantStatus: 401, wantError: "unauthenticated"},
+		{name: "client not allowed", err: fmt.Errorf("get ad account: %w", auth.ErrForbidden), wantStatus: 403, wantError: "client_not_allowed"},
+		{name: "foreign object", err: fmt.Errorf("%w: 123", service.ErrObjectNotInAdAccount), wantStatus: 403, wantError: "object_not_in_ad_account"},
+		{name: "launch failed on foreign object", err: fmt.Errorf("%w: %w", service.ErrLaunchFailed, service.ErrObjectNotInAdAccount), wantStatus: 403, wantError: "object_not_in_ad_account"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeServiceErr(rec, 500, tt.err)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if code := errorCode(t, rec); code != tt.wantError {
+				t.Errorf("error = %q, want %q", code, tt.wantError)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package httpapi
 import (
 	"net/http"
 
+	"creative-service/internal/auth"
+
 	"github.com/go-chi/chi/v5"
 )
 
@@ -27,6 +29,33 @@ type Handlers interface {
 	ListAds(http.ResponseWriter, *http.Request)
 	UpdateAd(http.ResponseWriter, *http.Request)
 	DeleteAd(http.ResponseWriter, *http.Request)
+	BulkUpdateStatus(http.ResponseWriter, *http.Request)
+	CreateLaunch(http.ResponseWriter, *http.Request)
+	ListLaunches(http.ResponseWriter, *http.Request)
+	GetLaunch(http.ResponseWriter, *http.Request)
+	UndoLaunch(http.ResponseWriter, *http.Request)
+	CopyCampaign(http.ResponseWriter, *http.Request)
+	CopyAdSet(http.ResponseWriter, *http.Request)
+	CopyAd(http.ResponseWriter, *http.Request)
+	GetCopy(http.ResponseWriter, *http.Request)
+	ValidateTargeting(http.ResponseWriter, *http.Request)
+	SearchTargeting(http.ResponseWriter, *http.Request)
+	EstimateAdSet(http.ResponseWriter, *http.Request)
+	CreateScheduledAction(http.ResponseWriter, *http.Request)
+	ListScheduledActions(http.ResponseWriter, *http.Request)
+	GetScheduledAction(http.ResponseWriter, *http.Request)
+	CancelScheduledAction(http.ResponseWriter, *http.Request)
+	GetInsights(http.ResponseWriter, *http.Request)
+	CreateReport(http.ResponseWriter, *http.Request)
+	GetReport(http.ResponseWriter, *http.Request)
+	CreativeLeaderboard(http.ResponseWriter, *http.Request)
+	GetMetaUsage(http.ResponseWriter, *http.Request)
+	GetMetaStatus(http.ResponseWriter, *http.Request)
+	CreateAPIKey(http.ResponseWriter, *http.Request)
+	ListAPIKeys(http.ResponseWriter, *http.Request)
+	RevokeAPIKey(http.ResponseWriter, *http.Request)
+	Idempotent(http.Handler) http.Handler
+	Authenticate(http.Handler) http.Handler
 }
 
 func NewRouter(h Handlers) http.Handler {
@@ -34,33 +63,91 @@ func NewRouter(h Handlers) http.Handler {
 	r.Use(Recoverer, AccessLog)
 
 	r.Get("/v1/health", h.Health)
-	
-	// Clients & Ad Accounts
-	r.Get("/v1/clients", h.ListClients)
-	r.Get("/v1/clients/{client_uuid}/ad-accounts", h.ListAdAccountsByClient)
-	
-	// Creatives
-	r.Post("/v1/creatives/image", h.CreateImageCreative)
-	r.Post("/v1/creatives/video", h.CreateVideoCreative)
-	r.Get("/v1/creatives", h.ListCreatives)
-	r.Get("/v1/creatives/{creative_id}", h.GetCreative)
-	r.Delete("/v1/creatives/{creative_id}", h.SoftDeleteCreative)
-	
-	// Campaigns, AdSets, Ads
-	r.Post("/v1/campaigns", h.CreateCampaign)
-	r.Get("/v1/campaigns", h.ListCampaigns)
-	r.Patch("/v1/campaigns/{campaign_id}", h.UpdateCampaign)
-	r.Delete("/v1/campaigns/{campaign_id}", h.DeleteCampaign)
-	
-	r.Post("/v1/adsets", h.CreateAdSet)
-	r.Get("/v1/adsets", h.ListAdSets)
-	r.Patch("/v1/adsets/{adset_id}", h.UpdateAdSet)
-	r.Delete("/v1/adsets/{adset_id}", h.DeleteAdSet)
-	
-	r.Post("/v1/ads", h.CreateAd)
-	r.Get("/v1/ads", h.ListAds)
-	r.Patch("/v1/ads/{ad_id}", h.UpdateAd)
-	r.Delete("/v1/ads/{ad_id}", h.DeleteAd)
+
+	// Demais rotas exigem API key; o scope mínimo vai por rota
+	r.Group(func(r chi.Router) {
+		r.Use(h.Authenticate)
+
+		read := r.With(RequireScope(auth.ScopeRead))
+		write := r.With(RequireScope(auth.ScopeWrite))
+		admin := r.With(RequireScope(auth.ScopeAdmin), RejectDryRun)
+
+		// POSTs de criação aceitam Idempotency-Key
+		readIdem := read.With(h.Idempotent)
+		writeIdem := write.With(h.Idempotent)
+
+		// Escritas sem dry run recusam ?dry_run/X-Dry-Run antes de pegar a chave de idempotência
+		noDryRun := write.With(RejectDryRun)
+		noDryRunIdem := noDryRun.With(h.Idempotent)
+
+		// Clients & Ad Accounts
+		read.Get("/v1/clients", h.ListClients)
+		read.Get("/v1/clients/{client_uuid}/ad-accounts", h.ListAdAccountsByClient)
+
+		// Creatives
+		noDryRunIdem.Post("/v1/creatives/image", h.CreateImageCreative)
+		noDryRunIdem.Post("/v1/creatives/video", h.CreateVideoCreative)
+		read.Get("/v1/creatives", h.ListCreatives)
+		read.Get("/v1/creatives/leaderboard", h.CreativeLeaderboard)
+		read.Get("/v1/creatives/{creative_id}", h.GetCreative)
+		noDryRun.Delete("/v1/creatives/{creative_id}", h.SoftDeleteCreative)
+
+		// Campaigns, AdSets, Ads
+		writeIdem.Post("/v1/campaigns", h.CreateCampaign)
+		read.Get("/v1/campaigns", h.ListCampaigns)
+		write.Patch("/v1/campaigns/{campaign_id}", h.UpdateCampaign)
+		write.Delete("/v1/campaigns/{campaign_id}", h.DeleteCampaign)
+		noDryRunIdem.Post("/v1/campaigns/{campaign_id}/copy", h.CopyCampaign)
+
+		writeIdem.Post("/v1/adsets", h.CreateAdSet)
+		read.Get("/v1/adsets", h.ListAdSets)
+		read.Post("/v1/adsets/estimate", h.EstimateAdSet)
+		write.Patch("/v1/adsets/{adset_id}", h.UpdateAdSet)
+		write.Delete("/v1/adsets/{adset_id}", h.DeleteAdSet)
+		noDryRunIdem.Post("/v1/adsets/{adset_id}/copy", h.CopyAdSet)
+
+		writeIdem.Post("/v1/ads", h.CreateAd)
+		read.Get("/v1/ads", h.ListAds)
+		write.Patch("/v1/ads/{ad_id}", h.UpdateAd)
+		write.Delete("/v1/ads/{ad_id}", h.DeleteAd)
+		noDryRunIdem.Post("/v1/ads/{ad_id}/copy", h.CopyAd)
+
+		// Cópias assíncronas
+		read.Get("/v1/copies/{request_set_id}", h.GetCopy)
+
+		// Bulk
+		writeIdem.Post("/v1/bulk/status", h.BulkUpdateStatus)
+
+		// Launches (hierarquia completa em uma chamada)
+		writeIdem.Post("/v1/launches", h.CreateLaunch)
+		read.Get("/v1/launches", h.ListLaunches)
+		read.Get("/v1/launches/{launch_id}", h.GetLaunch)
+		noDryRun.Delete("/v1/launches/{launch_id}", h.UndoLaunch)
+
+		// Targeting
+		read.Post("/v1/targeting/validate", h.ValidateTargeting)
+		read.Get("/v1/targeting/search", h.SearchTargeting)
+
+		// Ações agendadas (executadas pelo worker)
+		noDryRunIdem.Post("/v1/scheduled-actions", h.CreateScheduledAction)
+		read.Get("/v1/scheduled-actions", h.ListScheduledActions)
+		read.Get("/v1/scheduled-actions/{action_id}", h.GetScheduledAction)
+		noDryRun.Post("/v1/scheduled-actions/{action_id}/cancel", h.CancelScheduledAction)
+
+		// Insights (reports só leem dados: scope read)
+		read.Get("/v1/insights", h.GetInsights)
+		readIdem.Post("/v1/reports", h.CreateReport)
+		read.Get("/v1/reports/{report_id}", h.GetReport)
+
+		// Meta API (uso, circuitos e scheduler de todas as contas)
+		admin.Get("/v1/meta/usage", h.GetMetaUsage)
+		admin.Get("/v1/meta/status", h.GetMetaStatus)
+
+		// API keys
+		admin.Post("/v1/api-keys", h.CreateAPIKey)
+		admin.Get("/v1/api-keys", h.ListAPIKeys)
+		admin.Delete("/v1/api-keys/{key_id}", h.RevokeAPIKey)
+	})
 
 	return r
 }
@@ -0,0 +1,215 @@
+package meta
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ======= CIRCUIT BREAKER =======
+// Um circuito por ad account + família de endpoint. Chamadas seguidas que
+// falham com a Meta fora do ar (rede, 5xx, transientes) abrem o circuito e as
+// chamadas seguintes falham na hora com CircuitOpenError, sem passar pelos
+// retries. Throttling fica com o UsageTracker. Depois de OpenFor o circuito
+// fica half-open: uma única chamada de teste passa; sucesso fecha, falha
+// reabre e uma chamada sem resultado (cancelada) libera a vaga do teste.
+
+const (
+	defaultBreakerFailures = 5
+	defaultBreakerOpenFor  = 30 * time.Second
+	breakerProbeTimeout    = 2 * time.Minute // probe que nunca reportou libera outra
+)
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// DefaultBreakers é compartilhado por todos os Clients do processo
+var DefaultBreakers = NewBreakerSet()
+
+var ErrCircuitOpen = errors.New("meta circuit open")
+
+// CircuitOpenError indica que a chamada não foi feita porque o circuito da
+// ad account/família está aberto
+type CircuitOpenError struct {
+	AdAccountID string
+	Family      string
+	RetryAfter  time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("meta circuit open for %s %s: retry after %s", e.AdAccountID, e.Family, e.RetryAfter.Round(time.Second))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+type BreakerStatus struct {
+	AdAccountID string       `json:"ad_account_id"`
+	Family      string       `json:"family"`
+	State       BreakerState `json:"state"`
+	Failures    int          `json:"failures"`
+	LastError   string       `json:"last_error,omitempty"`
+	OpenedAt    *time.Time   `json:"opened_at,omitempty"`
+	RetryAt     *time.Time   `json:"retry_at,omitempty"`
+}
+
+type BreakerSet struct {
+	Failures int           // falhas seguidas que abrem o circuito (default 5)
+	OpenFor  time.Duration // tempo aberto antes do half-open (default 30s)
+
+	mu       sync.Mutex
+	breakers map[string]*breaker // ad account + família
+}
+
+type breaker struct {
+	adAccountID string
+	family      string
+	state       BreakerState
+	failures    int
+	lastError   string
+	openedAt    time.Time
+	probing     bool      // chamada de teste em andamento (half-open)
+	probeAt     time.Time // início da chamada de teste
+}
+
+func NewBreakerSet() *BreakerSet {
+	return &BreakerSet{breakers: map[string]*breaker{}}
+}
+
+func (s *BreakerSet) failures() int {
+	if s.Failures > 0 {
+		return s.Failures
+	}
+	return defaultBreakerFailures
+}
+
+func (s *BreakerSet) openFor() time.Duration {
+	if s.OpenFor > 0 {
+		return s.OpenFor
+	}
+	return defaultBreakerOpenFor
+}
+
+// Allow decide se a chamada pode seguir. Só o circuito fechado ou a chamada de
+// teste do half-open passam.
+func (s *BreakerSet) Allow(adAccountID, family string, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.breakers[adAccountID+"|"+family]
+	if b == nil || b.state == BreakerClosed {
+		return nil
+	}
+
+	if b.state == BreakerOpen {
+		retryAt := b.openedAt.Add(s.openFor())
+		if now.Before(retryAt) {
+			return &CircuitOpenError{AdAccountID: adAccountID, Family: family, RetryAfter: retryAt.Sub(now)}
+		}
+		b.state = BreakerHalfOpen
+		b.probing, b.probeAt = true, now
+		return nil
+	}
+
+	// half-open: uma chamada de teste por vez
+	if !b.probing || now.Sub(b.probeAt) > breakerProbeTimeout {
+		b.probing, b.probeAt = true, now
+		return nil
+	}
+	return &CircuitOpenError{AdAccountID: adAccountID, Family: family, RetryAfter: time.Second}
+}
+
+// Success fecha o circuito (e esquece a conta/família)
+func (s *BreakerSet) Success(adAccountID, family string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.breakers, adAccountID+"|"+family)
+}
+
+// Release encerra uma chamada sem resultado (cancelada ou segurada antes de
+// chegar à Meta): em half-open libera a vaga do teste sem mudar o estado
+func (s *BreakerSet) Release(adAccountID, family string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b := s.breakers[adAccountID+"|"+family]; b != nil && b.state == BreakerHalfOpen {
+		b.probing = false
+	}
+}
+
+// Failure conta uma falha da Meta; em half-open reabre na hora
+func (s *BreakerSet) Failure(adAccountID, family string, err error, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := adAccountID + "|" + family
+	b := s.breakers[key]
+	if b == nil {
+		b = &breaker{adAccountID: adAccountID, family: family, state: BreakerClosed}
+		s.breakers[key] = b
+	}
+	b.failures++
+	if err != nil {
+		b.lastError = err.Error()
+	}
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= s.failures()) {
+		b.state = BreakerOpen
+		b.openedAt = now
+		b.probing = false
+	}
+}
+
+// Snapshot devolve os circuitos com falhas recentes (fechados sem falha não aparecem)
+func (s *BreakerSet) Snapshot() []BreakerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]BreakerStatus, 0, len(s.breakers))
+	for _, b := range s.breakers {
+		st := BreakerStatus{
+			AdAccountID: b.adAccountID,
+			Family:      b.family,
+			State:       b.state,
+			Failures:    b.failures,
+			LastError:   b.lastError,
+		}
+		if b.state != BreakerClosed {
+			openedAt, retryAt := b.openedAt, b.openedAt.Add(s.openFor())
+			st.OpenedAt, st.RetryAt = &openedAt, &retryAt
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].AdAccountID != out[j].AdAccountID {
+			return out[i].AdAccountID < out[j].AdAccountID
+		}
+		return out[i].Family < out[j].Family
+	})
+	return out
+}
+
+// endpointFamily agrupa os endpoints pelo tipo de carga: uma falha em insights
+// não deve derrubar criação de campanhas da mesma conta
+func endpointFamily(req *http.Request) string {
+	path := strings.Trim(req.URL.Path, "/")
+	switch path[strings.LastIndex(path, "/")+1:] {
+	case "insights":
+		return "insights"
+	case "adimages", "advideos":
+		return "media"
+	case "adcreatives":
+		return "creatives"
+	case "search", "delivery_estimate", "reachestimate", "targetingsentencelines":
+		return "targeting"
+	}
+	return "objects"
+}
@@ -0,0 +1,258 @@
+package meta_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/meta/metatest"
+)
+
+func TestBreakerCountsFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		fault    metatest.GraphError
+		wantOpen bool
+	}{
+		{"transient opens", metatest.ErrTransient, true},
+		{"rate limited does not open", metatest.ErrRateLimited, false},
+		{"permission denied does not open", metatest.ErrPermission, false},
+		{"invalid parameter does not open", metatest.ErrInvalidParameter, false},
+		{"not found does not open", metatest.GraphError{Code: 100, Subcode: 33, Message: "Object does not exist"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := metatest.NewServer()
+			defer srv.Close()
+			id := srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "c"})
+			srv.Inject(metatest.Fault{Method: "GET", Error: tt.fault})
+
+			mc := srv.Client("token")
+			mc.MaxRetries = 0
+			mc.Breakers.Failures = 2
+			ctx := meta.WithAdAccount(context.Background(), "act_1")
+
+			for i := 0; i < 2; i++ {
+				if _, err := mc.GetObject(ctx, id, nil); err == nil {
+					t.Fatalf("call %d: want error", i)
+				}
+			}
+			_, err := mc.GetObject(ctx, id, nil)
+
+			if got := errors.Is(err, meta.ErrCircuitOpen); got != tt.wantOpen {
+				t.Fatalf("circuit open = %v, want %v (err %v)", got, tt.wantOpen, err)
+			}
+			wantRequests := 3
+			if tt.wantOpen {
+				wantRequests = 2
+			}
+			if got := srv.Count("GET", id); got != wantRequests {
+				t.Errorf("requests = %d, want %d", got, wantRequests)
+			}
+		})
+	}
+}
+
+func TestBreakerCountsOncePerCall(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	id := srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "c"})
+	srv.Inject(metatest.Fault{Method: "GET", Status: 503})
+
+	mc := srv.Client("token")
+	mc.MaxRetries = 2
+	mc.Breakers.Failures = 2
+	ctx := meta.WithAdAccount(context.Background(), "act_1")
+
+	// Três tentativas, uma falha: o circuito continua fechado
+	if _, err := mc.GetObject(ctx, id, nil); errors.Is(err, meta.ErrCircuitOpen) || err == nil {
+		t.Fatalf("first call: err = %v, want the Meta error", err)
+	}
+	if st := mc.Breakers.Snapshot(); len(st) != 1 || st[0].Failures != 1 || st[0].State != meta.BreakerClosed {
+		t.Fatalf("breakers after one call = %+v, want one failure, closed", st)
+	}
+
+	if _, err := mc.GetObject(ctx, id, nil); errors.Is(err, meta.ErrCircuitOpen) {
+		t.Fatalf("second call: circuit open before it failed")
+	}
+	if _, err := mc.GetObject(ctx, id, nil); !errors.Is(err, meta.ErrCircuitOpen) {
+		t.Fatalf("third call: err = %v, want ErrCircuitOpen", err)
+	}
+	if got := srv.Count("GET", id); got != 6 {
+		t.Errorf("requests = %d, want 6", got)
+	}
+}
+
+func TestBreakerProbe(t *testing.T) {
+	tests := []struct {
+		name      string
+		fault     metatest.Fault
+		cancel    bool
+		wantState meta.BreakerState // "" = circuito esquecido (fechado)
+	}{
+		{name: "probe with retries closes", fault: metatest.Fault{Method: "GET", Times: 1, Status: 503}},
+		{name: "failed probe reopens", fault: metatest.Fault{Method: "GET", Status: 503}, wantState: meta.BreakerOpen},
+		{name: "canceled probe frees the slot", fault: metatest.Fault{Method: "GET", Status: 503}, cancel: true, wantState: meta.BreakerHalfOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := metatest.NewServer()
+			defer srv.Close()
+			id := srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "c"})
+
+			mc := srv.Client("token")
+			mc.MaxRetries = 1
+			mc.Breakers.Failures, mc.Breakers.OpenFor = 1, time.Millisecond
+			mc.Breakers.Failure("act_1", "objects", errors.New("meta down"), time.Now())
+			time.Sleep(5 * time.Millisecond)
+
+			srv.Inject(tt.fault)
+			ctx := meta.WithAdAccount(context.Background(), "act_1")
+			if tt.cancel {
+				canceled, cancel := context.WithCancel(ctx)
+				cancel()
+				ctx = canceled
+			}
+			_, err := mc.GetObject(ctx, id, nil)
+			if errors.Is(err, meta.ErrCircuitOpen) {
+				t.Fatalf("probe rejected by its own breaker: %v", err)
+			}
+
+			st := mc.Breakers.Snapshot()
+			switch {
+			case tt.wantState == "" && len(st) != 0:
+				t.Errorf("breakers = %+v, want closed", st)
+			case tt.wantState != "" && (len(st) != 1 || st[0].State != tt.wantState):
+				t.Errorf("breakers = %+v, want %s", st, tt.wantState)
+			}
+
+			// Depois de um probe cancelado, a próxima chamada vira o teste
+			if tt.cancel {
+				if err := mc.Breakers.Allow("act_1", "objects", time.Now()); err != nil {
+					t.Errorf("next probe: err = %v, want nil", err)
+				}
+			}
+		})
+	}
+}
+
+func TestBreakerIsPerAccountAndFamily(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	srv.Inject(metatest.Fault{Method: "GET", Path: "insights", Error: metatest.ErrTransient})
+	srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "c"})
+
+	mc := srv.Client("token")
+	mc.MaxRetries = 0
+	mc.Breakers.Failures = 1
+	ctx := meta.WithAdAccount(context.Background(), "act_1")
+
+	if _, err := mc.GetInsights(ctx, "act_1", meta.InsightsParams{}); err == nil {
+		t.Fatal("insights: want error")
+	}
+	if _, err := mc.GetInsights(ctx, "act_1", meta.InsightsParams{}); !errors.Is(err, meta.ErrCircuitOpen) {
+		t.Fatalf("insights: err = %v, want ErrCircuitOpen", err)
+	}
+	if _, err := mc.ListCampaigns(ctx, "act_1", []string{"name"}); err != nil {
+		t.Errorf("objects family of the same account: err = %v", err)
+	}
+	if _, err := mc.GetInsights(meta.WithAdAccount(context.Background(), "act_2"), "act_2", meta.InsightsParams{}); errors.Is(err, meta.ErrCircuitOpen) {
+		t.Error("insights of another account: circuit open")
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	failure := errors.New("meta down")
+
+	tests := []struct {
+		name  string
+		steps func(b *meta.BreakerSet) error // devolve o Allow final
+		want  error
+	}{
+		{
+			name: "open rejects until OpenFor",
+			steps: func(b *meta.BreakerSet) error {
+				b.Failure("act_1", "objects", failure, t0)
+				return b.Allow("act_1", "objects", t0.Add(30*time.Second))
+			},
+			want: meta.ErrCircuitOpen,
+		},
+		{
+			name: "half-open lets a single probe through",
+			steps: func(b *meta.BreakerSet) error {
+				b.Failure("act_1", "objects", failure, t0)
+				if err := b.Allow("act_1", "objects", t0.Add(time.Minute)); err != nil {
+					return err
+				}
+				return b.Allow("act_1", "objects", t0.Add(time.Minute))
+			},
+			want: meta.ErrCircuitOpen,
+		},
+		{
+			name: "probe success closes",
+			steps: func(b *meta.BreakerSet) error {
+				b.Failure("act_1", "objects", failure, t0)
+				_ = b.Allow("act_1", "objects", t0.Add(time.Minute))
+				b.Success("act_1", "objects")
+				return b.Allow("act_1", "objects", t0.Add(time.Minute))
+			},
+			want: nil,
+		},
+		{
+			name: "probe failure reopens",
+			steps: func(b *meta.BreakerSet) error {
+				b.Failure("act_1", "objects", failure, t0)
+				_ = b.Allow("act_1", "objects", t0.Add(time.Minute))
+				b.Failure("act_1", "objects", failure, t0.Add(time.Minute))
+				return b.Allow("act_1", "objects", t0.Add(time.Minute+time.Second))
+			},
+			want: meta.ErrCircuitOpen,
+		},
+		{
+			name: "released probe lets the next one through",
+			steps: func(b *meta.BreakerSet) error {
+				b.Failure("act_1", "objects", failure, t0)
+				_ = b.Allow("act_1", "objects", t0.Add(time.Minute))
+				b.Release("act_1", "objects")
+				return b.Allow("act_1", "objects", t0.Add(time.Minute))
+			},
+			want: nil,
+		},
+		{
+			name: "release outside half-open keeps the circuit open",
+			steps: func(b *meta.BreakerSet) error {
+				b.Failure("act_1", "objects", failure, t0)
+				b.Release("act_1", "objects")
+				return b.Allow("act_1", "objects", t0.Add(time.Second))
+			},
+			want: meta.ErrCircuitOpen,
+		},
+		{
+			name: "other family stays closed",
+			steps: func(b *meta.BreakerSet) error {
+				b.Failure("act_1", "objects", failure, t0)
+				return b.Allow("act_1", "insights", t0)
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := meta.NewBreakerSet()
+			b.Failures, b.OpenFor = 1, 45*time.Second
+			err := tt.steps(b)
+			if tt.want == nil && err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
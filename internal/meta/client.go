@@ -10,6 +10,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -20,6 +21,8 @@ type Client struct {
 	Token      string
 	HTTP       *http.Client
 	MaxRetries int
+	Usage      *UsageTracker // headers de rate limit; nil desliga o throttling
+	Breakers   *BreakerSet   // circuit breaker por ad account/família; nil desliga
 }
 
 func New(baseURL, apiVersion, token string, timeout time.Duration) *Client {
@@ -28,7 +31,9 @@ func New(baseURL, apiVersion, token string, timeout time.Duration) *Client {
 		APIVersion: apiVersion,
 		Token:      token,
 		HTTP:       &http.Client{Timeout: timeout},
-		MaxRetries: 5,
+		MaxRetries: defaultMaxRetries,
+		Usage:      DefaultUsageTracker,
+		Breakers:   DefaultBreakers,
 	}
 }
 
@@ -37,27 +42,6 @@ func (c *Client) endpoint(path string) string {
 	return fmt.Sprintf("%s/%s/%s", strings.TrimRight(c.BaseURL, "/"), c.APIVersion, path)
 }
 
-type apiError struct {
-	FBError struct {
-		Message      string `json:"message"`
-		Type         string `json:"type"`
-		Code         int    `json:"code"`
-		ErrorSubcode int    `json:"error_subcode"`
-		FbTraceID    string `json:"fbtrace_id"`
-	} `json:"error"`
-}
-
-func (e apiError) Error() string {
-	return fmt.Sprintf(
-		"meta api error: code=%d subcode=%d type=%s msg=%s trace=%s",
-		e.FBError.Code,
-		e.FBError.ErrorSubcode,
-		e.FBError.Type,
-		e.FBError.Message,
-		e.FBError.FbTraceID,
-	)
-}
-
 func Act(id string) string {
 	if strings.HasPrefix(id, "act_") { return id }
 	return "act_" + id
@@ -77,14 +61,8 @@ func (c *Client) doJSON(ctx context.Context, method, path string, q url.Values,
 	req.Header.Set("Authorization", "Bearer "+c.Token)
 	if body != nil { req.Header.Set("Content-Type", "application/json") }
 
-	respBody, status, err := c.doWithRetry(req)
+	respBody, err := c.doWithRetry(req)
 	if err != nil { return err }
-	if status >= 400 {
-		var ae apiError
-		_ = json.Unmarshal(respBody, &ae)
-		if ae.FBError.Code != 0 { return ae }
-		return fmt.Errorf("meta http %d: %s", status, string(respBody))
-	}
 	if out != nil {
 		if err := json.Unmarshal(respBody, out); err != nil {
 			return fmt.Errorf("unmarshal: %w body=%s", err, string(respBody))
@@ -109,14 +87,8 @@ func (c *Client) doForm(ctx context.Context, method, path string, fields map[str
 	req.Header.Set("Authorization", "Bearer "+c.Token)
 	req.Header.Set("Content-Type", w.FormDataContentType())
 
-	respBody, status, err := c.doWithRetry(req)
+	respBody, err := c.doWithRetry(req)
 	if err != nil { return err }
-	if status >= 400 {
-		var ae apiError
-		_ = json.Unmarshal(respBody, &ae)
-		if ae.FBError.Code != 0 { return ae }
-		return fmt.Errorf("meta http %d: %s", status, string(respBody))
-	}
 	if out != nil {
 		if err := json.Unmarshal(respBody, out); err != nil {
 			return fmt.Errorf("unmarshal: %w body=%s", err, string(respBody))
@@ -125,33 +97,6 @@ func (c *Client) doForm(ctx context.Context, method, path string, fields map[str
 	return nil
 }
 
-func (c *Client) doWithRetry(req *http.Request) ([]byte, int, error) {
-	var lastErr error
-	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
-		resp, err := c.HTTP.Do(req)
-		if err != nil {
-			lastErr = err
-			time.Sleep(backoff(attempt))
-			continue
-		}
-		body, _ := io.ReadAll(resp.Body)
-		_ = resp.Body.Close()
-		if resp.StatusCode == 429 || (resp.StatusCode >= 500 && resp.StatusCode <= 599) {
-			lastErr = fmt.Errorf("retryable status %d: %s", resp.StatusCode, string(body))
-			time.Sleep(backoff(attempt))
-			continue
-		}
-		return body, resp.StatusCode, nil
-	}
-	return nil, 0, fmt.Errorf("failed after retries: %w", lastErr)
-}
-
-func backoff(attempt int) time.Duration {
-	ms := 250 * (1 << attempt)
-	if ms > 8000 { ms = 8000 }
-	return time.Duration(ms) * time.Millisecond
-}
-
 type UploadVideoResponse struct{ ID string `json:"id"` }
 
 func (c *Client) UploadVideo(ctx context.Context, adAccountID, name, fileName string, mp4 []byte) (string, error) {
@@ -283,6 +228,22 @@ func (c *Client) UpdateAd(ctx context.Context, adID string, payload map[string]a
 	return c.doJSON(ctx, http.MethodPost, adID, nil, payload, nil)
 }
 
+// ======= VALIDATE ONLY (dry run) =======
+// execution_options=["validate_only"] faz a Meta rodar as validações do endpoint
+// (creates em act_{id}/{edge} e updates em /{id}) sem criar nem alterar nada.
+// Vai na query para o payload continuar idêntico ao da chamada real.
+
+func (c *Client) ValidateOnly(ctx context.Context, path string, payload map[string]any) error {
+	q := url.Values{}
+	q.Set("execution_options", `["validate_only"]`)
+	var out struct{ Success bool `json:"success"` }
+	if err := c.doJSON(ctx, http.MethodPost, path, q, payload, &out); err != nil {
+		return err
+	}
+	if !out.Success { return errors.New("validate only: meta did not confirm success") }
+	return nil
+}
+
 // ======= DELETE methods (soft delete - reversível) =======
 // Usa status=DELETED para marcar como deletado sem perder dados
 // Meta API permite reverter mudando status de volta para PAUSED
@@ -314,3 +275,468 @@ func (c *Client) HardDeleteAdSet(ctx context.Context, adsetID string) error {
 func (c *Client) HardDeleteAd(ctx context.Context, adID string) error {
 	return c.doJSON(ctx, http.MethodDelete, adID, nil, nil, nil)
 }
+
+// ======= GET genérico =======
+
+func (c *Client) GetObject(ctx context.Context, objectID string, fields []string) (map[string]any, error) {
+	q := url.Values{}
+	if len(fields) > 0 { q.Set("fields", strings.Join(fields, ",")) }
+	var out map[string]any
+	if err := c.doJSON(ctx, http.MethodGet, objectID, q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// maxIDsPerRequest é o limite da Meta para ?ids= numa única chamada
+const maxIDsPerRequest = 50
+
+// GetObjects busca vários objetos por ID (?ids=), em lotes de 50.
+// Objetos deletados continuam acessíveis por ID, ao contrário das listagens.
+func (c *Client) GetObjects(ctx context.Context, ids []string, fields []string) (map[string]map[string]any, error) {
+	out := make(map[string]map[string]any, len(ids))
+	for start := 0; start < len(ids); start += maxIDsPerRequest {
+		end := start + maxIDsPerRequest
+		if end > len(ids) { end = len(ids) }
+
+		q := url.Values{}
+		q.Set("ids", strings.Join(ids[start:end], ","))
+		if len(fields) > 0 { q.Set("fields", strings.Join(fields, ",")) }
+
+		var page map[string]map[string]any
+		if err := c.doJSON(ctx, http.MethodGet, "", q, nil, &page); err != nil {
+			return nil, err
+		}
+		for id, obj := range page {
+			out[id] = obj
+		}
+	}
+	return out, nil
+}
+
+// campaignChildrenMaxPages limita a paginação de adsets/ads de uma campanha
+const campaignChildrenMaxPages = 100
+
+// ListCampaignAdSets e ListCampaignAds seguem todas as páginas: uma cópia
+// entre contas que lesse só a primeira sairia truncada
+func (c *Client) ListCampaignAdSets(ctx context.Context, campaignID string, fields []string) ([]map[string]any, error) {
+	q := url.Values{}
+	if len(fields) > 0 { q.Set("fields", strings.Join(fields, ",")) }
+	return c.listAll(ctx, fmt.Sprintf("%s/adsets", campaignID), q, campaignChildrenMaxPages, nil)
+}
+
+func (c *Client) ListCampaignAds(ctx context.Context, campaignID string, fields []string) ([]map[string]any, error) {
+	q := url.Values{}
+	if len(fields) > 0 { q.Set("fields", strings.Join(fields, ",")) }
+	return c.listAll(ctx, fmt.Sprintf("%s/ads", campaignID), q, campaignChildrenMaxPages, nil)
+}
+
+// ======= COPY methods (edge /copies) =======
+// payload aceita deep_copy, status_option, rename_options e o novo pai
+// (campaign_id para adsets, adset_id para ads)
+
+type CopiedObject struct {
+	AdObjectType string `json:"ad_object_type"`
+	SourceID     string `json:"source_id"`
+	CopiedID     string `json:"copied_id"`
+}
+
+type CopyResponse struct {
+	CopiedCampaignID string         `json:"copied_campaign_id,omitempty"`
+	CopiedAdSetID    string         `json:"copied_adset_id,omitempty"`
+	CopiedAdID       string         `json:"copied_ad_id,omitempty"`
+	AdObjectIDs      []CopiedObject `json:"ad_object_ids,omitempty"`
+}
+
+func (c *Client) CopyCampaign(ctx context.Context, campaignID string, payload map[string]any) (CopyResponse, error) {
+	var out CopyResponse
+	if err := c.doJSON(ctx, http.MethodPost, fmt.Sprintf("%s/copies", campaignID), nil, payload, &out); err != nil {
+		return CopyResponse{}, err
+	}
+	if out.CopiedCampaignID == "" { return CopyResponse{}, errors.New("copy campaign: empty id") }
+	return out, nil
+}
+
+func (c *Client) CopyAdSet(ctx context.Context, adsetID string, payload map[string]any) (CopyResponse, error) {
+	var out CopyResponse
+	if err := c.doJSON(ctx, http.MethodPost, fmt.Sprintf("%s/copies", adsetID), nil, payload, &out); err != nil {
+		return CopyResponse{}, err
+	}
+	if out.CopiedAdSetID == "" { return CopyResponse{}, errors.New("copy adset: empty id") }
+	return out, nil
+}
+
+func (c *Client) CopyAd(ctx context.Context, adID string, payload map[string]any) (CopyResponse, error) {
+	var out CopyResponse
+	if err := c.doJSON(ctx, http.MethodPost, fmt.Sprintf("%s/copies", adID), nil, payload, &out); err != nil {
+		return CopyResponse{}, err
+	}
+	if out.CopiedAdID == "" { return CopyResponse{}, errors.New("copy ad: empty id") }
+	return out, nil
+}
+
+// ======= ASYNC BATCH (cópias grandes) =======
+// Deep copies com muitos filhos estouram o limite síncrono da Meta;
+// nesse caso a cópia vai como request assíncrono em act_{id}/async_batch_requests
+
+type AsyncBatchRequest struct {
+	Name        string `json:"name"`
+	RelativeURL string `json:"relative_url"`
+	Body        string `json:"body"` // url-encoded
+}
+
+func (c *Client) CreateAsyncBatch(ctx context.Context, adAccountID, name string, requests []AsyncBatchRequest) (string, error) {
+	var out CreateIDResponse
+	payload := map[string]any{"name": name, "adbatch": requests}
+	if err := c.doJSON(ctx, http.MethodPost, fmt.Sprintf("%s/async_batch_requests", Act(adAccountID)), nil, payload, &out); err != nil {
+		return "", err
+	}
+	if out.ID == "" { return "", errors.New("create async batch: empty id") }
+	return out.ID, nil
+}
+
+type AsyncRequestSet struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	IsCompleted     bool   `json:"is_completed"`
+	TotalCount      int    `json:"total_count"`
+	SuccessCount    int    `json:"success_count"`
+	ErrorCount      int    `json:"error_count"`
+	InProgressCount int    `json:"in_progress_count"`
+	CanceledCount   int    `json:"canceled_count"`
+}
+
+type AsyncRequest struct {
+	ID     string         `json:"id"`
+	Status string         `json:"status"`
+	Result map[string]any `json:"result,omitempty"`
+	Input  map[string]any `json:"input,omitempty"`
+}
+
+func (c *Client) GetAsyncRequestSet(ctx context.Context, setID string) (AsyncRequestSet, error) {
+	q := url.Values{}
+	q.Set("fields", "id,name,is_completed,total_count,success_count,error_count,in_progress_count,canceled_count")
+	var out AsyncRequestSet
+	if err := c.doJSON(ctx, http.MethodGet, setID, q, nil, &out); err != nil {
+		return AsyncRequestSet{}, err
+	}
+	return out, nil
+}
+
+func (c *Client) ListAsyncRequests(ctx context.Context, setID string) ([]AsyncRequest, error) {
+	q := url.Values{}
+	q.Set("fields", "id,status,result,input")
+	var out struct {
+		Data []AsyncRequest `json:"data"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("%s/requests", setID), q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// ======= TARGETING SEARCH methods =======
+// /search da Graph API (interesses, localizações, categorias) e o resumo textual
+// de um targeting spec em act_{id}/targetingsentencelines
+
+func (c *Client) search(ctx context.Context, searchType string, q url.Values) ([]map[string]any, error) {
+	if q == nil { q = url.Values{} }
+	q.Set("type", searchType)
+	var out ListResponse
+	if err := c.doJSON(ctx, http.MethodGet, "search", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+// SearchInterests busca interesses por nome (type=adinterest)
+func (c *Client) SearchInterests(ctx context.Context, query, locale string, limit int) ([]map[string]any, error) {
+	q := url.Values{}
+	q.Set("q", query)
+	if locale != "" { q.Set("locale", locale) }
+	if limit > 0 { q.Set("limit", strconv.Itoa(limit)) }
+	return c.search(ctx, "adinterest", q)
+}
+
+// SearchGeoLocations busca países, regiões, cidades, CEPs etc. (type=adgeolocation).
+// locationTypes filtra por tipo (country, region, city, zip, geo_market...)
+func (c *Client) SearchGeoLocations(ctx context.Context, query string, locationTypes []string, countryCode, locale string, limit int) ([]map[string]any, error) {
+	q := url.Values{}
+	q.Set("q", query)
+	if len(locationTypes) > 0 {
+		b, _ := json.Marshal(locationTypes)
+		q.Set("location_types", string(b))
+	}
+	if countryCode != "" { q.Set("country_code", countryCode) }
+	if locale != "" { q.Set("locale", locale) }
+	if limit > 0 { q.Set("limit", strconv.Itoa(limit)) }
+	return c.search(ctx, "adgeolocation", q)
+}
+
+// SearchTargetingCategories lista categorias de uma classe (type=adTargetingCategory):
+// behaviors, demographics, life_events, industries, income...
+func (c *Client) SearchTargetingCategories(ctx context.Context, class, locale string, limit int) ([]map[string]any, error) {
+	q := url.Values{}
+	q.Set("class", class)
+	if locale != "" { q.Set("locale", locale) }
+	if limit > 0 { q.Set("limit", strconv.Itoa(limit)) }
+	return c.search(ctx, "adTargetingCategory", q)
+}
+
+// TargetingSentenceLines devolve a descrição legível de um targeting spec,
+// como aparece no Ads Manager
+func (c *Client) TargetingSentenceLines(ctx context.Context, adAccountID string, targetingSpec any, locale string) ([]map[string]any, error) {
+	b, err := json.Marshal(targetingSpec)
+	if err != nil { return nil, fmt.Errorf("encode targeting_spec: %w", err) }
+	q := url.Values{}
+	q.Set("targeting_spec", string(b))
+	if locale != "" { q.Set("locale", locale) }
+	var out struct {
+		Lines []map[string]any `json:"targetingsentencelines"` // [{"content": "Location:", "children": [...]}]
+	}
+	if err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("%s/targetingsentencelines", Act(adAccountID)), q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Lines, nil
+}
+
+// ======= ESTIMATE methods =======
+// Estimativas de público e resultados para um targeting antes de criar o adset
+
+type OutcomePoint struct {
+	Spend       float64 `json:"spend"` // centavos por dia
+	Reach       float64 `json:"reach"`
+	Impressions float64 `json:"impressions"`
+	Actions     float64 `json:"actions"` // resultados do optimization_goal
+}
+
+type DeliveryEstimate struct {
+	DailyOutcomesCurve    []OutcomePoint `json:"daily_outcomes_curve"`
+	EstimateDAU           int64          `json:"estimate_dau"`
+	EstimateMAULowerBound int64          `json:"estimate_mau_lower_bound"`
+	EstimateMAUUpperBound int64          `json:"estimate_mau_upper_bound"`
+	EstimateReady         bool           `json:"estimate_ready"`
+}
+
+func (c *Client) GetDeliveryEstimate(ctx context.Context, adAccountID string, targetingSpec any, optimizationGoal string, promotedObject map[string]any) (DeliveryEstimate, error) {
+	b, err := json.Marshal(targetingSpec)
+	if err != nil { return DeliveryEstimate{}, fmt.Errorf("encode targeting_spec: %w", err) }
+	q := url.Values{}
+	q.Set("targeting_spec", string(b))
+	if optimizationGoal != "" { q.Set("optimization_goal", optimizationGoal) }
+	if len(promotedObject) > 0 {
+		po, err := json.Marshal(promotedObject)
+		if err != nil { return DeliveryEstimate{}, fmt.Errorf("encode promoted_object: %w", err) }
+		q.Set("promoted_object", string(po))
+	}
+	var out struct {
+		Data []DeliveryEstimate `json:"data"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("%s/delivery_estimate", Act(adAccountID)), q, nil, &out); err != nil {
+		return DeliveryEstimate{}, err
+	}
+	if len(out.Data) == 0 { return DeliveryEstimate{}, errors.New("delivery estimate: empty response") }
+	return out.Data[0], nil
+}
+
+type ReachEstimate struct {
+	UsersLowerBound int64 `json:"users_lower_bound"`
+	UsersUpperBound int64 `json:"users_upper_bound"`
+	EstimateReady   bool  `json:"estimate_ready"`
+}
+
+func (c *Client) GetReachEstimate(ctx context.Context, adAccountID string, targetingSpec any) (ReachEstimate, error) {
+	b, err := json.Marshal(targetingSpec)
+	if err != nil { return ReachEstimate{}, fmt.Errorf("encode targeting_spec: %w", err) }
+	q := url.Values{}
+	q.Set("targeting_spec", string(b))
+	var out struct {
+		Data ReachEstimate `json:"data"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("%s/reachestimate", Act(adAccountID)), q, nil, &out); err != nil {
+		return ReachEstimate{}, err
+	}
+	return out.Data, nil
+}
+
+// ======= AD ACCOUNT =======
+
+type AdAccountInfo struct {
+	ID                     string  `json:"id"`
+	Name                   string  `json:"name"`
+	Currency               string  `json:"currency"`
+	TimezoneName           string  `json:"timezone_name"` // ex: America/Sao_Paulo
+	TimezoneOffsetHoursUTC float64 `json:"timezone_offset_hours_utc"`
+}
+
+func (c *Client) GetAdAccount(ctx context.Context, adAccountID string) (AdAccountInfo, error) {
+	q := url.Values{}
+	q.Set("fields", "id,name,currency,timezone_name,timezone_offset_hours_utc")
+	var out AdAccountInfo
+	if err := c.doJSON(ctx, http.MethodGet, Act(adAccountID), q, nil, &out); err != nil {
+		return AdAccountInfo{}, err
+	}
+	return out, nil
+}
+
+// ======= INSIGHTS methods =======
+// {object_id}/insights para conta (act_), campanha, adset ou ad.
+// A Meta devolve métricas numéricas como string ("12.34"); as linhas vão como vêm.
+
+// Limite de páginas por consulta síncrona; períodos maiores devem usar report async
+const insightsMaxPages = 100
+
+type TimeRange struct {
+	Since string `json:"since"` // YYYY-MM-DD
+	Until string `json:"until"`
+}
+
+type InsightsParams struct {
+	Level                    string // account, campaign, adset ou ad
+	Fields                   []string
+	DatePreset               string     // ex: last_7d (exclusivo com TimeRange)
+	TimeRange                *TimeRange
+	TimeIncrement            string     // "1" (diário), "monthly", "all_days"
+	Breakdowns               []string
+	ActionAttributionWindows []string   // ex: 7d_click, 1d_view
+	Filtering                []map[string]any
+	Limit                    int        // linhas por página
+}
+
+func (p InsightsParams) values() (url.Values, error) {
+	q := url.Values{}
+	if p.Level != "" { q.Set("level", p.Level) }
+	if len(p.Fields) > 0 { q.Set("fields", strings.Join(p.Fields, ",")) }
+	if p.DatePreset != "" { q.Set("date_preset", p.DatePreset) }
+	if p.TimeRange != nil {
+		b, err := json.Marshal(p.TimeRange)
+		if err != nil { return nil, err }
+		q.Set("time_range", string(b))
+	}
+	if p.TimeIncrement != "" { q.Set("time_increment", p.TimeIncrement) }
+	if len(p.Breakdowns) > 0 { q.Set("breakdowns", strings.Join(p.Breakdowns, ",")) }
+	if len(p.ActionAttributionWindows) > 0 {
+		b, err := json.Marshal(p.ActionAttributionWindows)
+		if err != nil { return nil, err }
+		q.Set("action_attribution_windows", string(b))
+	}
+	if len(p.Filtering) > 0 {
+		b, err := json.Marshal(p.Filtering)
+		if err != nil { return nil, err }
+		q.Set("filtering", string(b))
+	}
+	if p.Limit > 0 { q.Set("limit", strconv.Itoa(p.Limit)) }
+	return q, nil
+}
+
+// GetInsights busca insights de forma síncrona seguindo a paginação (cursor after)
+func (c *Client) GetInsights(ctx context.Context, objectID string, p InsightsParams) ([]map[string]any, error) {
+	q, err := p.values()
+	if err != nil { return nil, fmt.Errorf("encode insights params: %w", err) }
+	return c.listAll(ctx, fmt.Sprintf("%s/insights", objectID), q, insightsMaxPages, nil)
+}
+
+// GetInsightsPage busca uma página de insights (p.Limit linhas) a partir do
+// cursor after; next vem vazio na última página
+func (c *Client) GetInsightsPage(ctx context.Context, objectID string, p InsightsParams, after string) (rows []map[string]any, next string, err error) {
+	q, err := p.values()
+	if err != nil { return nil, "", fmt.Errorf("encode insights params: %w", err) }
+	if after != "" { q.Set("after", after) }
+	var out struct {
+		Data   []map[string]any `json:"data"`
+		Paging struct {
+			Cursors struct {
+				After string `json:"after"`
+			} `json:"cursors"`
+			Next string `json:"next"`
+		} `json:"paging"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, fmt.Sprintf("%s/insights", objectID), q, nil, &out); err != nil {
+		return nil, "", err
+	}
+	if out.Paging.Next == "" {
+		return out.Data, "", nil
+	}
+	return out.Data, out.Paging.Cursors.After, nil
+}
+
+// listAll percorre as páginas de um edge pelo cursor after. onPage (opcional)
+// recebe o total de linhas lido até ali; um erro dele interrompe a paginação.
+func (c *Client) listAll(ctx context.Context, path string, q url.Values, maxPages int, onPage func(rows int) error) ([]map[string]any, error) {
+	var rows []map[string]any
+	for page := 0; page < maxPages; page++ {
+		var out struct {
+			Data   []map[string]any `json:"data"`
+			Paging struct {
+				Cursors struct {
+					After string `json:"after"`
+				} `json:"cursors"`
+				Next string `json:"next"`
+			} `json:"paging"`
+		}
+		if err := c.doJSON(ctx, http.MethodGet, path, q, nil, &out); err != nil {
+			return nil, err
+		}
+		rows = append(rows, out.Data...)
+		if onPage != nil {
+			if err := onPage(len(rows)); err != nil {
+				return nil, err
+			}
+		}
+		if out.Paging.Next == "" || out.Paging.Cursors.After == "" {
+			return rows, nil
+		}
+		q.Set("after", out.Paging.Cursors.After)
+	}
+	return nil, fmt.Errorf("list %s: more than %d pages", path, maxPages)
+}
+
+// ======= ASYNC INSIGHTS (report runs) =======
+// Para períodos longos ou contas grandes: POST em {object_id}/insights devolve um
+// report_run_id, que é consultado até async_status = "Job Completed" e então
+// paginado em {report_run_id}/insights
+
+const asyncReportMaxPages = 10000
+
+const (
+	AsyncJobCompleted = "Job Completed"
+	AsyncJobFailed    = "Job Failed"
+	AsyncJobSkipped   = "Job Skipped"
+)
+
+type AsyncReport struct {
+	ID                     string `json:"id"`
+	AsyncStatus            string `json:"async_status"` // Job Not Started, Job Started, Job Running, Job Completed, Job Failed, Job Skipped
+	AsyncPercentCompletion int    `json:"async_percent_completion"`
+}
+
+func (c *Client) StartAsyncInsights(ctx context.Context, objectID string, p InsightsParams) (string, error) {
+	q, err := p.values()
+	if err != nil { return "", fmt.Errorf("encode insights params: %w", err) }
+	var out struct {
+		ReportRunID string `json:"report_run_id"`
+	}
+	if err := c.doJSON(ctx, http.MethodPost, fmt.Sprintf("%s/insights", objectID), q, nil, &out); err != nil {
+		return "", err
+	}
+	if out.ReportRunID == "" { return "", errors.New("start async insights: empty report_run_id") }
+	return out.ReportRunID, nil
+}
+
+func (c *Client) GetAsyncReport(ctx context.Context, reportRunID string) (AsyncReport, error) {
+	q := url.Values{}
+	q.Set("fields", "id,async_status,async_percent_completion")
+	var out AsyncReport
+	if err := c.doJSON(ctx, http.MethodGet, reportRunID, q, nil, &out); err != nil {
+		return AsyncReport{}, err
+	}
+	return out, nil
+}
+
+// GetAsyncReportResults pagina as linhas de um report concluído. onPage é chamado
+// a cada página (ex: para renovar o lease do job durante paginações longas).
+func (c *Client) GetAsyncReportResults(ctx context.Context, reportRunID string, pageSize int, onPage func(rows int) error) ([]map[string]any, error) {
+	q := url.Values{}
+	if pageSize > 0 { q.Set("limit", strconv.Itoa(pageSize)) }
+	return c.listAll(ctx, fmt.Sprintf("%s/insights", reportRunID), q, asyncReportMaxPages, onPage)
+}
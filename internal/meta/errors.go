@@ -0,0 +1,188 @@
+package meta
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ======= ERRORS =======
+// Erros da Graph API classificados por tipo. A classificação decide se o client
+// repete a chamada e como os handlers respondem (status HTTP e código estável).
+// Referência: https://developers.facebook.com/docs/marketing-api/error-reference
+
+type ErrorKind string
+
+const (
+	KindRateLimited      ErrorKind = "rate_limited"      // throttling (4, 17, 32, 613, 80000-80014...)
+	KindAuthExpired      ErrorKind = "auth_expired"      // token inválido/expirado (190, 102)
+	KindPermissionDenied ErrorKind = "permission_denied" // sem permissão no objeto (10, 200-299)
+	KindInvalidParameter ErrorKind = "invalid_parameter" // payload rejeitado (100 etc.)
+	KindNotFound         ErrorKind = "not_found"         // objeto não existe ou não é acessível
+	KindTransient        ErrorKind = "transient"         // falha temporária da Meta (1, 2, is_transient, 5xx)
+	KindUnknown          ErrorKind = "unknown"
+)
+
+// Sentinelas para errors.Is(err, meta.ErrRateLimited) etc.
+var (
+	ErrRateLimited      = errors.New("meta rate limited")
+	ErrAuthExpired      = errors.New("meta token expired or invalid")
+	ErrPermissionDenied = errors.New("meta permission denied")
+	ErrInvalidParameter = errors.New("meta invalid parameter")
+	ErrNotFound         = errors.New("meta object not found")
+	ErrTransient        = errors.New("meta transient error")
+)
+
+var kindSentinels = map[ErrorKind]error{
+	KindRateLimited:      ErrRateLimited,
+	KindAuthExpired:      ErrAuthExpired,
+	KindPermissionDenied: ErrPermissionDenied,
+	KindInvalidParameter: ErrInvalidParameter,
+	KindNotFound:         ErrNotFound,
+	KindTransient:        ErrTransient,
+}
+
+// Error é um erro devolvido pela Graph API
+type Error struct {
+	Kind       ErrorKind
+	HTTPStatus int
+
+	Code         int
+	Subcode      int
+	Type         string
+	Message      string
+	UserTitle    string // error_user_title: texto pronto para o usuário final
+	UserMsg      string // error_user_msg
+	FbTraceID    string
+	IsTransient  bool
+	RetryAfter   time.Duration // header Retry-After, quando presente
+	ResponseBody string        // só quando a resposta não tinha o objeto error
+}
+
+func (e *Error) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("meta http %d: %s", e.HTTPStatus, e.ResponseBody)
+	}
+	return fmt.Sprintf(
+		"meta api error: code=%d subcode=%d type=%s msg=%s trace=%s",
+		e.Code,
+		e.Subcode,
+		e.Type,
+		e.Message,
+		e.FbTraceID,
+	)
+}
+
+func (e *Error) Is(target error) bool {
+	return kindSentinels[e.Kind] == target
+}
+
+// Retryable indica falhas que podem passar sozinhas (throttling e transientes)
+func (e *Error) Retryable() bool {
+	return e.Kind == KindRateLimited || e.Kind == KindTransient
+}
+
+// Is permite errors.Is(throttledErr, meta.ErrRateLimited)
+func (e *ThrottledError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// IsPermanent indica erros da Meta que não adianta repetir (token, permissão,
+// parâmetro inválido, objeto inexistente) e creates de resultado incerto, que
+// repetidos podem duplicar objetos. Erros que não são da Meta devolvem false.
+func IsPermanent(err error) bool {
+	if errors.Is(err, ErrCreateOutcomeUnknown) {
+		return true
+	}
+	var me *Error
+	if !errors.As(err, &me) {
+		return false
+	}
+	switch me.Kind {
+	case KindAuthExpired, KindPermissionDenied, KindInvalidParameter, KindNotFound:
+		return true
+	}
+	return false
+}
+
+// IsAsyncCopyRequired indica que a Meta recusou um /copies síncrono porque a
+// cópia tem objetos demais e precisa ir por async_batch_requests. A Meta responde
+// com code 100 e uma mensagem pedindo a chamada assíncrona; não há subcode estável.
+func IsAsyncCopyRequired(err error) bool {
+	var me *Error
+	if !errors.As(err, &me) || me.Code != 100 {
+		return false
+	}
+	return strings.Contains(strings.ToLower(me.Message), "async")
+}
+
+// parseError monta o *Error de uma resposta >= 400
+func parseError(status int, header http.Header, body []byte) *Error {
+	var raw struct {
+		Error struct {
+			Message      string `json:"message"`
+			Type         string `json:"type"`
+			Code         int    `json:"code"`
+			ErrorSubcode int    `json:"error_subcode"`
+			UserTitle    string `json:"error_user_title"`
+			UserMsg      string `json:"error_user_msg"`
+			IsTransient  bool   `json:"is_transient"`
+			FbTraceID    string `json:"fbtrace_id"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &raw)
+
+	e := &Error{
+		HTTPStatus:  status,
+		Code:        raw.Error.Code,
+		Subcode:     raw.Error.ErrorSubcode,
+		Type:        raw.Error.Type,
+		Message:     raw.Error.Message,
+		UserTitle:   raw.Error.UserTitle,
+		UserMsg:     raw.Error.UserMsg,
+		FbTraceID:   raw.Error.FbTraceID,
+		IsTransient: raw.Error.IsTransient,
+	}
+	if e.Code == 0 {
+		e.ResponseBody = string(body)
+	}
+	if v := header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			e.RetryAfter = time.Duration(secs) * time.Second
+		}
+	}
+	e.Kind = classify(e)
+	return e
+}
+
+func classify(e *Error) ErrorKind {
+	switch {
+	case e.Code == 4, e.Code == 17, e.Code == 32, e.Code == 341, e.Code == 613,
+		e.Code >= 80000 && e.Code <= 80014,
+		e.Subcode == 1487742, e.Subcode == 2446079:
+		return KindRateLimited
+	case e.Code == 190, e.Code == 102:
+		return KindAuthExpired
+	case e.Code == 10, e.Code == 3, e.Code >= 200 && e.Code <= 299:
+		return KindPermissionDenied
+	case e.Code == 100 && e.Subcode == 33, e.Code == 803:
+		return KindNotFound
+	case e.IsTransient, e.Code == 1, e.Code == 2:
+		return KindTransient
+	case e.Code == 100, e.Code == 2635:
+		return KindInvalidParameter
+	case e.HTTPStatus == http.StatusTooManyRequests:
+		return KindRateLimited
+	case e.HTTPStatus >= 500:
+		return KindTransient
+	case e.HTTPStatus == http.StatusNotFound:
+		return KindNotFound
+	case e.HTTPStatus == http.StatusBadRequest && e.Code != 0:
+		return KindInvalidParameter
+	}
+	return KindUnknown
+}
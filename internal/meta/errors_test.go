@@ -0,0 +1,105 @@
+package meta_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/meta/metatest"
+)
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name      string
+		fault     metatest.Fault
+		wantKind  meta.ErrorKind
+		sentinel  error
+		permanent bool
+	}{
+		{"rate limited", metatest.Fault{Error: metatest.ErrRateLimited}, meta.KindRateLimited, meta.ErrRateLimited, false},
+		{"ad account throttled", metatest.Fault{Error: metatest.ErrAccountThrottled}, meta.KindRateLimited, meta.ErrRateLimited, false},
+		{"token expired", metatest.Fault{Error: metatest.ErrTokenExpired}, meta.KindAuthExpired, meta.ErrAuthExpired, true},
+		{"permission", metatest.Fault{Error: metatest.ErrPermission}, meta.KindPermissionDenied, meta.ErrPermissionDenied, true},
+		{"invalid parameter", metatest.Fault{Error: metatest.ErrInvalidParameter}, meta.KindInvalidParameter, meta.ErrInvalidParameter, true},
+		{"unknown error flagged transient", metatest.Fault{Error: metatest.ErrUnknown}, meta.KindTransient, meta.ErrTransient, false},
+		{"object missing", metatest.Fault{Error: metatest.GraphError{Code: 100, Subcode: 33, Type: "GraphMethodException", Message: "Object does not exist"}}, meta.KindNotFound, meta.ErrNotFound, true},
+		{"transient", metatest.Fault{Error: metatest.ErrTransient}, meta.KindTransient, meta.ErrTransient, false},
+		{"http 429 without body", metatest.Fault{Status: 429}, meta.KindRateLimited, meta.ErrRateLimited, false},
+		{"http 503 without body", metatest.Fault{Status: 503}, meta.KindTransient, meta.ErrTransient, false},
+		{"http 404 without body", metatest.Fault{Status: 404}, meta.KindNotFound, meta.ErrNotFound, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := metatest.NewServer()
+			defer srv.Close()
+			id := srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "c"})
+			srv.Inject(tt.fault)
+
+			mc := srv.Client("token")
+			mc.MaxRetries = 0
+			_, err := mc.GetObject(context.Background(), id, []string{"name"})
+
+			var me *meta.Error
+			if !errors.As(err, &me) {
+				t.Fatalf("err = %v, want *meta.Error", err)
+			}
+			if me.Kind != tt.wantKind {
+				t.Errorf("kind = %s, want %s", me.Kind, tt.wantKind)
+			}
+			if !errors.Is(err, tt.sentinel) {
+				t.Errorf("errors.Is(err, %v) = false", tt.sentinel)
+			}
+			if got := meta.IsPermanent(err); got != tt.permanent {
+				t.Errorf("IsPermanent = %v, want %v", got, tt.permanent)
+			}
+		})
+	}
+}
+
+func TestErrorUserMessage(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	srv.Inject(metatest.Fault{Method: "POST", Path: "campaigns", Error: metatest.GraphError{
+		Code: 100, Subcode: 1885183, Type: "OAuthException", Message: "Invalid parameter",
+		UserTitle: "Budget too low", UserMsg: "Your budget must be at least $1.00",
+	}})
+
+	mc := srv.Client("token")
+	_, err := mc.CreateCampaign(context.Background(), "act_1", map[string]any{"name": "c"})
+
+	var me *meta.Error
+	if !errors.As(err, &me) {
+		t.Fatalf("err = %v, want *meta.Error", err)
+	}
+	if me.Kind != meta.KindInvalidParameter || me.Subcode != 1885183 || me.FbTraceID != "metatest" {
+		t.Errorf("error = %+v", me)
+	}
+	if me.UserTitle != "Budget too low" || me.UserMsg != "Your budget must be at least $1.00" {
+		t.Errorf("user title/msg = %q / %q", me.UserTitle, me.UserMsg)
+	}
+	if n := srv.Count("POST", "act_1/campaigns"); n != 1 {
+		t.Errorf("POST campaigns = %d, want 1 (invalid parameter is not retried)", n)
+	}
+}
+
+func TestIsAsyncCopyRequired(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"async batch requested", &meta.Error{Kind: meta.KindInvalidParameter, Code: 100, Message: "Please use async_batch_requests for copies with many objects"}, true},
+		{"other invalid parameter", &meta.Error{Kind: meta.KindInvalidParameter, Code: 100, Message: "Invalid parameter"}, false},
+		{"other code", &meta.Error{Kind: meta.KindTransient, Code: 2, Message: "async failure"}, false},
+		{"not a meta error", errors.New("async"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := meta.IsAsyncCopyRequired(tt.err); got != tt.want {
+				t.Errorf("IsAsyncCopyRequired = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package meta
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ======= CLIENT FACTORY =======
+// Todas as chamadas à Graph API compartilham um único http.Transport (pool de
+// conexões TLS para graph.facebook.com) e cada token tem um *Client em cache,
+// indexado pelo hash do token para não manter o segredo como chave do mapa.
+
+const (
+	defaultMaxRetries = 5
+	maxCachedClients  = 1000 // tokens rotacionados acabam saindo quando o cache enche
+)
+
+type ClientFactory struct {
+	BaseURL    string
+	APIVersion string
+	HTTP       *http.Client
+	Usage      *UsageTracker
+	Breakers   *BreakerSet
+
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+func NewClientFactory(baseURL, apiVersion string, timeout time.Duration) *ClientFactory {
+	return &ClientFactory{
+		BaseURL:    baseURL,
+		APIVersion: apiVersion,
+		HTTP:       &http.Client{Timeout: timeout, Transport: NewTransport()},
+		Usage:      DefaultUsageTracker,
+		Breakers:   DefaultBreakers,
+		clients:    map[string]*Client{},
+	}
+}
+
+// NewTransport devolve um transport ajustado para muitas chamadas ao mesmo host
+func NewTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = 256
+	t.MaxIdleConnsPerHost = 64 // o default (2) fecha conexões a cada pico
+	t.IdleConnTimeout = 90 * time.Second
+	t.TLSHandshakeTimeout = 10 * time.Second
+	t.ExpectContinueTimeout = time.Second
+	t.ForceAttemptHTTP2 = true
+	return t
+}
+
+// Client devolve o client do token. O *Client é compartilhado entre goroutines:
+// quem chama não deve alterar os campos dele.
+func (f *ClientFactory) Client(token string) *Client {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if c, ok := f.clients[key]; ok {
+		return c
+	}
+	if f.clients == nil || len(f.clients) >= maxCachedClients {
+		f.clients = map[string]*Client{}
+	}
+	c := &Client{
+		BaseURL:    f.BaseURL,
+		APIVersion: f.APIVersion,
+		Token:      token,
+		HTTP:       f.HTTP,
+		MaxRetries: defaultMaxRetries,
+		Usage:      f.Usage,
+		Breakers:   f.Breakers,
+	}
+	f.clients[key] = c
+	return c
+}
@@ -0,0 +1,75 @@
+package meta
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClientFactoryCache(t *testing.T) {
+	f := NewClientFactory("https://graph.example", "v24.0", time.Second)
+
+	a := f.Client("token-a")
+	if f.Client("token-a") != a {
+		t.Error("same token returned a new client")
+	}
+	b := f.Client("token-b")
+	if b == a {
+		t.Fatal("different tokens share a client")
+	}
+
+	// Transport, uso e breakers são do processo, não do token
+	if a.HTTP != f.HTTP || b.HTTP != f.HTTP || a.Usage != f.Usage || a.Breakers != f.Breakers {
+		t.Error("clients do not share the factory http client, usage tracker and breakers")
+	}
+	if a.Token != "token-a" || a.BaseURL != "https://graph.example" || a.APIVersion != "v24.0" || a.MaxRetries != defaultMaxRetries {
+		t.Errorf("client = %+v", a)
+	}
+	for key := range f.clients {
+		if key == "token-a" || key == "token-b" {
+			t.Error("cache keyed by the raw token")
+		}
+	}
+}
+
+func TestClientFactoryCacheLimit(t *testing.T) {
+	f := &ClientFactory{} // zero value também funciona
+	first := f.Client("token-0")
+	for i := 1; i < maxCachedClients; i++ {
+		f.Client(fmt.Sprintf("token-%d", i))
+	}
+	if len(f.clients) != maxCachedClients || f.Client("token-0") != first {
+		t.Fatalf("cache lost clients before reaching the limit (%d)", len(f.clients))
+	}
+
+	// Cache cheio é descartado: o próximo token novo começa um cache vazio
+	f.Client("token-new")
+	if len(f.clients) != 1 {
+		t.Errorf("cache size after the limit = %d, want 1", len(f.clients))
+	}
+	if f.Client("token-0") == first {
+		t.Error("client survived the cache reset")
+	}
+}
+
+func TestClientFactoryConcurrent(t *testing.T) {
+	f := NewClientFactory("https://graph.example", "v24.0", time.Second)
+
+	var wg sync.WaitGroup
+	clients := make([]*Client, 32)
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clients[i] = f.Client("token")
+		}()
+	}
+	wg.Wait()
+
+	for _, c := range clients {
+		if c != clients[0] {
+			t.Fatal("concurrent calls for the same token returned different clients")
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package meta_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/meta/metatest"
+)
+
+func TestInsightsQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		params meta.InsightsParams
+		want   map[string]string // "" = parâmetro ausente
+	}{
+		{
+			name:   "preset",
+			params: meta.InsightsParams{Level: "ad", Fields: []string{"spend", "impressions"}, DatePreset: "last_7d"},
+			want:   map[string]string{"level": "ad", "fields": "spend,impressions", "date_preset": "last_7d", "time_range": ""},
+		},
+		{
+			name: "time range",
+			params: meta.InsightsParams{
+				Level: "campaign", TimeRange: &meta.TimeRange{Since: "2024-01-01", Until: "2024-01-31"}, TimeIncrement: "1",
+				Breakdowns: []string{"age", "gender"}, ActionAttributionWindows: []string{"7d_click", "1d_view"},
+			},
+			want: map[string]string{
+				"date_preset":                "",
+				"time_range":                 `{"since":"2024-01-01","until":"2024-01-31"}`,
+				"time_increment":             "1",
+				"breakdowns":                 "age,gender",
+				"action_attribution_windows": `["7d_click","1d_view"]`,
+			},
+		},
+		{
+			name:   "filtering",
+			params: meta.InsightsParams{Filtering: []map[string]any{{"field": "spend", "operator": "GREATER_THAN", "value": 0}}},
+			want:   map[string]string{"filtering": `[{"field":"spend","operator":"GREATER_THAN","value":0}]`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := metatest.NewServer()
+			defer srv.Close()
+
+			if _, err := srv.Client("token").GetInsights(context.Background(), "act_1", tt.params); err != nil {
+				t.Fatal(err)
+			}
+			reqs := srv.Requests()
+			if len(reqs) != 1 {
+				t.Fatalf("requests = %d, want 1", len(reqs))
+			}
+			for k, want := range tt.want {
+				if got := reqs[0].Query.Get(k); got != want {
+					t.Errorf("%s = %q, want %q", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestInsightsPaging(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	rows := make([]map[string]any, 7)
+	for i := range rows {
+		rows[i] = map[string]any{"ad_id": fmt.Sprint(i)}
+	}
+	srv.SetInsights("act_1", rows)
+	mc := srv.Client("token")
+	ctx := context.Background()
+
+	// GetInsights segue todas as páginas
+	all, err := mc.GetInsights(ctx, "act_1", meta.InsightsParams{Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 7 || srv.Count("GET", "act_1/insights") != 3 {
+		t.Fatalf("rows = %d in %d requests, want 7 in 3", len(all), srv.Count("GET", "act_1/insights"))
+	}
+
+	// GetInsightsPage devolve uma página e o cursor da próxima; vazio na última
+	var got []string
+	after := ""
+	for page := 0; ; page++ {
+		if page > 3 {
+			t.Fatal("cursor never ends")
+		}
+		data, next, err := mc.GetInsightsPage(ctx, "act_1", meta.InsightsParams{Limit: 3}, after)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range data {
+			got = append(got, fmt.Sprint(r["ad_id"]))
+		}
+		if next == "" {
+			break
+		}
+		after = next
+	}
+	if fmt.Sprint(got) != "[0 1 2 3 4 5 6]" {
+		t.Errorf("rows = %v", got)
+	}
+}
+
+func TestAsyncReportResultsPaging(t *testing.T) {
+	stop := errors.New("stop")
+	tests := []struct {
+		name         string
+		pageSize     int
+		stopAfter    int // onPage devolve erro a partir desta página (0 = nunca)
+		wantPages    []int
+		wantRows     int
+		wantRequests int
+	}{
+		{name: "all pages", pageSize: 3, wantPages: []int{3, 6, 7}, wantRows: 7, wantRequests: 3},
+		{name: "single page", pageSize: 10, wantPages: []int{7}, wantRows: 7, wantRequests: 1},
+		{name: "onPage error stops paging", pageSize: 3, stopAfter: 1, wantPages: []int{3}, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := metatest.NewServer()
+			defer srv.Close()
+			rows := make([]map[string]any, 7)
+			for i := range rows {
+				rows[i] = map[string]any{"ad_id": fmt.Sprint(i)}
+			}
+			// As linhas de um report run ficam em {report_run_id}/insights
+			srv.SetInsights("900", rows)
+
+			var pages []int
+			got, err := srv.Client("token").GetAsyncReportResults(context.Background(), "900", tt.pageSize, func(n int) error {
+				pages = append(pages, n)
+				if tt.stopAfter > 0 && len(pages) >= tt.stopAfter {
+					return stop
+				}
+				return nil
+			})
+			if tt.stopAfter > 0 {
+				if !errors.Is(err, stop) {
+					t.Fatalf("err = %v, want onPage error", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.wantRows || fmt.Sprint(pages) != fmt.Sprint(tt.wantPages) {
+				t.Errorf("rows = %d pages = %v, want %d %v", len(got), pages, tt.wantRows, tt.wantPages)
+			}
+			if n := srv.Count("GET", "900/insights"); n != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package metatest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ======= ERROR INJECTION =======
+// Faults casam por método e path e substituem a resposta normal. Com Commit a
+// requisição é executada antes do erro (ex.: create que a Meta processou mas
+// cuja resposta se perdeu), para testar retries de operações não idempotentes.
+
+// GraphError é o objeto error devolvido pela Graph API
+type GraphError struct {
+	Code        int
+	Subcode     int
+	Type        string
+	Message     string
+	UserTitle   string
+	UserMsg     string
+	IsTransient bool
+}
+
+func (e GraphError) body() map[string]any {
+	out := map[string]any{
+		"message":    e.Message,
+		"type":       e.Type,
+		"code":       e.Code,
+		"fbtrace_id": "metatest",
+	}
+	if e.Subcode != 0 {
+		out["error_subcode"] = e.Subcode
+	}
+	if e.UserTitle != "" {
+		out["error_user_title"] = e.UserTitle
+	}
+	if e.UserMsg != "" {
+		out["error_user_msg"] = e.UserMsg
+	}
+	if e.IsTransient {
+		out["is_transient"] = true
+	}
+	return out
+}
+
+// Erros comuns da Meta, prontos para Fault.Error
+var (
+	ErrTransient        = GraphError{Code: 2, Type: "OAuthException", Message: "An unexpected error has occurred. Please retry your request later.", IsTransient: true}
+	ErrUnknown          = GraphError{Code: 1, Type: "OAuthException", Message: "An unknown error occurred", IsTransient: true}
+	ErrRateLimited      = GraphError{Code: 17, Subcode: 2446079, Type: "OAuthException", Message: "User request limit reached"}
+	ErrAccountThrottled = GraphError{Code: 80004, Subcode: 2446079, Type: "OAuthException", Message: "There have been too many calls to this ad-account. Wait a bit and try again."}
+	ErrTokenExpired     = GraphError{Code: 190, Subcode: 463, Type: "OAuthException", Message: "Error validating access token: Session has expired."}
+	ErrPermission       = GraphError{Code: 200, Type: "OAuthException", Message: "Permissions error"}
+	ErrInvalidParameter = GraphError{Code: 100, Type: "OAuthException", Message: "Invalid parameter"}
+)
+
+type Fault struct {
+	Method string // "" casa qualquer método
+	Path   string // path completo ou sufixo ("campaigns" casa act_1/campaigns); "" casa qualquer
+	Times  int    // quantas requisições são afetadas; 0 = todas
+
+	Status     int           // default 400 (500 quando Error é transiente)
+	Error      GraphError    // corpo do erro; Code 0 devolve só o status
+	RetryAfter time.Duration // header Retry-After
+	Delay      time.Duration // espera antes de responder (timeouts do client)
+	Drop       bool          // fecha a conexão sem resposta (falha de rede)
+	Commit     bool          // executa a requisição antes de devolver o erro
+
+	srv  *Server
+	hits int
+}
+
+// Hits conta quantas requisições o fault já afetou
+func (f *Fault) Hits() int {
+	f.srv.mu.Lock()
+	defer f.srv.mu.Unlock()
+	return f.hits
+}
+
+// Inject registra um fault; os mais antigos têm prioridade
+func (s *Server) Inject(f Fault) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fp := &f
+	fp.srv, fp.hits = s, 0
+	s.faults = append(s.faults, fp)
+	return fp
+}
+
+// ClearFaults remove todos os faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// matchFault devolve o primeiro fault ativo para a requisição; chamado com mu
+func (s *Server) matchFault(method, path string) *Fault {
+	for _, f := range s.faults {
+		if f.Times > 0 && f.hits >= f.Times {
+			continue
+		}
+		if f.Method != "" && f.Method != method {
+			continue
+		}
+		if !matchPath(path, f.Path) {
+			continue
+		}
+		f.hits++
+		return f
+	}
+	return nil
+}
+
+// apply escreve a resposta do fault; false quando o fault é só Delay
+func (f *Fault) apply(w http.ResponseWriter) bool {
+	if f.Delay > 0 {
+		time.Sleep(f.Delay)
+	}
+	if f.Drop {
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				_ = conn.Close()
+				return true
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+	if f.Status == 0 && f.Error.Code == 0 {
+		return false
+	}
+
+	status := f.Status
+	if status == 0 {
+		status = 400
+		if f.Error.IsTransient {
+			status = 500
+		}
+	}
+	if f.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter.Seconds())))
+	}
+	if f.Error.Code == 0 {
+		w.WriteHeader(status)
+		return true
+	}
+	writeError(w, status, f.Error)
+	return true
+}
@@ -0,0 +1,731 @@
+// Package metatest emula em memória os endpoints da Graph API usados pelo
+// serviço (adimages, advideos, adcreatives, campaigns, adsets, ads, updates,
+// deletes, insights e ?ids=), para testar services e handlers sem rede.
+//
+//	srv := metatest.NewServer()
+//	defer srv.Close()
+//	mc := srv.Client("token")
+//	srv.Inject(metatest.Fault{Method: "POST", Path: "campaigns", Times: 1, Error: metatest.ErrTransient})
+//
+// O estado fica todo no Server: objetos criados, imagens, linhas de insights
+// semeadas e o log das requisições recebidas.
+package metatest
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"creative-service/internal/meta"
+)
+
+const (
+	APIVersion      = "v24.0"
+	defaultPageSize = 25
+)
+
+// Tipos de objeto guardados pelo servidor
+const (
+	TypeCampaign = "campaign"
+	TypeAdSet    = "adset"
+	TypeAd       = "ad"
+	TypeCreative = "adcreative"
+	TypeVideo    = "video"
+
+	TypeAdAccount = "adaccount"
+)
+
+// edges de criação/listagem em act_{id}/{edge}
+var edgeTypes = map[string]string{
+	"campaigns":   TypeCampaign,
+	"adsets":      TypeAdSet,
+	"ads":         TypeAd,
+	"adcreatives": TypeCreative,
+	"advideos":    TypeVideo,
+}
+
+// Object é um objeto da Meta em memória
+type Object struct {
+	ID          string
+	Type        string
+	AdAccountID string // act_...
+	Fields      map[string]any
+	Deleted     bool
+}
+
+// Image é uma imagem enviada para act_{id}/adimages
+type Image struct {
+	AdAccountID string
+	Name        string
+	Hash        string
+	Size        int
+}
+
+// Request é uma requisição recebida, para asserções nos testes
+type Request struct {
+	Method string
+	Path   string // sem a versão: act_1/campaigns, 123, 123/insights
+	Query  url.Values
+	Body   map[string]any
+	Token  string
+}
+
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	nextID     int64
+	objects    map[string]*Object
+	order      []string // ids na ordem de criação, para listagens estáveis
+	images     map[string]*Image
+	insights   map[string][]map[string]any
+	faults     []*Fault
+	requests   []Request
+	badTokens  map[string]struct{}
+	appUsage   float64
+	accountUse map[string]accountUsage
+	now        func() time.Time
+}
+
+type accountUsage struct {
+	pct        float64
+	resetAfter time.Duration
+}
+
+// NewServer sobe o servidor; quem chama deve chamar Close
+func NewServer() *Server {
+	s := &Server{
+		nextID:     1000,
+		objects:    map[string]*Object{},
+		images:     map[string]*Image{},
+		insights:   map[string][]map[string]any{},
+		badTokens:  map[string]struct{}{},
+		accountUse: map[string]accountUsage{},
+		now:        time.Now,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Client devolve um *meta.Client apontando para o servidor, com rate limit
+// tracker e circuit breakers próprios (os defaults do processo não vazam entre testes)
+func (s *Server) Client(token string) *meta.Client {
+	c := meta.New(s.URL, APIVersion, token, 5*time.Second)
+	c.Usage = meta.NewUsageTracker()
+	c.Breakers = meta.NewBreakerSet()
+	return c
+}
+
+// ClientFactory devolve uma factory apontando para o servidor, para injetar nos services
+func (s *Server) ClientFactory() *meta.ClientFactory {
+	f := meta.NewClientFactory(s.URL, APIVersion, 5*time.Second)
+	f.Usage = meta.NewUsageTracker()
+	f.Breakers = meta.NewBreakerSet()
+	return f
+}
+
+// ======= STATE =======
+
+// AddObject cria um objeto direto no estado (sem passar pela API) e devolve o id
+func (s *Server) AddObject(objType, adAccountID string, fields map[string]any) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.create(objType, meta.Act(adAccountID), fields).ID
+}
+
+// SetAdAccount registra os campos da própria ad account (timezone_name,
+// timezone_offset_hours_utc, currency), lidos em GET act_{id}
+func (s *Server) SetAdAccount(adAccountID string, fields map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := meta.Act(adAccountID)
+	fields = cloneMap(fields)
+	fields["id"] = id
+	fields["account_id"] = strings.TrimPrefix(id, "act_")
+	s.objects[id] = &Object{ID: id, Type: TypeAdAccount, AdAccountID: id, Fields: fields}
+}
+
+// Object devolve uma cópia dos campos de um objeto
+func (s *Server) Object(id string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.objects[id]
+	if !ok {
+		return Object{}, false
+	}
+	cp := *o
+	cp.Fields = cloneMap(o.Fields)
+	return cp, true
+}
+
+// Objects lista os objetos de um tipo numa ad account (incluindo deletados)
+func (s *Server) Objects(objType, adAccountID string) []Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Object
+	for _, id := range s.order {
+		o := s.objects[id]
+		if o.Type == objType && o.AdAccountID == meta.Act(adAccountID) {
+			cp := *o
+			cp.Fields = cloneMap(o.Fields)
+			out = append(out, cp)
+		}
+	}
+	return out
+}
+
+// Images lista as imagens enviadas para uma ad account
+func (s *Server) Images(adAccountID string) []Image {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Image
+	for _, img := range s.images {
+		if img.AdAccountID == meta.Act(adAccountID) {
+			out = append(out, *img)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Hash < out[j].Hash })
+	return out
+}
+
+// SetInsights define as linhas devolvidas por {objectID}/insights (objectID pode ser act_...)
+func (s *Server) SetInsights(objectID string, rows []map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.insights[objectID] = rows
+}
+
+// Requests devolve o log das requisições recebidas
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Count conta requisições por método e path (sufixo: "campaigns" casa com act_1/campaigns)
+func (s *Server) Count(method, path string) int {
+	n := 0
+	for _, r := range s.Requests() {
+		if (method == "" || r.Method == method) && matchPath(r.Path, path) {
+			n++
+		}
+	}
+	return n
+}
+
+// InvalidateToken faz o token passar a receber erro 190
+func (s *Server) InvalidateToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.badTokens[token] = struct{}{}
+}
+
+// ======= THROTTLING =======
+
+// SetAppUsage define o % devolvido em X-App-Usage; >= 100 bloqueia todas as chamadas (código 4)
+func (s *Server) SetAppUsage(pct float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.appUsage = pct
+}
+
+// Throttle define o % de uso da ad account (X-Ad-Account-Usage). Com pct >= 100
+// as chamadas da conta recebem o erro 80004 até resetAfter passar.
+func (s *Server) Throttle(adAccountID string, pct float64, resetAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pct <= 0 {
+		delete(s.accountUse, meta.Act(adAccountID))
+		return
+	}
+	s.accountUse[meta.Act(adAccountID)] = accountUsage{pct: pct, resetAfter: resetAfter}
+}
+
+// ======= HTTP =======
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	if rest, ok := strings.CutPrefix(path, APIVersion); ok {
+		path = strings.TrimPrefix(rest, "/")
+	}
+	body, err := readBody(r)
+	if err != nil {
+		writeError(w, 400, GraphError{Code: 100, Type: "OAuthException", Message: "Invalid request body: " + err.Error()})
+		return
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get("access_token")
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: path, Query: r.URL.Query(), Body: body, Token: token})
+	fault := s.matchFault(r.Method, path)
+	account := s.requestAccount(path)
+	s.mu.Unlock()
+
+	if fault != nil && !fault.Commit {
+		if fault.apply(w) {
+			return
+		}
+	}
+
+	s.mu.Lock()
+	s.usageHeaders(w, account)
+	status, resp := s.route(r.Method, path, r.URL.Query(), body, token, account)
+	s.mu.Unlock()
+
+	if fault != nil && fault.Commit {
+		if fault.apply(w) {
+			return
+		}
+	}
+	writeJSON(w, status, resp)
+}
+
+// route executa a requisição no estado; chamado com mu
+func (s *Server) route(method, path string, q url.Values, body map[string]any, token, account string) (int, any) {
+	if token == "" {
+		return errResp(400, GraphError{Code: 2500, Type: "OAuthException", Message: "An active access token must be used to query information about the current user."})
+	}
+	if _, bad := s.badTokens[token]; bad {
+		return errResp(400, GraphError{Code: 190, Type: "OAuthException", Message: "Error validating access token: Session has expired."})
+	}
+	if s.appUsage >= 100 {
+		return errResp(400, GraphError{Code: 4, Type: "OAuthException", Message: "Application request limit reached", IsTransient: true})
+	}
+	if u, ok := s.accountUse[account]; ok && u.pct >= 100 {
+		return errResp(400, GraphError{Code: 80004, Subcode: 2446079, Type: "OAuthException", Message: "There have been too many calls to this ad-account. Wait a bit and try again."})
+	}
+
+	node, edge, _ := strings.Cut(path, "/")
+	validateOnly := strings.Contains(q.Get("execution_options"), "validate_only")
+	switch {
+	case node == "" && method == http.MethodGet:
+		return s.getByIDs(q)
+	case edge == "" && method == http.MethodGet:
+		return s.getObject(node, q)
+	case edge == "" && method == http.MethodPost:
+		return s.updateObject(node, body, validateOnly)
+	case edge == "" && method == http.MethodDelete:
+		return s.deleteObject(node)
+	case edge == "insights" && method == http.MethodGet:
+		return s.page(s.insights[node], q, path)
+	case edge == "adimages" && method == http.MethodPost:
+		return s.uploadImage(node, body)
+	case method == http.MethodPost && strings.HasPrefix(node, "act_") && edgeTypes[edge] != "":
+		return s.createObject(edgeTypes[edge], node, body, validateOnly)
+	case method == http.MethodGet && edgeTypes[edge] != "":
+		return s.listObjects(edgeTypes[edge], node, q, path)
+	}
+	return errResp(400, GraphError{Code: 100, Subcode: 33, Type: "GraphMethodException", Message: fmt.Sprintf("Unsupported %s request. Object with ID '%s' does not exist, cannot be loaded due to missing permissions, or does not support this operation", method, path)})
+}
+
+// createObject com validateOnly roda as mesmas checagens e devolve success sem criar
+func (s *Server) createObject(objType, account string, body map[string]any, validateOnly bool) (int, any) {
+	if name, _ := body["name"].(string); name == "" && objType != TypeVideo {
+		return errResp(400, invalidParam("name", "The name field is required"))
+	}
+
+	fields := cloneMap(body)
+	switch objType {
+	case TypeAdSet:
+		campaignID, _ := fields["campaign_id"].(string)
+		if c, ok := s.objects[campaignID]; !ok || c.Type != TypeCampaign || c.Deleted {
+			return errResp(400, invalidParam("campaign_id", "The campaign does not exist"))
+		}
+	case TypeAd:
+		adsetID, _ := fields["adset_id"].(string)
+		adset, ok := s.objects[adsetID]
+		if !ok || adset.Type != TypeAdSet || adset.Deleted {
+			return errResp(400, invalidParam("adset_id", "The ad set does not exist"))
+		}
+		fields["campaign_id"] = adset.Fields["campaign_id"]
+		// creative: {"creative_id": X} vira {"id": X}, como a Meta devolve na leitura
+		if cr, ok := fields["creative"].(map[string]any); ok {
+			if id, ok := cr["creative_id"]; ok {
+				fields["creative"] = map[string]any{"id": fmt.Sprint(id)}
+			}
+		}
+	case TypeVideo:
+		fields["status"] = map[string]any{"video_status": "ready"}
+		if name, ok := fields["name"]; ok {
+			fields["title"] = name
+		}
+		delete(fields, "source")
+	}
+	if _, ok := fields["status"]; !ok && objType != TypeVideo && objType != TypeCreative {
+		fields["status"] = "PAUSED"
+	}
+
+	if validateOnly {
+		return 200, map[string]any{"success": true}
+	}
+
+	o := s.create(objType, account, fields)
+	return 200, map[string]any{"id": o.ID}
+}
+
+func (s *Server) create(objType, account string, fields map[string]any) *Object {
+	s.nextID++
+	id := strconv.FormatInt(s.nextID, 10)
+	if fields == nil {
+		fields = map[string]any{}
+	}
+	fields["id"] = id
+	fields["account_id"] = strings.TrimPrefix(account, "act_")
+	if _, ok := fields["created_time"]; !ok {
+		fields["created_time"] = s.now().UTC().Format("2006-01-02T15:04:05-0700")
+	}
+	o := &Object{ID: id, Type: objType, AdAccountID: account, Fields: fields}
+	s.objects[id] = o
+	s.order = append(s.order, id)
+	return o
+}
+
+// updateObject aplica o payload; status=DELETED é soft delete e pode ser revertido
+func (s *Server) updateObject(id string, body map[string]any, validateOnly bool) (int, any) {
+	o, ok := s.objects[id]
+	if !ok || o.Deleted {
+		return errResp(400, notFound(id))
+	}
+	if validateOnly {
+		return 200, map[string]any{"success": true}
+	}
+	for k, v := range body {
+		if k == "id" || k == "access_token" {
+			continue
+		}
+		o.Fields[k] = v
+	}
+	return 200, map[string]any{"success": true}
+}
+
+// deleteObject é o hard delete (DELETE /{id}): não volta mais
+func (s *Server) deleteObject(id string) (int, any) {
+	o, ok := s.objects[id]
+	if !ok || o.Deleted {
+		return errResp(400, notFound(id))
+	}
+	o.Deleted = true
+	o.Fields["status"] = "DELETED"
+	return 200, map[string]any{"success": true}
+}
+
+func (s *Server) getObject(id string, q url.Values) (int, any) {
+	// deletados continuam acessíveis por ID
+	o, ok := s.objects[id]
+	if !ok {
+		return errResp(400, notFound(id))
+	}
+	return 200, s.project(o, q.Get("fields"))
+}
+
+// getByIDs atende ?ids=1,2,3; como na Meta, um id inexistente falha a chamada toda
+func (s *Server) getByIDs(q url.Values) (int, any) {
+	ids := strings.Split(q.Get("ids"), ",")
+	if q.Get("ids") == "" {
+		return errResp(400, GraphError{Code: 100, Type: "OAuthException", Message: "Must specify ids"})
+	}
+	out := map[string]any{}
+	for _, id := range ids {
+		o, ok := s.objects[id]
+		if !ok {
+			return errResp(400, notFound(id))
+		}
+		out[id] = s.project(o, q.Get("fields"))
+	}
+	return 200, out
+}
+
+// listObjects atende act_{id}/{edge} e os edges de um pai ({campaign}/adsets, {campaign}/ads, {adset}/ads).
+// Objetos deletados (DELETE ou status=DELETED) não aparecem, como na Meta sem
+// filtro de effective_status.
+func (s *Server) listObjects(objType, node string, q url.Values, path string) (int, any) {
+	parentKey := ""
+	if !strings.HasPrefix(node, "act_") {
+		parent, ok := s.objects[node]
+		if !ok {
+			return errResp(400, notFound(node))
+		}
+		parentKey = parent.Type + "_id"
+	}
+
+	var rows []map[string]any
+	for _, id := range s.order {
+		o := s.objects[id]
+		if o.Type != objType || o.Deleted || o.Fields["status"] == "DELETED" {
+			continue
+		}
+		if parentKey == "" && o.AdAccountID != node {
+			continue
+		}
+		if parentKey != "" && fmt.Sprint(o.Fields[parentKey]) != node {
+			continue
+		}
+		rows = append(rows, s.project(o, q.Get("fields")))
+	}
+	return s.page(rows, q, path)
+}
+
+func (s *Server) uploadImage(account string, body map[string]any) (int, any) {
+	f, ok := body["filename"].(file)
+	if !ok {
+		return errResp(400, invalidParam("filename", "No image file was uploaded"))
+	}
+	sum := md5.Sum(f.data)
+	hash := hex.EncodeToString(sum[:])
+	s.images[account+"/"+hash] = &Image{AdAccountID: account, Name: f.name, Hash: hash, Size: len(f.data)}
+	return 200, map[string]any{"images": map[string]any{
+		f.name: map[string]any{"hash": hash, "url": fmt.Sprintf("%s/images/%s", s.URL, hash)},
+	}}
+}
+
+// page pagina rows com limit/after, no formato data + paging.cursors + paging.next
+func (s *Server) page(rows []map[string]any, q url.Values, path string) (int, any) {
+	limit := defaultPageSize
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	start := 0
+	if after := q.Get("after"); after != "" {
+		b, err := base64.StdEncoding.DecodeString(after)
+		if err != nil {
+			return errResp(400, invalidParam("after", "Invalid cursor"))
+		}
+		start, _ = strconv.Atoi(string(b))
+	}
+	if start > len(rows) {
+		start = len(rows)
+	}
+	end := min(start+limit, len(rows))
+
+	data := rows[start:end]
+	if data == nil {
+		data = []map[string]any{}
+	}
+	out := map[string]any{"data": data}
+	if len(data) > 0 {
+		paging := map[string]any{"cursors": map[string]any{
+			"before": cursor(start),
+			"after":  cursor(end),
+		}}
+		if end < len(rows) {
+			next := cloneValues(q)
+			next.Set("after", cursor(end))
+			next.Set("limit", strconv.Itoa(limit))
+			paging["next"] = fmt.Sprintf("%s/%s/%s?%s", s.URL, APIVersion, path, next.Encode())
+		}
+		out["paging"] = paging
+	}
+	return 200, out
+}
+
+func cursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// project devolve id + os campos pedidos em ?fields= (sem fields, id e name).
+// Campos com sub-campos (creative{id,name}) expandem a referência guardada.
+func (s *Server) project(o *Object, fieldsParam string) map[string]any {
+	out := map[string]any{"id": o.ID}
+	fields := splitFields(fieldsParam)
+	if len(fields) == 0 {
+		fields = []string{"id", "name"}
+	}
+	for _, f := range fields {
+		name, sub, hasSub := strings.Cut(f, "{")
+		v, ok := o.Fields[name]
+		if !ok {
+			continue
+		}
+		if ref, isMap := v.(map[string]any); isMap && hasSub {
+			if target, ok := s.objects[fmt.Sprint(ref["id"])]; ok {
+				v = s.project(target, strings.TrimSuffix(sub, "}"))
+			}
+		}
+		out[name] = v
+	}
+	return out
+}
+
+// splitFields separa "a,b{c,d},e" respeitando as chaves
+func splitFields(v string) []string {
+	var out []string
+	depth, start := 0, 0
+	for i, c := range v {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				if f := strings.TrimSpace(v[start:i]); f != "" {
+					out = append(out, f)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if f := strings.TrimSpace(v[start:]); f != "" {
+		out = append(out, f)
+	}
+	return out
+}
+
+// requestAccount descobre a ad account da requisição (act_ no path ou dona do objeto); chamado com mu
+func (s *Server) requestAccount(path string) string {
+	node, _, _ := strings.Cut(path, "/")
+	if strings.HasPrefix(node, "act_") {
+		return node
+	}
+	if o, ok := s.objects[node]; ok {
+		return o.AdAccountID
+	}
+	return ""
+}
+
+// usageHeaders escreve X-App-Usage e X-Ad-Account-Usage; chamado com mu
+func (s *Server) usageHeaders(w http.ResponseWriter, account string) {
+	if s.appUsage > 0 {
+		b, _ := json.Marshal(map[string]any{"call_count": s.appUsage, "total_time": s.appUsage, "total_cputime": s.appUsage})
+		w.Header().Set("X-App-Usage", string(b))
+	}
+	if u, ok := s.accountUse[account]; ok {
+		b, _ := json.Marshal(map[string]any{
+			"acc_id_util_pct":     u.pct,
+			"reset_time_duration": int(u.resetAfter.Seconds()),
+			"ads_api_access_tier": "standard_access",
+		})
+		w.Header().Set("X-Ad-Account-Usage", string(b))
+	}
+}
+
+// ======= BODY =======
+
+type file struct {
+	name string
+	data []byte
+}
+
+// readBody lê JSON, multipart (arquivos viram file) ou form-urlencoded
+func readBody(r *http.Request) (map[string]any, error) {
+	out := map[string]any{}
+	if r.Body == nil || r.Method == http.MethodGet {
+		return out, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) == 0 {
+			return out, nil
+		}
+		if err := json.Unmarshal(b, &out); err != nil {
+			return nil, err
+		}
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(64 << 20); err != nil {
+			return nil, err
+		}
+		for k, v := range r.MultipartForm.Value {
+			out[k] = formValue(v[0])
+		}
+		for k, fhs := range r.MultipartForm.File {
+			f, err := fhs[0].Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(f)
+			_ = f.Close()
+			if err != nil {
+				return nil, err
+			}
+			out[k] = file{name: fhs[0].Filename, data: data}
+		}
+	default:
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+		for k, v := range r.PostForm {
+			out[k] = formValue(v[0])
+		}
+	}
+	return out, nil
+}
+
+// formValue decodifica valores JSON enviados como string de form (targeting, creative...)
+func formValue(v string) any {
+	if strings.HasPrefix(v, "{") || strings.HasPrefix(v, "[") {
+		var decoded any
+		if json.Unmarshal([]byte(v), &decoded) == nil {
+			return decoded
+		}
+	}
+	return v
+}
+
+// ======= RESPONSES =======
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, e GraphError) {
+	writeJSON(w, status, map[string]any{"error": e.body()})
+}
+
+func errResp(status int, e GraphError) (int, any) {
+	return status, map[string]any{"error": e.body()}
+}
+
+func notFound(id string) GraphError {
+	return GraphError{
+		Code:    100,
+		Subcode: 33,
+		Type:    "GraphMethodException",
+		Message: fmt.Sprintf("Unsupported get request. Object with ID '%s' does not exist, cannot be loaded due to missing permissions, or does not support this operation", id),
+	}
+}
+
+func invalidParam(field, msg string) GraphError {
+	return GraphError{Code: 100, Type: "OAuthException", Message: "Invalid parameter", UserTitle: field, UserMsg: msg}
+}
+
+func cloneMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func cloneValues(q url.Values) url.Values {
+	out := url.Values{}
+	for k, v := range q {
+		out[k] = append([]string(nil), v...)
+	}
+	return out
+}
+
+func matchPath(path, pattern string) bool {
+	return pattern == "" || path == pattern || strings.HasSuffix(path, "/"+pattern)
+}
@@ -0,0 +1,296 @@
+package metatest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/meta/metatest"
+)
+
+// call faz uma requisição crua ao servidor e devolve status, headers e o JSON
+// da resposta (nil quando o corpo vem vazio)
+func call(t *testing.T, srv *metatest.Server, method, path string, q url.Values, body map[string]any) (int, http.Header, map[string]any) {
+	t.Helper()
+	if q == nil {
+		q = url.Values{}
+	}
+	if q.Get("access_token") == "" {
+		q.Set("access_token", "token")
+	}
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/%s/%s?%s", srv.URL, metatest.APIVersion, path, q.Encode()), bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var out map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil && !errors.Is(err, io.EOF) {
+		t.Fatalf("decode %s %s: %v", method, path, err)
+	}
+	return resp.StatusCode, resp.Header, out
+}
+
+func graphCode(resp map[string]any) int {
+	e, _ := resp["error"].(map[string]any)
+	code, _ := e["code"].(float64)
+	return int(code)
+}
+
+func TestServerPagination(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	var campaignID string
+	for i := range 30 {
+		campaignID = srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": fmt.Sprintf("c%d", i)})
+		srv.AddObject(metatest.TypeAdSet, "act_1", map[string]any{"name": fmt.Sprintf("as%d", i), "campaign_id": campaignID})
+	}
+	srv.AddObject(metatest.TypeCampaign, "act_2", map[string]any{"name": "other account"})
+
+	var names []string
+	next := ""
+	for page := 0; ; page++ {
+		if page > 3 {
+			t.Fatal("pagination does not end")
+		}
+		q := url.Values{"fields": {"name"}}
+		if next != "" {
+			u, _ := url.Parse(next)
+			q = u.Query()
+		}
+		status, _, resp := call(t, srv, "GET", "act_1/campaigns", q, nil)
+		if status != 200 {
+			t.Fatalf("page %d: status %d %v", page, status, resp)
+		}
+		for _, row := range resp["data"].([]any) {
+			names = append(names, row.(map[string]any)["name"].(string))
+		}
+		paging, _ := resp["paging"].(map[string]any)
+		next, _ = paging["next"].(string)
+		if next == "" {
+			break
+		}
+	}
+
+	if len(names) != 30 || names[0] != "c0" || names[29] != "c29" {
+		t.Errorf("names = %v, want c0..c29 in creation order", names)
+	}
+
+	// Edge de um pai só lista os filhos dele
+	adsets, err := srv.Client("token").ListCampaignAdSets(context.Background(), campaignID, []string{"name"})
+	if err != nil || len(adsets) != 1 || adsets[0]["name"] != "as29" {
+		t.Errorf("ListCampaignAdSets = %v, %v; want as29", adsets, err)
+	}
+}
+
+func TestServerGetByIDs(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	a := srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "a", "objective": "OUTCOME_SALES"})
+	b := srv.AddObject(metatest.TypeAdSet, "act_1", map[string]any{"name": "b", "campaign_id": a})
+
+	status, _, resp := call(t, srv, "GET", "", url.Values{"ids": {a + "," + b}, "fields": {"name,objective"}}, nil)
+	if status != 200 || len(resp) != 2 {
+		t.Fatalf("ids: status %d %v", status, resp)
+	}
+	if got := resp[a].(map[string]any); got["objective"] != "OUTCOME_SALES" || got["id"] != a {
+		t.Errorf("campaign = %v", got)
+	}
+	if _, ok := resp[b].(map[string]any)["objective"]; ok {
+		t.Error("field missing from the object was returned")
+	}
+
+	// Como na Meta, um id inexistente derruba a chamada inteira
+	if status, _, resp := call(t, srv, "GET", "", url.Values{"ids": {a + ",999"}}, nil); status != 400 || graphCode(resp) != 100 {
+		t.Errorf("unknown id: status %d %v", status, resp)
+	}
+}
+
+func TestServerCreateUpdateDelete(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	mc := srv.Client("token")
+	ctx := context.Background()
+
+	campaignID, err := mc.CreateCampaign(ctx, "act_1", map[string]any{"name": "c", "objective": "OUTCOME_TRAFFIC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, _, resp := call(t, srv, "POST", "act_1/adsets", nil, map[string]any{"name": "as", "campaign_id": campaignID})
+	if status != 200 {
+		t.Fatalf("create adset: %d %v", status, resp)
+	}
+	adsetID := resp["id"].(string)
+	creativeID := srv.AddObject(metatest.TypeCreative, "act_1", map[string]any{"name": "cr"})
+	status, _, resp = call(t, srv, "POST", "act_1/ads", nil, map[string]any{"name": "ad", "adset_id": adsetID, "creative": map[string]any{"creative_id": creativeID}})
+	if status != 200 {
+		t.Fatalf("create ad: %d %v", status, resp)
+	}
+	adID := resp["id"].(string)
+
+	// O ad herda a campanha do adset e o creative expande como na leitura da Meta
+	_, _, ad := call(t, srv, "GET", adID, url.Values{"fields": {"campaign_id,status,creative{id,name}"}}, nil)
+	if ad["campaign_id"] != campaignID || ad["status"] != "PAUSED" {
+		t.Errorf("ad = %v", ad)
+	}
+	if cr, _ := ad["creative"].(map[string]any); cr["id"] != creativeID || cr["name"] != "cr" {
+		t.Errorf("ad creative = %v", ad["creative"])
+	}
+
+	// Filho de pai inexistente é recusado
+	if status, _, resp := call(t, srv, "POST", "act_1/adsets", nil, map[string]any{"name": "x", "campaign_id": "999"}); status != 400 || graphCode(resp) != 100 {
+		t.Errorf("adset without campaign: %d %v", status, resp)
+	}
+
+	if status, _, _ := call(t, srv, "POST", adsetID, nil, map[string]any{"status": "ACTIVE", "daily_budget": "1000"}); status != 200 {
+		t.Fatalf("update adset: %d", status)
+	}
+	if o, _ := srv.Object(adsetID); o.Fields["status"] != "ACTIVE" || o.Fields["daily_budget"] != "1000" {
+		t.Errorf("adset after update = %v", o.Fields)
+	}
+
+	// DELETE some das listagens mas continua acessível por ID
+	if status, _, _ := call(t, srv, "DELETE", adID, nil, nil); status != 200 {
+		t.Fatalf("delete ad: %d", status)
+	}
+	if _, _, resp := call(t, srv, "GET", adsetID+"/ads", nil, nil); len(resp["data"].([]any)) != 0 {
+		t.Errorf("deleted ad still listed: %v", resp["data"])
+	}
+	if status, _, resp := call(t, srv, "GET", adID, url.Values{"fields": {"status"}}, nil); status != 200 || resp["status"] != "DELETED" {
+		t.Errorf("deleted ad by id: %d %v", status, resp)
+	}
+	if status, _, _ := call(t, srv, "POST", adID, nil, map[string]any{"name": "again"}); status != 400 {
+		t.Errorf("update of deleted ad: status %d, want 400", status)
+	}
+}
+
+func TestServerValidateOnly(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	campaignID := srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "c", "status": "PAUSED"})
+	validateOnly := url.Values{"execution_options": {`["validate_only"]`}}
+
+	status, _, resp := call(t, srv, "POST", "act_1/campaigns", validateOnly, map[string]any{"name": "new"})
+	if status != 200 || resp["success"] != true {
+		t.Errorf("validate create: %d %v", status, resp)
+	}
+	// As mesmas checagens do create valem no validate_only
+	if status, _, _ := call(t, srv, "POST", "act_1/campaigns", validateOnly, map[string]any{}); status != 400 {
+		t.Errorf("validate create without name: status %d, want 400", status)
+	}
+	if status, _, _ := call(t, srv, "POST", "act_1/adsets", validateOnly, map[string]any{"name": "as", "campaign_id": "999"}); status != 400 {
+		t.Errorf("validate adset without campaign: status %d, want 400", status)
+	}
+	if status, _, _ := call(t, srv, "POST", campaignID, validateOnly, map[string]any{"status": "ACTIVE"}); status != 200 {
+		t.Errorf("validate update: status %d", status)
+	}
+
+	if n := len(srv.Objects(metatest.TypeCampaign, "act_1")); n != 1 {
+		t.Errorf("campaigns = %d, want 1 (validate_only does not create)", n)
+	}
+	if o, _ := srv.Object(campaignID); o.Fields["status"] != "PAUSED" {
+		t.Errorf("status = %v, want PAUSED (validate_only does not update)", o.Fields["status"])
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	id := srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "c"})
+
+	// Times limita o fault; Method e Path filtram
+	f := srv.Inject(metatest.Fault{Method: "GET", Path: id, Times: 2, Error: metatest.ErrTransient, RetryAfter: 3 * time.Second})
+	status, h, resp := call(t, srv, "GET", id, nil, nil)
+	if status != 500 || graphCode(resp) != 2 || h.Get("Retry-After") != "3" {
+		t.Errorf("transient fault: %d %v Retry-After=%q", status, resp, h.Get("Retry-After"))
+	}
+	if status, _, _ := call(t, srv, "POST", id, nil, map[string]any{"name": "x"}); status != 200 {
+		t.Errorf("POST matched a GET fault: status %d", status)
+	}
+	call(t, srv, "GET", id, nil, nil)
+	if status, _, _ := call(t, srv, "GET", id, nil, nil); status != 200 || f.Hits() != 2 {
+		t.Errorf("after Times: status %d hits %d", status, f.Hits())
+	}
+
+	// Commit executa o create e perde a resposta
+	srv.Inject(metatest.Fault{Method: "POST", Path: "campaigns", Times: 1, Status: 502, Commit: true})
+	if status, _, _ := call(t, srv, "POST", "act_1/campaigns", nil, map[string]any{"name": "committed"}); status != 502 {
+		t.Errorf("commit fault: status %d, want 502", status)
+	}
+	if n := len(srv.Objects(metatest.TypeCampaign, "act_1")); n != 2 {
+		t.Errorf("campaigns after commit fault = %d, want 2", n)
+	}
+
+	// Drop fecha a conexão: o client vê erro de rede
+	srv.Inject(metatest.Fault{Method: "GET", Path: "act_1/adsets", Drop: true})
+	mc := srv.Client("token")
+	mc.MaxRetries = 0
+	_, err := mc.ListAdSets(context.Background(), "act_1", nil)
+	var me *meta.Error
+	if err == nil || errors.As(err, &me) {
+		t.Errorf("dropped connection: err = %v, want a network error", err)
+	}
+
+	srv.ClearFaults()
+	if status, _, _ := call(t, srv, "GET", "act_1/adsets", nil, nil); status != 200 {
+		t.Errorf("after ClearFaults: status %d", status)
+	}
+	if n := srv.Count("GET", id); n != 3 {
+		t.Errorf("Count(GET, %s) = %d, want 3", id, n)
+	}
+}
+
+func TestServerTokensAndThrottling(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	id := srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "c"})
+
+	srv.Throttle("act_1", 50, 0)
+	status, h, _ := call(t, srv, "GET", id, nil, nil)
+	var usage map[string]any
+	_ = json.Unmarshal([]byte(h.Get("X-Ad-Account-Usage")), &usage)
+	if status != 200 || usage["acc_id_util_pct"] != 50.0 {
+		t.Errorf("usage below limit: %d %v", status, usage)
+	}
+
+	srv.Throttle("act_1", 100, time.Minute)
+	if status, h, resp := call(t, srv, "GET", id, nil, nil); status != 400 || graphCode(resp) != 80004 || h.Get("X-Ad-Account-Usage") == "" {
+		t.Errorf("throttled account: %d %v", status, resp)
+	}
+	if status, _, _ := call(t, srv, "GET", "act_2/campaigns", nil, nil); status != 200 {
+		t.Errorf("other account throttled: status %d", status)
+	}
+	srv.Throttle("act_1", 0, 0)
+
+	srv.SetAppUsage(100)
+	if status, h, resp := call(t, srv, "GET", id, nil, nil); status != 400 || graphCode(resp) != 4 || h.Get("X-App-Usage") == "" {
+		t.Errorf("app blocked: %d %v", status, resp)
+	}
+	srv.SetAppUsage(0)
+
+	srv.InvalidateToken("token")
+	if status, _, resp := call(t, srv, "GET", id, nil, nil); status != 400 || graphCode(resp) != 190 {
+		t.Errorf("invalid token: %d %v", status, resp)
+	}
+	if status, _, _ := call(t, srv, "GET", id, url.Values{"access_token": {"other"}}, nil); status != 200 {
+		t.Errorf("other token: status %d", status)
+	}
+}
@@ -0,0 +1,225 @@
+package meta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ======= RETRY =======
+// Cada tentativa reconstrói o body via GetBody (o reader original já foi
+// consumido), espera com backoff full-jitter respeitando Retry-After e o
+// cancelamento do contexto. POSTs que criam objetos não são idempotentes: só
+// são repetidos quando a Meta confirma que nada foi criado (throttling).
+
+const (
+	retryBaseDelay = 250 * time.Millisecond
+	retryMaxDelay  = 8 * time.Second
+	maxRetryAfter  = 60 * time.Second // Retry-After maior que isso volta para quem chamou
+)
+
+// ErrCreateOutcomeUnknown indica um create que falhou sem confirmação de que
+// nada foi criado (timeout, conexão caída, 5xx). Repetir pode duplicar o objeto.
+var ErrCreateOutcomeUnknown = errors.New("meta create outcome unknown")
+
+// Edges em que POST cria um objeto novo. adimages fica de fora: a Meta
+// deduplica imagens pelo hash, então repetir o upload é seguro.
+var createEdges = map[string]struct{}{
+	"campaigns":            {},
+	"adsets":               {},
+	"ads":                  {},
+	"adcreatives":          {},
+	"advideos":             {},
+	"copies":               {},
+	"async_batch_requests": {},
+}
+
+type callTrackerCtxKey struct{}
+
+// WithCallTracker marca ctx para CallAttempted dizer, depois, se alguma
+// requisição chegou a ser enviada à Meta (throttling local, circuito aberto e
+// erros antes da chamada não contam)
+func WithCallTracker(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callTrackerCtxKey{}, new(atomic.Bool))
+}
+
+// CallAttempted indica se alguma chamada feita com ctx (ou derivados) foi
+// enviada à Meta; sem WithCallTracker devolve false
+func CallAttempted(ctx context.Context) bool {
+	attempted, ok := ctx.Value(callTrackerCtxKey{}).(*atomic.Bool)
+	return ok && attempted.Load()
+}
+
+func markCallAttempted(ctx context.Context) {
+	if attempted, ok := ctx.Value(callTrackerCtxKey{}).(*atomic.Bool); ok {
+		attempted.Store(true)
+	}
+}
+
+// doWithRetry devolve o body de respostas < 400. Erros da Meta viram *Error;
+// só throttling e falhas transientes são repetidos. O circuit breaker é
+// consultado uma vez por chamada e recebe um único resultado dela (não um por
+// tentativa), em qualquer saída: ver breakerOutcome.
+func (c *Client) doWithRetry(req *http.Request) ([]byte, error) {
+	ctx := req.Context()
+	app, account := tokenFingerprint(c.Token), requestAdAccount(req)
+	family, create := endpointFamily(req), isCreate(req)
+
+	outcome, outcomeErr := breakerNoOutcome, error(nil)
+	if c.Breakers != nil {
+		if err := c.Breakers.Allow(account, family, time.Now()); err != nil {
+			return nil, err
+		}
+		defer func() { c.reportBreaker(account, family, outcome, outcomeErr) }()
+	}
+
+	var lastErr error
+	var wait time.Duration
+	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepCtx(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
+		if c.Usage != nil {
+			if err := c.Usage.Wait(ctx, app, account); err != nil {
+				return nil, err
+			}
+		}
+
+		r, err := rewindRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		markCallAttempted(ctx)
+		resp, err := c.HTTP.Do(r)
+		if err == nil {
+			var body []byte
+			body, err = io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if c.Usage != nil {
+				c.Usage.Observe(app, account, resp.Header, time.Now())
+			}
+			if err == nil {
+				if resp.StatusCode < 400 {
+					outcome, outcomeErr = breakerResponded, nil
+					return body, nil
+				}
+
+				me := parseError(resp.StatusCode, resp.Header, body)
+				outcome, outcomeErr = breakerResponded, nil
+				if countsAsBreakerFailure(me.Kind) {
+					outcome, outcomeErr = breakerFailed, me
+				}
+				if !me.Retryable() {
+					return nil, me
+				}
+				if create && me.Kind != KindRateLimited {
+					return nil, fmt.Errorf("%w: %w", ErrCreateOutcomeUnknown, me)
+				}
+				if me.RetryAfter > maxRetryAfter {
+					return nil, me
+				}
+				lastErr = me
+				wait = max(backoff(attempt), me.RetryAfter)
+				continue
+			}
+		}
+
+		// Falha de rede ou body cortado: não dá para saber o que a Meta processou
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		outcome, outcomeErr = breakerFailed, err
+		if create {
+			return nil, fmt.Errorf("%w: %w", ErrCreateOutcomeUnknown, err)
+		}
+		lastErr = err
+		wait = backoff(attempt)
+	}
+	return nil, fmt.Errorf("failed after retries: %w", lastErr)
+}
+
+// breakerOutcome é o resultado de uma chamada para o circuit breaker, dado
+// pela última tentativa que chegou a ser feita
+type breakerOutcome int
+
+const (
+	breakerNoOutcome breakerOutcome = iota // nenhuma tentativa terminou (cancelamento, throttling local)
+	breakerResponded                       // a Meta respondeu normalmente, mesmo que com erro
+	breakerFailed                          // rede, 5xx ou transiente
+)
+
+// reportBreaker fecha o circuito quando a Meta respondeu, conta falha quando
+// ela estava fora do ar e, sem resultado, só libera a vaga do teste do half-open
+func (c *Client) reportBreaker(account, family string, outcome breakerOutcome, err error) {
+	switch outcome {
+	case breakerResponded:
+		c.Breakers.Success(account, family)
+	case breakerFailed:
+		c.Breakers.Failure(account, family, err, time.Now())
+	default:
+		c.Breakers.Release(account, family)
+	}
+}
+
+// countsAsBreakerFailure: só falhas da Meta (transientes e 5xx sem corpo). Rate
+// limit é tratado pelo UsageTracker; permissão negada, parâmetro inválido e
+// objeto inexistente são respostas normais
+func countsAsBreakerFailure(kind ErrorKind) bool {
+	return kind == KindTransient
+}
+
+// rewindRequest devolve a requisição da tentativa; a partir da segunda o body
+// é reconstruído com GetBody
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("request body cannot be replayed: %s %s", req.Method, req.URL.Path)
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("rewind body: %w", err)
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+func isCreate(req *http.Request) bool {
+	if req.Method != http.MethodPost || strings.Contains(req.URL.Query().Get("execution_options"), "validate_only") {
+		return false
+	}
+	_, ok := createEdges[path.Base(req.URL.Path)]
+	return ok
+}
+
+// backoff full-jitter: aleatório entre 0 e min(max, base*2^attempt)
+func backoff(attempt int) time.Duration {
+	ceil := retryMaxDelay
+	if attempt < 16 {
+		ceil = min(retryBaseDelay<<attempt, retryMaxDelay)
+	}
+	return rand.N(ceil) + time.Millisecond
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
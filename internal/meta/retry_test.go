@@ -0,0 +1,162 @@
+package meta_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/meta/metatest"
+)
+
+func TestClientRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		create       bool
+		fault        metatest.Fault
+		wantKind     meta.ErrorKind // vazio: a chamada termina com sucesso
+		wantUnknown  bool           // ErrCreateOutcomeUnknown
+		wantRequests int
+	}{
+		{
+			name:         "transient read is retried",
+			fault:        metatest.Fault{Method: "GET", Times: 2, Error: metatest.ErrTransient},
+			wantRequests: 3,
+		},
+		{
+			name:         "network failure on read is retried",
+			fault:        metatest.Fault{Method: "GET", Times: 1, Drop: true},
+			wantRequests: 2,
+		},
+		{
+			name:         "invalid parameter is not retried",
+			fault:        metatest.Fault{Method: "GET", Error: metatest.ErrInvalidParameter},
+			wantKind:     meta.KindInvalidParameter,
+			wantRequests: 1,
+		},
+		{
+			name:         "expired token is not retried",
+			fault:        metatest.Fault{Method: "GET", Error: metatest.ErrTokenExpired},
+			wantKind:     meta.KindAuthExpired,
+			wantRequests: 1,
+		},
+		{
+			name:         "retry-after beyond the limit goes back to the caller",
+			fault:        metatest.Fault{Method: "GET", Error: metatest.ErrRateLimited, RetryAfter: 2 * time.Minute},
+			wantKind:     meta.KindRateLimited,
+			wantRequests: 1,
+		},
+		{
+			name:         "rate limited create is retried",
+			create:       true,
+			fault:        metatest.Fault{Method: "POST", Path: "campaigns", Times: 1, Error: metatest.ErrRateLimited},
+			wantRequests: 2,
+		},
+		{
+			name:         "transient create is not retried",
+			create:       true,
+			fault:        metatest.Fault{Method: "POST", Path: "campaigns", Times: 1, Error: metatest.ErrTransient},
+			wantKind:     meta.KindTransient,
+			wantUnknown:  true,
+			wantRequests: 1,
+		},
+		{
+			name:         "lost create response is not retried",
+			create:       true,
+			fault:        metatest.Fault{Method: "POST", Path: "campaigns", Times: 1, Status: 502, Commit: true},
+			wantKind:     meta.KindTransient,
+			wantUnknown:  true,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := metatest.NewServer()
+			defer srv.Close()
+			id := srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "c"})
+			srv.Inject(tt.fault)
+
+			mc := srv.Client("token")
+			ctx := meta.WithAdAccount(context.Background(), "act_1")
+
+			var err error
+			method, path := "GET", id
+			if tt.create {
+				method, path = "POST", "act_1/campaigns"
+				_, err = mc.CreateCampaign(ctx, "act_1", map[string]any{"name": "new", "objective": "OUTCOME_TRAFFIC"})
+			} else {
+				_, err = mc.GetObject(ctx, id, []string{"name"})
+			}
+
+			if got := srv.Count(method, path); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if tt.wantKind == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				// O retry reenvia o body inteiro (GetBody), não o reader já consumido
+				if objs := srv.Objects(metatest.TypeCampaign, "act_1"); tt.create && (len(objs) != 2 || objs[1].Fields["name"] != "new") {
+					t.Errorf("campaigns after retried create = %+v", objs)
+				}
+				return
+			}
+			var me *meta.Error
+			if !errors.As(err, &me) || me.Kind != tt.wantKind {
+				t.Fatalf("err = %v, want kind %s", err, tt.wantKind)
+			}
+			if got := errors.Is(err, meta.ErrCreateOutcomeUnknown); got != tt.wantUnknown {
+				t.Errorf("ErrCreateOutcomeUnknown = %v, want %v", got, tt.wantUnknown)
+			}
+		})
+	}
+}
+
+func TestClientRetryStopsOnCancel(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	id := srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "c"})
+	srv.Inject(metatest.Fault{Method: "GET", Error: metatest.ErrTransient})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := srv.Client("token").GetObject(ctx, id, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestCallAttempted(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	id := srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "c"})
+
+	mc := srv.Client("token")
+	mc.Breakers.Failures = 1
+	mc.MaxRetries = 0
+
+	ctx := meta.WithCallTracker(context.Background())
+	if meta.CallAttempted(ctx) {
+		t.Fatal("CallAttempted before any call")
+	}
+
+	// Primeira chamada falha e abre o circuito; a segunda nem sai do processo
+	srv.Inject(metatest.Fault{Method: "GET", Error: metatest.ErrTransient})
+	_, _ = mc.GetObject(meta.WithAdAccount(context.Background(), "act_1"), id, nil)
+
+	_, err := mc.GetObject(meta.WithAdAccount(ctx, "act_1"), id, nil)
+	if !errors.Is(err, meta.ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if meta.CallAttempted(ctx) {
+		t.Error("CallAttempted = true for a call blocked by the breaker")
+	}
+
+	srv.ClearFaults()
+	_, _ = mc.GetObject(ctx, id, nil)
+	if !meta.CallAttempted(ctx) {
+		t.Error("CallAttempted = false after a request was sent")
+	}
+}
@@ -0,0 +1,346 @@
+package meta
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ======= RATE LIMIT USAGE =======
+// A Meta devolve o consumo de rate limit em headers a cada resposta:
+//   X-App-Usage                {"call_count":28,"total_time":25,"total_cputime":25}          (% do limite do app)
+//   X-Ad-Account-Usage         {"acc_id_util_pct":9.67,"reset_time_duration":0,...}         (% da conta)
+//   X-Business-Use-Case-Usage  {"<business_id>":[{"type":"ads_management","call_count":95,
+//                               "estimated_time_to_regain_access":0,...}]}                  (por business/caso de uso)
+// O UsageTracker guarda o último valor visto e, antes de cada chamada, segura
+// a requisição quando a conta/app está perto do limite ou bloqueada.
+// O uso por business vale para todas as contas do business: cada conta que
+// recebe o header fica associada a ele. Conta que ainda não recebeu o header
+// herda o uso dos businesses vistos com o mesmo token.
+
+const (
+	defaultThrottleThreshold = 75.0             // % a partir do qual as chamadas são espaçadas
+	defaultMaxThrottleDelay  = 5 * time.Second  // espera máxima por chamada perto do limite
+	defaultMaxThrottleWait   = 30 * time.Second // bloqueios maiores que isso falham na hora
+	usageStaleAfter          = 5 * time.Minute  // uso mais antigo que isso é ignorado
+	appBlockedCooldown       = time.Minute      // app a 100% não informa quando libera
+)
+
+// DefaultUsageTracker é compartilhado por todos os Clients do processo
+var DefaultUsageTracker = NewUsageTracker()
+
+// ThrottledError indica que a chamada não foi feita porque a conta/app está
+// bloqueada pela Meta por mais tempo do que MaxWait
+type ThrottledError struct {
+	Scope      string // app, ad_account ou business_use_case
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("meta rate limit reached for %s %s: retry after %s", e.Scope, e.Key, e.RetryAfter.Round(time.Second))
+}
+
+type AppUsage struct {
+	App          string     `json:"app"` // fingerprint do token (o app não vem no header)
+	CallCount    float64    `json:"call_count"`
+	TotalCPUTime float64    `json:"total_cputime"`
+	TotalTime    float64    `json:"total_time"`
+	BlockedUntil *time.Time `json:"blocked_until,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+type AdAccountUsage struct {
+	AdAccountID       string     `json:"ad_account_id"`
+	UtilPct           float64    `json:"acc_id_util_pct"`
+	ResetTimeDuration int        `json:"reset_time_duration"` // segundos
+	AccessTier        string     `json:"ads_api_access_tier,omitempty"`
+	BlockedUntil      *time.Time `json:"blocked_until,omitempty"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+type BusinessUseCaseUsage struct {
+	BusinessID                  string     `json:"business_id"`
+	Type                        string     `json:"type"`
+	AdAccountID                 string     `json:"ad_account_id,omitempty"` // última conta que recebeu este header
+	App                         string     `json:"app,omitempty"`           // fingerprint do token da última resposta
+	CallCount                   float64    `json:"call_count"`
+	TotalCPUTime                float64    `json:"total_cputime"`
+	TotalTime                   float64    `json:"total_time"`
+	EstimatedTimeToRegainAccess int        `json:"estimated_time_to_regain_access"` // minutos
+	AccessTier                  string     `json:"ads_api_access_tier,omitempty"`
+	BlockedUntil                *time.Time `json:"blocked_until,omitempty"`
+	UpdatedAt                   time.Time  `json:"updated_at"`
+}
+
+type UsageSnapshot struct {
+	Apps             []AppUsage             `json:"apps"`
+	AdAccounts       []AdAccountUsage       `json:"ad_accounts"`
+	BusinessUseCases []BusinessUseCaseUsage `json:"business_use_cases"`
+}
+
+type UsageTracker struct {
+	Threshold float64       // % (default 75)
+	MaxDelay  time.Duration // espera máxima por chamada perto do limite (default 5s)
+	MaxWait   time.Duration // bloqueio máximo aguardado antes de devolver ThrottledError (default 30s)
+
+	mu       sync.Mutex
+	apps     map[string]*AppUsage
+	accounts map[string]*AdAccountUsage
+	buc      map[string]*BusinessUseCaseUsage // business_id + type
+
+	businesses map[string]map[string]struct{} // ad account -> business_ids vistos no header
+}
+
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{
+		apps:       map[string]*AppUsage{},
+		accounts:   map[string]*AdAccountUsage{},
+		buc:        map[string]*BusinessUseCaseUsage{},
+		businesses: map[string]map[string]struct{}{},
+	}
+}
+
+type adAccountCtxKey struct{}
+
+// WithAdAccount associa as chamadas feitas com ctx à ad account (para
+// atribuir o uso e segurar chamadas de contas perto do limite)
+func WithAdAccount(ctx context.Context, adAccountID string) context.Context {
+	return context.WithValue(ctx, adAccountCtxKey{}, Act(adAccountID))
+}
+
+// requestAdAccount usa a conta do contexto ou, na falta, o path (act_123/...)
+func requestAdAccount(req *http.Request) string {
+	if id, ok := req.Context().Value(adAccountCtxKey{}).(string); ok {
+		return id
+	}
+	for _, seg := range strings.Split(req.URL.Path, "/") {
+		if strings.HasPrefix(seg, "act_") {
+			return seg
+		}
+	}
+	return ""
+}
+
+func tokenFingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:6])
+}
+
+// Observe registra os headers de uso de uma resposta
+func (t *UsageTracker) Observe(app, adAccountID string, h http.Header, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if v := h.Get("X-App-Usage"); v != "" {
+		var u struct {
+			CallCount    float64 `json:"call_count"`
+			TotalCPUTime float64 `json:"total_cputime"`
+			TotalTime    float64 `json:"total_time"`
+		}
+		if json.Unmarshal([]byte(v), &u) == nil {
+			au := &AppUsage{App: app, CallCount: u.CallCount, TotalCPUTime: u.TotalCPUTime, TotalTime: u.TotalTime, UpdatedAt: now}
+			if maxPct(u.CallCount, u.TotalCPUTime, u.TotalTime) >= 100 {
+				until := now.Add(appBlockedCooldown)
+				au.BlockedUntil = &until
+			}
+			t.apps[app] = au
+		}
+	}
+
+	if v := h.Get("X-Ad-Account-Usage"); v != "" && adAccountID != "" {
+		var u struct {
+			UtilPct           float64 `json:"acc_id_util_pct"`
+			ResetTimeDuration int     `json:"reset_time_duration"`
+			AccessTier        string  `json:"ads_api_access_tier"`
+		}
+		if json.Unmarshal([]byte(v), &u) == nil {
+			au := &AdAccountUsage{AdAccountID: adAccountID, UtilPct: u.UtilPct, ResetTimeDuration: u.ResetTimeDuration, AccessTier: u.AccessTier, UpdatedAt: now}
+			if u.UtilPct >= 100 && u.ResetTimeDuration > 0 {
+				until := now.Add(time.Duration(u.ResetTimeDuration) * time.Second)
+				au.BlockedUntil = &until
+			}
+			t.accounts[adAccountID] = au
+		}
+	}
+
+	if v := h.Get("X-Business-Use-Case-Usage"); v != "" {
+		var u map[string][]struct {
+			Type                        string  `json:"type"`
+			CallCount                   float64 `json:"call_count"`
+			TotalCPUTime                float64 `json:"total_cputime"`
+			TotalTime                   float64 `json:"total_time"`
+			EstimatedTimeToRegainAccess int     `json:"estimated_time_to_regain_access"`
+			AccessTier                  string  `json:"ads_api_access_tier"`
+		}
+		if json.Unmarshal([]byte(v), &u) == nil {
+			for businessID, cases := range u {
+				if adAccountID != "" {
+					if t.businesses[adAccountID] == nil {
+						t.businesses[adAccountID] = map[string]struct{}{}
+					}
+					t.businesses[adAccountID][businessID] = struct{}{}
+				}
+				for _, c := range cases {
+					bu := &BusinessUseCaseUsage{
+						BusinessID: businessID, Type: c.Type, AdAccountID: adAccountID, App: app,
+						CallCount: c.CallCount, TotalCPUTime: c.TotalCPUTime, TotalTime: c.TotalTime,
+						EstimatedTimeToRegainAccess: c.EstimatedTimeToRegainAccess, AccessTier: c.AccessTier,
+						UpdatedAt: now,
+					}
+					if c.EstimatedTimeToRegainAccess > 0 {
+						until := now.Add(time.Duration(c.EstimatedTimeToRegainAccess) * time.Minute)
+						bu.BlockedUntil = &until
+					}
+					t.buc[businessID+"/"+c.Type] = bu
+				}
+			}
+		}
+	}
+}
+
+// Delay calcula quanto esperar antes de chamar a Meta. Bloqueios (estimated_time_to_regain_access,
+// reset_time_duration) esperam até liberar; perto do limite as chamadas são espaçadas
+// proporcionalmente ao uso. Bloqueios maiores que MaxWait viram ThrottledError.
+func (t *UsageTracker) Delay(app, adAccountID string, now time.Time) (time.Duration, error) {
+	threshold, maxDelay, maxWait := t.Threshold, t.MaxDelay, t.MaxWait
+	if threshold <= 0 {
+		threshold = defaultThrottleThreshold
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxThrottleDelay
+	}
+	if maxWait <= 0 {
+		maxWait = defaultMaxThrottleWait
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var blocked time.Duration
+	var scope, key string
+	pct := 0.0
+
+	block := func(s, k string, until *time.Time) {
+		if until != nil && until.After(now) && until.Sub(now) > blocked {
+			blocked, scope, key = until.Sub(now), s, k
+		}
+	}
+	fresh := func(at time.Time) bool { return now.Sub(at) < usageStaleAfter }
+
+	// Bloqueios valem até expirar; percentuais só enquanto recentes
+	if a, ok := t.apps[app]; ok {
+		block("app", app, a.BlockedUntil)
+		if fresh(a.UpdatedAt) {
+			pct = max(pct, maxPct(a.CallCount, a.TotalCPUTime, a.TotalTime))
+		}
+	}
+	if adAccountID != "" {
+		if a, ok := t.accounts[adAccountID]; ok {
+			block("ad_account", adAccountID, a.BlockedUntil)
+			if fresh(a.UpdatedAt) {
+				pct = max(pct, a.UtilPct)
+			}
+		}
+	}
+	for k, b := range t.buc {
+		if !t.bucApplies(b, app, adAccountID) {
+			continue
+		}
+		block("business_use_case", k, b.BlockedUntil)
+		if fresh(b.UpdatedAt) {
+			pct = max(pct, maxPct(b.CallCount, b.TotalCPUTime, b.TotalTime))
+		}
+	}
+
+	if blocked > 0 {
+		if blocked > maxWait {
+			return 0, &ThrottledError{Scope: scope, Key: key, RetryAfter: blocked}
+		}
+		return blocked, nil
+	}
+	if pct < threshold {
+		return 0, nil
+	}
+	frac := (pct - threshold) / (100 - threshold)
+	if frac > 1 {
+		frac = 1
+	}
+	return time.Duration(frac * float64(maxDelay)), nil
+}
+
+// bucApplies diz se o uso de um business vale para a chamada: pelo business da
+// conta quando já é conhecido, senão pelo token que recebeu o header
+func (t *UsageTracker) bucApplies(b *BusinessUseCaseUsage, app, adAccountID string) bool {
+	if known := t.businesses[adAccountID]; len(known) > 0 {
+		_, ok := known[b.BusinessID]
+		return ok
+	}
+	return b.App == app
+}
+
+// Wait aplica Delay respeitando o cancelamento do contexto
+func (t *UsageTracker) Wait(ctx context.Context, app, adAccountID string) error {
+	d, err := t.Delay(app, adAccountID, time.Now())
+	if err != nil || d <= 0 {
+		return err
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Snapshot devolve o uso conhecido (opcionalmente só de uma ad account)
+func (t *UsageTracker) Snapshot(adAccountID string) UsageSnapshot {
+	if adAccountID != "" {
+		adAccountID = Act(adAccountID)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := UsageSnapshot{Apps: []AppUsage{}, AdAccounts: []AdAccountUsage{}, BusinessUseCases: []BusinessUseCaseUsage{}}
+	if adAccountID == "" {
+		for _, a := range t.apps {
+			out.Apps = append(out.Apps, *a)
+		}
+	}
+	for id, a := range t.accounts {
+		if adAccountID == "" || id == adAccountID {
+			out.AdAccounts = append(out.AdAccounts, *a)
+		}
+	}
+	for _, b := range t.buc {
+		if _, ok := t.businesses[adAccountID][b.BusinessID]; adAccountID == "" || ok {
+			out.BusinessUseCases = append(out.BusinessUseCases, *b)
+		}
+	}
+
+	sort.Slice(out.Apps, func(i, j int) bool { return out.Apps[i].App < out.Apps[j].App })
+	sort.Slice(out.AdAccounts, func(i, j int) bool { return out.AdAccounts[i].AdAccountID < out.AdAccounts[j].AdAccountID })
+	sort.Slice(out.BusinessUseCases, func(i, j int) bool {
+		a, b := out.BusinessUseCases[i], out.BusinessUseCases[j]
+		return a.BusinessID+"/"+a.Type < b.BusinessID+"/"+b.Type
+	})
+	return out
+}
+
+func maxPct(vs ...float64) float64 {
+	m := 0.0
+	for _, v := range vs {
+		m = max(m, v)
+	}
+	return m
+}
@@ -0,0 +1,183 @@
+package meta_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"creative-service/internal/meta"
+)
+
+func usageHeader(kv ...string) http.Header {
+	h := http.Header{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		h.Set(kv[i], kv[i+1])
+	}
+	return h
+}
+
+func TestUsageTrackerObserve(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tr := meta.NewUsageTracker()
+	tr.Observe("app1", "act_1", usageHeader(
+		"X-App-Usage", `{"call_count":28,"total_time":25,"total_cputime":100}`,
+		"X-Ad-Account-Usage", `{"acc_id_util_pct":100,"reset_time_duration":120,"ads_api_access_tier":"standard_access"}`,
+		"X-Business-Use-Case-Usage", `{"999":[{"type":"ads_management","call_count":95,"total_cputime":10,"total_time":10,"estimated_time_to_regain_access":3}]}`,
+	), now)
+	// Header malformado é ignorado sem apagar o que já foi visto
+	tr.Observe("app1", "act_1", usageHeader("X-App-Usage", `{not json`), now)
+
+	snap := tr.Snapshot("")
+	if len(snap.Apps) != 1 || len(snap.AdAccounts) != 1 || len(snap.BusinessUseCases) != 1 {
+		t.Fatalf("snapshot = %+v, want one entry per scope", snap)
+	}
+
+	app := snap.Apps[0]
+	if app.CallCount != 28 || app.TotalCPUTime != 100 || app.BlockedUntil == nil || !app.BlockedUntil.Equal(now.Add(time.Minute)) {
+		t.Errorf("app usage = %+v, want blocked for a minute at 100%% cpu", app)
+	}
+
+	acc := snap.AdAccounts[0]
+	if acc.UtilPct != 100 || acc.AccessTier != "standard_access" || acc.BlockedUntil == nil || !acc.BlockedUntil.Equal(now.Add(120*time.Second)) {
+		t.Errorf("ad account usage = %+v, want blocked for reset_time_duration", acc)
+	}
+
+	buc := snap.BusinessUseCases[0]
+	if buc.BusinessID != "999" || buc.Type != "ads_management" || buc.AdAccountID != "act_1" || buc.App != "app1" {
+		t.Errorf("business use case = %+v", buc)
+	}
+	if buc.BlockedUntil == nil || !buc.BlockedUntil.Equal(now.Add(3*time.Minute)) {
+		t.Errorf("business blocked until = %v, want estimated_time_to_regain_access in minutes", buc.BlockedUntil)
+	}
+
+	// Filtro por conta aceita o id sem act_ e leva os businesses da conta
+	if snap := tr.Snapshot("1"); len(snap.Apps) != 0 || len(snap.AdAccounts) != 1 || len(snap.BusinessUseCases) != 1 {
+		t.Errorf("snapshot for act_1 = %+v", snap)
+	}
+	if snap := tr.Snapshot("act_2"); len(snap.AdAccounts) != 0 || len(snap.BusinessUseCases) != 0 {
+		t.Errorf("snapshot for act_2 = %+v, want empty", snap)
+	}
+}
+
+func TestUsageTrackerDelay(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		observe   func(tr *meta.UsageTracker)
+		app       string
+		account   string
+		want      time.Duration
+		wantScope string // ThrottledError esperado
+	}{
+		{
+			name:    "no usage",
+			observe: func(tr *meta.UsageTracker) {},
+			app:     "app1", account: "act_1",
+		},
+		{
+			name: "below threshold",
+			observe: func(tr *meta.UsageTracker) {
+				tr.Observe("app1", "act_1", usageHeader("X-Ad-Account-Usage", `{"acc_id_util_pct":50}`), now)
+			},
+			app: "app1", account: "act_1",
+		},
+		{
+			name: "spaced proportionally above threshold",
+			observe: func(tr *meta.UsageTracker) {
+				tr.Observe("app1", "act_1", usageHeader("X-Ad-Account-Usage", `{"acc_id_util_pct":87.5}`), now)
+			},
+			app: "app1", account: "act_1", want: 2500 * time.Millisecond,
+		},
+		{
+			name: "full usage without reset waits max delay",
+			observe: func(tr *meta.UsageTracker) {
+				tr.Observe("app1", "act_1", usageHeader("X-Ad-Account-Usage", `{"acc_id_util_pct":100}`), now)
+			},
+			app: "app1", account: "act_1", want: 5 * time.Second,
+		},
+		{
+			name: "stale usage is ignored",
+			observe: func(tr *meta.UsageTracker) {
+				tr.Observe("app1", "act_1", usageHeader("X-Ad-Account-Usage", `{"acc_id_util_pct":99}`), now.Add(-10*time.Minute))
+			},
+			app: "app1", account: "act_1",
+		},
+		{
+			name: "other account is not throttled",
+			observe: func(tr *meta.UsageTracker) {
+				tr.Observe("app1", "act_1", usageHeader("X-Ad-Account-Usage", `{"acc_id_util_pct":100,"reset_time_duration":10}`), now)
+			},
+			app: "app1", account: "act_2",
+		},
+		{
+			name: "short block waits until released",
+			observe: func(tr *meta.UsageTracker) {
+				tr.Observe("app1", "act_1", usageHeader("X-Ad-Account-Usage", `{"acc_id_util_pct":100,"reset_time_duration":10}`), now)
+			},
+			app: "app1", account: "act_1", want: 10 * time.Second,
+		},
+		{
+			name: "long business block fails fast",
+			observe: func(tr *meta.UsageTracker) {
+				tr.Observe("app1", "act_1", usageHeader("X-Business-Use-Case-Usage", `{"999":[{"type":"ads_management","call_count":100,"estimated_time_to_regain_access":5}]}`), now)
+			},
+			app: "app1", account: "act_1", wantScope: "business_use_case",
+		},
+		{
+			name: "business usage follows the account to other tokens",
+			observe: func(tr *meta.UsageTracker) {
+				tr.Observe("app1", "act_1", usageHeader("X-Business-Use-Case-Usage", `{"999":[{"type":"ads_management","call_count":100,"estimated_time_to_regain_access":5}]}`), now)
+			},
+			app: "app2", account: "act_1", wantScope: "business_use_case",
+		},
+		{
+			name: "unknown account inherits business usage of the token",
+			observe: func(tr *meta.UsageTracker) {
+				tr.Observe("app1", "act_1", usageHeader("X-Business-Use-Case-Usage", `{"999":[{"type":"ads_management","call_count":87.5}]}`), now)
+			},
+			app: "app1", account: "act_9", want: 2500 * time.Millisecond,
+		},
+		{
+			name: "business of another account is not applied",
+			observe: func(tr *meta.UsageTracker) {
+				tr.Observe("app1", "act_1", usageHeader("X-Business-Use-Case-Usage", `{"999":[{"type":"ads_management","call_count":100}]}`), now)
+				tr.Observe("app1", "act_2", usageHeader("X-Business-Use-Case-Usage", `{"888":[{"type":"ads_management","call_count":10}]}`), now)
+			},
+			app: "app1", account: "act_2",
+		},
+		{
+			name: "app at 100% blocks its calls",
+			observe: func(tr *meta.UsageTracker) {
+				tr.Observe("app1", "", usageHeader("X-App-Usage", `{"call_count":100}`), now)
+			},
+			app: "app1", account: "act_1", wantScope: "app",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := meta.NewUsageTracker()
+			tt.observe(tr)
+
+			got, err := tr.Delay(tt.app, tt.account, now)
+			if tt.wantScope != "" {
+				var te *meta.ThrottledError
+				if !errors.As(err, &te) || te.Scope != tt.wantScope {
+					t.Fatalf("err = %v, want ThrottledError for %s", err, tt.wantScope)
+				}
+				if te.RetryAfter <= 0 {
+					t.Errorf("retry after = %s, want > 0", te.RetryAfter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("delay = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"creative-service/internal/auth"
+	"creative-service/internal/meta"
+	"creative-service/internal/storage"
+)
+
+// ErrObjectNotInAdAccount: o objeto informado pertence a outra ad account
+var ErrObjectNotInAdAccount = errors.New("object does not belong to ad account")
+
+// getAdAccount busca a ad account e confere se a API key do request tem acesso
+// ao cliente dela (auth.ErrForbidden). Todo service que recebe ad_account_id
+// passa por aqui; o worker roda com auth.WithSystem.
+func getAdAccount(ctx context.Context, st *storage.Store, adAccountID string) (storage.AdAccount, error) {
+	adAccount, err := st.GetAdAccount(ctx, adAccountID)
+	if err != nil {
+		return storage.AdAccount{}, err
+	}
+	if err := auth.CheckClient(ctx, adAccount.ClientUUID); err != nil {
+		return storage.AdAccount{}, err
+	}
+	return adAccount, nil
+}
+
+// checkAdAccount é getAdAccount para quem só precisa da permissão (listagens no banco)
+func checkAdAccount(ctx context.Context, st *storage.Store, adAccountID string) error {
+	_, err := getAdAccount(ctx, st, adAccountID)
+	return err
+}
+
+// checkJobClient confere o cliente de um job criado pela API (reports)
+func checkJobClient(ctx context.Context, job storage.Job) error {
+	clientUUID := ""
+	if job.ClientUUID != nil {
+		clientUUID = *job.ClientUUID
+	}
+	return auth.CheckClient(ctx, clientUUID)
+}
+
+// checkObjectsInAdAccount confere na Meta (campo account_id) que os objetos
+// pertencem à ad account antes de agir sobre eles. O token de uma conta costuma
+// enxergar as outras contas do mesmo business, então só o ad_account_id não basta.
+func checkObjectsInAdAccount(ctx context.Context, mc *meta.Client, adAccountID string, ids ...string) error {
+	foreign, err := foreignObjects(ctx, mc, adAccountID, ids...)
+	if err != nil {
+		return err
+	}
+	if len(foreign) > 0 {
+		return fmt.Errorf("%w: %s", ErrObjectNotInAdAccount, foreign[0])
+	}
+	return nil
+}
+
+// foreignObjects devolve, na ordem de ids, os objetos que não são da ad account
+// (uma chamada ?ids= a cada 50), para quem reporta a falha por item (bulk)
+func foreignObjects(ctx context.Context, mc *meta.Client, adAccountID string, ids ...string) ([]string, error) {
+	want := strings.TrimPrefix(meta.Act(adAccountID), "act_")
+
+	var foreign, objectIDs []string
+	seen := map[string]struct{}{}
+	for _, id := range ids {
+		if strings.HasPrefix(id, "act_") {
+			if strings.TrimPrefix(id, "act_") != want {
+				foreign = append(foreign, id)
+			}
+			continue
+		}
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		objectIDs = append(objectIDs, id)
+	}
+	if len(objectIDs) == 0 {
+		return foreign, nil
+	}
+
+	objects, err := mc.GetObjects(ctx, objectIDs, []string{"account_id"})
+	if err != nil {
+		return nil, fmt.Errorf("get object account: %w", err)
+	}
+	for _, id := range objectIDs {
+		if stringField(objects[id], "account_id") != want {
+			foreign = append(foreign, id)
+		}
+	}
+	return foreign, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/meta/metatest"
+)
+
+func TestForeignObjects(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	own := srv.AddObject(metatest.TypeCampaign, "act_1", nil)
+	own2 := srv.AddObject(metatest.TypeAd, "act_1", nil)
+	other := srv.AddObject(metatest.TypeCampaign, "act_2", nil)
+
+	tests := []struct {
+		name      string
+		ids       []string
+		want      []string
+		wantCalls int
+	}{
+		{name: "all owned", ids: []string{own, own2}, wantCalls: 1},
+		{name: "foreign object", ids: []string{own, other}, want: []string{other}, wantCalls: 1},
+		{name: "duplicates are checked once", ids: []string{other, other}, want: []string{other}, wantCalls: 1},
+		{name: "ad account ids skip meta", ids: []string{"act_1", "act_2"}, want: []string{"act_2"}},
+		{name: "no ids"},
+	}
+
+	mc := srv.Client("token")
+	ctx := meta.WithAdAccount(context.Background(), "act_1")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(srv.Requests())
+			got, err := foreignObjects(ctx, mc, "act_1", tt.ids...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("foreign = %v, want %v", got, tt.want)
+			}
+			if calls := len(srv.Requests()) - before; calls != tt.wantCalls {
+				t.Errorf("meta calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCheckObjectsInAdAccount(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	own := srv.AddObject(metatest.TypeAdSet, "act_1", nil)
+	other := srv.AddObject(metatest.TypeAdSet, "act_2", nil)
+
+	tests := []struct {
+		name        string
+		adAccountID string
+		ids         []string
+		wantErr     error
+	}{
+		{name: "owned", adAccountID: "act_1", ids: []string{own}},
+		{name: "ad account without act_ prefix", adAccountID: "1", ids: []string{own}},
+		{name: "foreign", adAccountID: "act_1", ids: []string{own, other}, wantErr: ErrObjectNotInAdAccount},
+		{name: "missing object", adAccountID: "act_1", ids: []string{"999"}, wantErr: meta.ErrNotFound},
+	}
+
+	mc := srv.Client("token")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := meta.WithAdAccount(context.Background(), tt.adAccountID)
+			err := checkObjectsInAdAccount(ctx, mc, tt.adAccountID, tt.ids...)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
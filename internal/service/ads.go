@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"creative-service/internal/meta"
 	"creative-service/internal/secrets"
@@ -14,11 +13,9 @@ type AdService struct {
 	Store  *storage.Store
 	Tokens secrets.Resolver
 
-	BaseURL     string
-	APIVersion  string
-	HTTPTimeout time.Duration
+	Meta *meta.ClientFactory
 
-	Sem *Semaphore
+	Sched *Scheduler
 }
 
 type CreateAdInput struct {
@@ -27,36 +24,48 @@ type CreateAdInput struct {
 	CreativeID  string
 	Name        string
 	Status      string
+
+	DryRun bool // valida e devolve o payload sem criar
 }
 
 type CreateAdOutput struct {
-	AdID string `json:"ad_id"`
+	AdID   string        `json:"ad_id"`
+	DryRun *DryRunResult `json:"dry_run,omitempty"`
 }
 
 func (s *AdService) CreateAd(ctx context.Context, in CreateAdInput) (CreateAdOutput, error) {
-	if err := s.Sem.Acquire(ctx); err != nil {
-		return CreateAdOutput{}, err
-	}
-	defer s.Sem.Release()
-
 	// Buscar ad account pelo ID (act_123456789)
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
 	if err != nil {
 		return CreateAdOutput{}, fmt.Errorf("get ad account: %w", err)
 	}
+	return s.createAd(ctx, adAccount, in)
+}
+
+// createAd recebe a ad account já resolvida pelo chamador
+func (s *AdService) createAd(ctx context.Context, adAccount storage.AdAccount, in CreateAdInput) (CreateAdOutput, error) {
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
+	if err != nil {
+		return CreateAdOutput{}, err
+	}
+	defer lease.Release()
 
 	token, err := s.Tokens.Resolve(adAccount.TokenRef)
 	if err != nil {
 		return CreateAdOutput{}, fmt.Errorf("resolve token: %w", err)
 	}
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	payload := adPayload(in)
 
-	payload := map[string]any{
-		"name":       in.Name,
-		"adset_id":   in.AdSetID,
-		"creative":   map[string]any{"creative_id": in.CreativeID},
-		"status":     in.Status,
+	if in.DryRun {
+		dr, err := dryRun(ctx, mc, meta.Act(adAccount.AdAccountID)+"/ads", payload)
+		if err != nil {
+			return CreateAdOutput{}, err
+		}
+		return CreateAdOutput{DryRun: dr}, nil
 	}
 
 	adID, err := mc.CreateAd(ctx, adAccount.AdAccountID, payload)
@@ -67,6 +76,15 @@ func (s *AdService) CreateAd(ctx context.Context, in CreateAdInput) (CreateAdOut
 	return CreateAdOutput{AdID: adID}, nil
 }
 
+func adPayload(in CreateAdInput) map[string]any {
+	return map[string]any{
+		"name":     in.Name,
+		"adset_id": in.AdSetID,
+		"creative": map[string]any{"creative_id": in.CreativeID},
+		"status":   in.Status,
+	}
+}
+
 type ListAdsInput struct {
 	AdAccountID string
 }
@@ -85,22 +103,24 @@ type ListAdsOutput struct {
 }
 
 func (s *AdService) ListAds(ctx context.Context, in ListAdsInput) (ListAdsOutput, error) {
-	if err := s.Sem.Acquire(ctx); err != nil {
-		return ListAdsOutput{}, err
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return ListAdsOutput{}, fmt.Errorf("get ad account: %w", err)
 	}
-	defer s.Sem.Release()
 
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
 	if err != nil {
-		return ListAdsOutput{}, fmt.Errorf("get ad account: %w", err)
+		return ListAdsOutput{}, err
 	}
+	defer lease.Release()
 
 	token, err := s.Tokens.Resolve(adAccount.TokenRef)
 	if err != nil {
 		return ListAdsOutput{}, fmt.Errorf("resolve token: %w", err)
 	}
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
 
 	fields := []string{"id", "name", "adset_id", "status", "creative{id,name}", "created_time"}
 	data, err := mc.ListAds(ctx, adAccount.AdAccountID, fields)
@@ -142,25 +162,34 @@ type UpdateAdInput struct {
 	AdID        string
 	Name        *string // opcional
 	Status      *string // opcional (ACTIVE, PAUSED, DELETED)
+
+	DryRun bool
 }
 
-func (s *AdService) UpdateAd(ctx context.Context, in UpdateAdInput) error {
-	if err := s.Sem.Acquire(ctx); err != nil {
-		return err
+// UpdateAd devolve o resultado do dry run quando in.DryRun; nil caso contrário
+func (s *AdService) UpdateAd(ctx context.Context, in UpdateAdInput) (*DryRunResult, error) {
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("get ad account: %w", err)
 	}
-	defer s.Sem.Release()
 
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
 	if err != nil {
-		return fmt.Errorf("get ad account: %w", err)
+		return nil, err
 	}
+	defer lease.Release()
 
 	token, err := s.Tokens.Resolve(adAccount.TokenRef)
 	if err != nil {
-		return fmt.Errorf("resolve token: %w", err)
+		return nil, fmt.Errorf("resolve token: %w", err)
 	}
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	if err := checkObjectsInAdAccount(ctx, mc, adAccount.AdAccountID, in.AdID); err != nil {
+		return nil, err
+	}
 
 	payload := map[string]any{}
 	if in.Name != nil {
@@ -171,10 +200,13 @@ func (s *AdService) UpdateAd(ctx context.Context, in UpdateAdInput) error {
 	}
 
 	if len(payload) == 0 {
-		return fmt.Errorf("no fields to update")
+		return nil, fmt.Errorf("no fields to update")
 	}
 
-	return mc.UpdateAd(ctx, in.AdID, payload)
+	if in.DryRun {
+		return dryRun(ctx, mc, in.AdID, payload)
+	}
+	return nil, mc.UpdateAd(ctx, in.AdID, payload)
 }
 
 // ======= DELETE Ad (soft delete) =======
@@ -182,25 +214,39 @@ func (s *AdService) UpdateAd(ctx context.Context, in UpdateAdInput) error {
 type DeleteAdInput struct {
 	AdAccountID string // necessário para resolver token
 	AdID        string
+	DryRun      bool
 }
 
-func (s *AdService) DeleteAd(ctx context.Context, in DeleteAdInput) error {
-	if err := s.Sem.Acquire(ctx); err != nil {
-		return err
+func (s *AdService) DeleteAd(ctx context.Context, in DeleteAdInput) (*DryRunResult, error) {
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("get ad account: %w", err)
 	}
-	defer s.Sem.Release()
+	return s.deleteAd(ctx, adAccount, in)
+}
 
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+// deleteAd: idem, sem buscar a ad account de novo
+func (s *AdService) deleteAd(ctx context.Context, adAccount storage.AdAccount, in DeleteAdInput) (*DryRunResult, error) {
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
 	if err != nil {
-		return fmt.Errorf("get ad account: %w", err)
+		return nil, err
 	}
+	defer lease.Release()
 
 	token, err := s.Tokens.Resolve(adAccount.TokenRef)
 	if err != nil {
-		return fmt.Errorf("resolve token: %w", err)
+		return nil, fmt.Errorf("resolve token: %w", err)
 	}
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
 
-	return mc.SoftDeleteAd(ctx, in.AdID)
+	if err := checkObjectsInAdAccount(ctx, mc, adAccount.AdAccountID, in.AdID); err != nil {
+		return nil, err
+	}
+
+	if in.DryRun {
+		return dryRun(ctx, mc, in.AdID, map[string]any{"status": "DELETED"})
+	}
+	return nil, mc.SoftDeleteAd(ctx, in.AdID)
 }
@@ -3,22 +3,22 @@ package service
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"creative-service/internal/meta"
 	"creative-service/internal/secrets"
 	"creative-service/internal/storage"
+	"creative-service/internal/targeting"
 )
 
 type AdSetService struct {
 	Store  *storage.Store
 	Tokens secrets.Resolver
 
-	BaseURL     string
-	APIVersion  string
-	HTTPTimeout time.Duration
+	Meta *meta.ClientFactory
 
-	Sem *Semaphore
+	Sched *Scheduler
 }
 
 type CreateAdSetInput struct {
@@ -27,52 +27,184 @@ type CreateAdSetInput struct {
 	Name             string
 	BillingEvent     string
 	OptimizationGoal string
+	BidStrategy      string         // default: LOWEST_COST_WITHOUT_CAP (ou bid cap se houver BidAmount)
 	BidAmount        int
+	BidConstraints   map[string]any // ex: {"roas_average_floor": 15000}
 	DailyBudget      int
-	Targeting        map[string]any
+	LifetimeBudget   int             // exige EndTime
+	StartTime        string          // RFC3339, ou sem offset no fuso da ad account
+	EndTime          string          // idem
+	AdSetSchedule    []ScheduleBlock // dayparting, exige LifetimeBudget
+	Targeting        *targeting.Spec
 	Status           string
+
+	DryRun bool // valida e devolve o payload sem criar
 }
 
 type CreateAdSetOutput struct {
-	AdSetID string `json:"adset_id"`
+	AdSetID string        `json:"adset_id"`
+	DryRun  *DryRunResult `json:"dry_run,omitempty"`
 }
 
 func (s *AdSetService) CreateAdSet(ctx context.Context, in CreateAdSetInput) (CreateAdSetOutput, error) {
-	if err := s.Sem.Acquire(ctx); err != nil {
+	if err := validateAdSetBudget(in.DailyBudget, in.LifetimeBudget, in.EndTime); err != nil {
+		return CreateAdSetOutput{}, err
+	}
+	if err := validateTargeting(in.Targeting); err != nil {
 		return CreateAdSetOutput{}, err
 	}
-	defer s.Sem.Release()
 
 	// Buscar ad account pelo ID (act_123456789)
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
 	if err != nil {
 		return CreateAdSetOutput{}, fmt.Errorf("get ad account: %w", err)
 	}
+	return s.createAdSet(ctx, adAccount, in)
+}
+
+// createAdSet recebe a ad account já resolvida pelo chamador
+func (s *AdSetService) createAdSet(ctx context.Context, adAccount storage.AdAccount, in CreateAdSetInput) (CreateAdSetOutput, error) {
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
+	if err != nil {
+		return CreateAdSetOutput{}, err
+	}
+	defer lease.Release()
 
 	token, err := s.Tokens.Resolve(adAccount.TokenRef)
 	if err != nil {
 		return CreateAdSetOutput{}, fmt.Errorf("resolve token: %w", err)
 	}
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	// Confere o orçamento contra a campanha antes de criar (CBO vs orçamento no adset)
+	campaign, err := mc.GetObject(ctx, in.CampaignID, []string{"daily_budget", "lifetime_budget", "bid_strategy"})
+	if err != nil {
+		return CreateAdSetOutput{}, fmt.Errorf("get campaign: %w", err)
+	}
+
+	var loc *time.Location
+	if hasAdSetSchedule(in) {
+		if loc, err = accountLocation(ctx, mc, adAccount.AdAccountID); err != nil {
+			return CreateAdSetOutput{}, err
+		}
+	}
+	payload, err := adSetPayload(campaign, loc, in)
+	if err != nil {
+		return CreateAdSetOutput{}, err
+	}
+
+	if in.DryRun {
+		dr, err := dryRun(ctx, mc, meta.Act(adAccount.AdAccountID)+"/adsets", payload)
+		if err != nil {
+			return CreateAdSetOutput{}, err
+		}
+		return CreateAdSetOutput{DryRun: dr}, nil
+	}
+
+	adsetID, err := mc.CreateAdSet(ctx, adAccount.AdAccountID, payload)
+	if err != nil {
+		return CreateAdSetOutput{}, err
+	}
+
+	return CreateAdSetOutput{AdSetID: adsetID}, nil
+}
+
+// planAdSet monta o adset de um dry run do launch. A campanha ainda não existe:
+// o adset é conferido contra o spec dela e só localmente, com o ID da campanha
+// trocado pela referência dela no plano. Recusas locais voltam no resultado;
+// o erro é só o da busca do fuso da conta, quando há agenda.
+func (s *AdSetService) planAdSet(ctx context.Context, adAccount storage.AdAccount, campaign map[string]any, in CreateAdSetInput) (*DryRunResult, error) {
+	path := meta.Act(adAccount.AdAccountID) + "/adsets"
+
+	var loc *time.Location
+	if hasAdSetSchedule(in) {
+		lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
+		if err != nil {
+			return nil, err
+		}
+		defer lease.Release()
+
+		token, err := s.Tokens.Resolve(adAccount.TokenRef)
+		if err != nil {
+			return nil, fmt.Errorf("resolve token: %w", err)
+		}
+		mc := s.Meta.Client(token)
+		if loc, err = accountLocation(meta.WithAdAccount(ctx, adAccount.AdAccountID), mc, adAccount.AdAccountID); err != nil {
+			return nil, err
+		}
+	}
+
+	payload, err := adSetPayload(campaign, loc, in)
+	if err != nil {
+		return localDryRunError(path, err), nil
+	}
+	return localDryRun(path, payload), nil
+}
+
+// adSetPayload confere o adset contra a campanha (CBO vs orçamento no adset,
+// lance) e a agenda no fuso da conta, e monta o payload do create
+func adSetPayload(campaign map[string]any, loc *time.Location, in CreateAdSetInput) (map[string]any, error) {
+	if err := validateAdSetAgainstCampaign(campaign, in.DailyBudget, in.LifetimeBudget); err != nil {
+		return nil, err
+	}
+	bidStrategy, sendBidStrategy, err := resolveAdSetBid(campaign, in.BidStrategy, in.BidAmount, in.BidConstraints, in.OptimizationGoal)
+	if err != nil {
+		return nil, err
+	}
+
+	var sched normalizedSchedule
+	if hasAdSetSchedule(in) {
+		sched, err = validateAdSetSchedule(adSetSchedule{
+			StartTime:      in.StartTime,
+			EndTime:        in.EndTime,
+			Blocks:         in.AdSetSchedule,
+			LifetimeBudget: in.LifetimeBudget,
+		}, loc, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	payload := map[string]any{
 		"campaign_id":       in.CampaignID,
 		"name":              in.Name,
 		"billing_event":     in.BillingEvent,
 		"optimization_goal": in.OptimizationGoal,
-		"bid_strategy":      "LOWEST_COST_WITHOUT_CAP",
-		"daily_budget":      in.DailyBudget,
 		"targeting":         in.Targeting,
 		"status":            in.Status,
 	}
-
-	adsetID, err := mc.CreateAdSet(ctx, adAccount.AdAccountID, payload)
-	if err != nil {
-		return CreateAdSetOutput{}, err
+	if sendBidStrategy {
+		payload["bid_strategy"] = bidStrategy
+	}
+	if in.BidAmount > 0 {
+		payload["bid_amount"] = in.BidAmount
+	}
+	if len(in.BidConstraints) > 0 {
+		payload["bid_constraints"] = in.BidConstraints
+	}
+	if in.DailyBudget > 0 {
+		payload["daily_budget"] = in.DailyBudget
+	}
+	if in.LifetimeBudget > 0 {
+		payload["lifetime_budget"] = in.LifetimeBudget
 	}
+	if sched.StartTime != "" {
+		payload["start_time"] = sched.StartTime
+	}
+	if sched.EndTime != "" {
+		payload["end_time"] = sched.EndTime
+	}
+	if len(sched.Blocks) > 0 {
+		payload["adset_schedule"] = sched.Blocks
+		payload["pacing_type"] = []string{"day_parting"}
+	}
+	return payload, nil
+}
 
-	return CreateAdSetOutput{AdSetID: adsetID}, nil
+func hasAdSetSchedule(in CreateAdSetInput) bool {
+	return in.StartTime != "" || in.EndTime != "" || len(in.AdSetSchedule) > 0
 }
 
 type ListAdSetsInput struct {
@@ -85,7 +217,13 @@ type AdSetItem struct {
 	CampaignID      string `json:"campaign_id,omitempty"`
 	Status          string `json:"status,omitempty"`
 	DailyBudget     string `json:"daily_budget,omitempty"`
+	LifetimeBudget  string `json:"lifetime_budget,omitempty"`
+	StartTime       string `json:"start_time,omitempty"`
+	EndTime         string `json:"end_time,omitempty"`
+	AdSetSchedule   []any  `json:"adset_schedule,omitempty"`
 	BillingEvent    string `json:"billing_event,omitempty"`
+	BidStrategy     string `json:"bid_strategy,omitempty"`
+	BidAmount       string `json:"bid_amount,omitempty"`
 	CreatedTime     string `json:"created_time,omitempty"`
 }
 
@@ -94,24 +232,26 @@ type ListAdSetsOutput struct {
 }
 
 func (s *AdSetService) ListAdSets(ctx context.Context, in ListAdSetsInput) (ListAdSetsOutput, error) {
-	if err := s.Sem.Acquire(ctx); err != nil {
-		return ListAdSetsOutput{}, err
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return ListAdSetsOutput{}, fmt.Errorf("get ad account: %w", err)
 	}
-	defer s.Sem.Release()
 
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
 	if err != nil {
-		return ListAdSetsOutput{}, fmt.Errorf("get ad account: %w", err)
+		return ListAdSetsOutput{}, err
 	}
+	defer lease.Release()
 
 	token, err := s.Tokens.Resolve(adAccount.TokenRef)
 	if err != nil {
 		return ListAdSetsOutput{}, fmt.Errorf("resolve token: %w", err)
 	}
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
 
-	fields := []string{"id", "name", "campaign_id", "status", "daily_budget", "billing_event", "created_time"}
+	fields := []string{"id", "name", "campaign_id", "status", "daily_budget", "lifetime_budget", "start_time", "end_time", "adset_schedule", "billing_event", "bid_strategy", "bid_amount", "created_time"}
 	data, err := mc.ListAdSets(ctx, adAccount.AdAccountID, fields)
 	if err != nil {
 		return ListAdSetsOutput{}, err
@@ -135,9 +275,21 @@ func (s *AdSetService) ListAdSets(ctx context.Context, in ListAdSetsInput) (List
 		if db, ok := item["daily_budget"].(string); ok {
 			a.DailyBudget = db
 		}
+		if lb, ok := item["lifetime_budget"].(string); ok {
+			a.LifetimeBudget = lb
+		}
+		a.StartTime = stringField(item, "start_time")
+		if et, ok := item["end_time"].(string); ok {
+			a.EndTime = et
+		}
+		a.AdSetSchedule, _ = item["adset_schedule"].([]any)
 		if be, ok := item["billing_event"].(string); ok {
 			a.BillingEvent = be
 		}
+		if bs, ok := item["bid_strategy"].(string); ok {
+			a.BidStrategy = bs
+		}
+		a.BidAmount = stringField(item, "bid_amount")
 		if ct, ok := item["created_time"].(string); ok {
 			a.CreatedTime = ct
 		}
@@ -155,27 +307,140 @@ type UpdateAdSetInput struct {
 	Name         *string // opcional
 	Status       *string // opcional (ACTIVE, PAUSED, DELETED)
 	DailyBudget  *int    // opcional
+	LifetimeBudget *int    // opcional
+	StartTime      *string // opcional (RFC3339 ou horário local da ad account)
+	EndTime        *string // opcional (idem)
+	AdSetSchedule  *[]ScheduleBlock // opcional; lista vazia remove o dayparting
+
+	BidStrategy    *string        // opcional
+	BidAmount      *int           // opcional
+	BidConstraints map[string]any // opcional
+
+	DryRun bool
 }
 
-func (s *AdSetService) UpdateAdSet(ctx context.Context, in UpdateAdSetInput) error {
-	if err := s.Sem.Acquire(ctx); err != nil {
-		return err
+// UpdateAdSet devolve o resultado do dry run quando in.DryRun; nil caso contrário
+func (s *AdSetService) UpdateAdSet(ctx context.Context, in UpdateAdSetInput) (*DryRunResult, error) {
+	if in.DailyBudget != nil && in.LifetimeBudget != nil {
+		return nil, fmt.Errorf("daily_budget and lifetime_budget are mutually exclusive")
+	}
+	if intValue(in.DailyBudget) < 0 || intValue(in.LifetimeBudget) < 0 {
+		return nil, fmt.Errorf("budgets must not be negative")
 	}
-	defer s.Sem.Release()
 
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
 	if err != nil {
-		return fmt.Errorf("get ad account: %w", err)
+		return nil, fmt.Errorf("get ad account: %w", err)
 	}
 
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
+	if err != nil {
+		return nil, err
+	}
+	defer lease.Release()
+
 	token, err := s.Tokens.Resolve(adAccount.TokenRef)
 	if err != nil {
-		return fmt.Errorf("resolve token: %w", err)
+		return nil, fmt.Errorf("resolve token: %w", err)
 	}
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	if err := checkObjectsInAdAccount(ctx, mc, adAccount.AdAccountID, in.AdSetID); err != nil {
+		return nil, err
+	}
 
 	payload := map[string]any{}
+
+	// Lance e orçamento dependem da campanha (CBO), com as mesmas regras da criação
+	bidChange := in.BidStrategy != nil || in.BidAmount != nil || in.BidConstraints != nil
+	budgetChange := intValue(in.DailyBudget) > 0 || intValue(in.LifetimeBudget) > 0
+	if bidChange || budgetChange {
+		current, err := mc.GetObject(ctx, in.AdSetID, []string{"campaign_id", "bid_strategy", "bid_amount", "bid_constraints", "optimization_goal"})
+		if err != nil {
+			return nil, fmt.Errorf("get adset: %w", err)
+		}
+		campaign, err := mc.GetObject(ctx, stringField(current, "campaign_id"), []string{"daily_budget", "lifetime_budget", "bid_strategy"})
+		if err != nil {
+			return nil, fmt.Errorf("get campaign: %w", err)
+		}
+
+		if budgetChange {
+			if err := validateAdSetAgainstCampaign(campaign, intValue(in.DailyBudget), intValue(in.LifetimeBudget)); err != nil {
+				return nil, err
+			}
+		}
+		if bidChange {
+			bid, err := resolveAdSetBidUpdate(current, campaign, in)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range bid {
+				payload[k] = v
+			}
+		}
+	}
+
+	// Mudança de orçamento ou agendamento: valida o estado final no fuso da conta
+	// (lifetime exige end_time, dayparting exige lifetime)
+	if in.DailyBudget != nil || in.LifetimeBudget != nil || in.StartTime != nil || in.EndTime != nil || in.AdSetSchedule != nil {
+		current, err := mc.GetObject(ctx, in.AdSetID, []string{"daily_budget", "lifetime_budget", "start_time", "end_time", "adset_schedule"})
+		if err != nil {
+			return nil, fmt.Errorf("get adset: %w", err)
+		}
+
+		sc := adSetSchedule{
+			StartTime: stringField(current, "start_time"),
+			EndTime:   stringField(current, "end_time"),
+			// Sem mudança de horário não revalida end_time/duração de um adset já rodando
+			KeepTimes: in.StartTime == nil && in.EndTime == nil,
+		}
+		sc.LifetimeBudget, _ = strconv.Atoi(stringField(current, "lifetime_budget"))
+		if in.DailyBudget != nil && *in.DailyBudget > 0 {
+			sc.LifetimeBudget = 0
+		}
+		if in.LifetimeBudget != nil {
+			sc.LifetimeBudget = *in.LifetimeBudget
+		}
+		if in.StartTime != nil {
+			sc.StartTime = *in.StartTime
+		}
+		if in.EndTime != nil {
+			sc.EndTime = *in.EndTime
+		}
+		if in.AdSetSchedule != nil {
+			sc.Blocks = *in.AdSetSchedule
+		} else if blocks, ok := current["adset_schedule"].([]any); ok && len(blocks) > 0 && sc.LifetimeBudget == 0 {
+			return nil, fmt.Errorf("adset uses adset_schedule: remove it before switching to daily_budget")
+		}
+
+		loc, err := accountLocation(ctx, mc, adAccount.AdAccountID)
+		if err != nil {
+			return nil, err
+		}
+		sched, err := validateAdSetSchedule(sc, loc, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		if in.StartTime != nil {
+			payload["start_time"] = sched.StartTime
+		}
+		if in.EndTime != nil {
+			payload["end_time"] = sched.EndTime
+		}
+		if in.AdSetSchedule != nil {
+			if len(sched.Blocks) > 0 {
+				payload["adset_schedule"] = sched.Blocks
+				payload["pacing_type"] = []string{"day_parting"}
+			} else {
+				payload["adset_schedule"] = []ScheduleBlock{}
+				payload["pacing_type"] = []string{"standard"}
+			}
+		}
+	}
+
 	if in.Name != nil {
 		payload["name"] = *in.Name
 	}
@@ -185,12 +450,18 @@ func (s *AdSetService) UpdateAdSet(ctx context.Context, in UpdateAdSetInput) err
 	if in.DailyBudget != nil {
 		payload["daily_budget"] = *in.DailyBudget
 	}
+	if in.LifetimeBudget != nil {
+		payload["lifetime_budget"] = *in.LifetimeBudget
+	}
 
 	if len(payload) == 0 {
-		return fmt.Errorf("no fields to update")
+		return nil, fmt.Errorf("no fields to update")
 	}
 
-	return mc.UpdateAdSet(ctx, in.AdSetID, payload)
+	if in.DryRun {
+		return dryRun(ctx, mc, in.AdSetID, payload)
+	}
+	return nil, mc.UpdateAdSet(ctx, in.AdSetID, payload)
 }
 
 // ======= DELETE AdSet (soft delete) =======
@@ -198,25 +469,39 @@ func (s *AdSetService) UpdateAdSet(ctx context.Context, in UpdateAdSetInput) err
 type DeleteAdSetInput struct {
 	AdAccountID string // necessário para resolver token
 	AdSetID     string
+	DryRun      bool
 }
 
-func (s *AdSetService) DeleteAdSet(ctx context.Context, in DeleteAdSetInput) error {
-	if err := s.Sem.Acquire(ctx); err != nil {
-		return err
+func (s *AdSetService) DeleteAdSet(ctx context.Context, in DeleteAdSetInput) (*DryRunResult, error) {
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("get ad account: %w", err)
 	}
-	defer s.Sem.Release()
+	return s.deleteAdSet(ctx, adAccount, in)
+}
 
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+// deleteAdSet: idem, sem buscar a ad account de novo
+func (s *AdSetService) deleteAdSet(ctx context.Context, adAccount storage.AdAccount, in DeleteAdSetInput) (*DryRunResult, error) {
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
 	if err != nil {
-		return fmt.Errorf("get ad account: %w", err)
+		return nil, err
 	}
+	defer lease.Release()
 
 	token, err := s.Tokens.Resolve(adAccount.TokenRef)
 	if err != nil {
-		return fmt.Errorf("resolve token: %w", err)
+		return nil, fmt.Errorf("resolve token: %w", err)
 	}
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
 
-	return mc.SoftDeleteAdSet(ctx, in.AdSetID)
+	if err := checkObjectsInAdAccount(ctx, mc, adAccount.AdAccountID, in.AdSetID); err != nil {
+		return nil, err
+	}
+
+	if in.DryRun {
+		return dryRun(ctx, mc, in.AdSetID, map[string]any{"status": "DELETED"})
+	}
+	return nil, mc.SoftDeleteAdSet(ctx, in.AdSetID)
 }
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"creative-service/internal/auth"
+	"creative-service/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type APIKeyService struct {
+	Store *storage.Store
+}
+
+type CreateAPIKeyInput struct {
+	Name        string
+	Scopes      []string // read, write, admin
+	ClientUUIDs []string // obrigatório sem admin
+}
+
+type CreateAPIKeyOutput struct {
+	Key    string         `json:"key"` // só aparece aqui; o banco guarda o hash
+	APIKey storage.APIKey `json:"api_key"`
+}
+
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, in CreateAPIKeyInput) (CreateAPIKeyOutput, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return CreateAPIKeyOutput{}, fmt.Errorf("name is required")
+	}
+	if len(in.Scopes) == 0 {
+		return CreateAPIKeyOutput{}, fmt.Errorf("at least one scope is required")
+	}
+	admin := false
+	for _, sc := range in.Scopes {
+		if !auth.ValidScope(auth.Scope(sc)) {
+			return CreateAPIKeyOutput{}, fmt.Errorf("invalid scope: %q", sc)
+		}
+		admin = admin || auth.Scope(sc) == auth.ScopeAdmin
+	}
+	if !admin && len(in.ClientUUIDs) == 0 {
+		return CreateAPIKeyOutput{}, fmt.Errorf("client_uuids is required for keys without admin scope")
+	}
+	for _, clientUUID := range in.ClientUUIDs {
+		if _, err := uuid.Parse(clientUUID); err != nil {
+			return CreateAPIKeyOutput{}, fmt.Errorf("invalid client_uuid: %q", clientUUID)
+		}
+		if _, err := s.Store.GetClientByUUID(ctx, clientUUID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return CreateAPIKeyOutput{}, fmt.Errorf("client not found: %s", clientUUID)
+			}
+			return CreateAPIKeyOutput{}, fmt.Errorf("get client: %w", err)
+		}
+	}
+
+	key, hash, err := auth.GenerateKey()
+	if err != nil {
+		return CreateAPIKeyOutput{}, fmt.Errorf("generate key: %w", err)
+	}
+	created, err := s.Store.CreateAPIKey(ctx, storage.APIKey{
+		KeyID:       uuid.New().String(),
+		Name:        name,
+		KeyPrefix:   auth.DisplayPrefix(key),
+		Scopes:      in.Scopes,
+		ClientUUIDs: in.ClientUUIDs,
+	}, hash)
+	if err != nil {
+		return CreateAPIKeyOutput{}, fmt.Errorf("save api key: %w", err)
+	}
+	return CreateAPIKeyOutput{Key: key, APIKey: created}, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) ([]storage.APIKey, error) {
+	return s.Store.ListAPIKeys(ctx)
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, keyID string) error {
+	if _, err := uuid.Parse(keyID); err != nil {
+		return storage.ErrAPIKeyNotFound
+	}
+	return s.Store.RevokeAPIKey(ctx, keyID)
+}
+
+// Authenticate troca a chave enviada pelo Principal correspondente;
+// auth.ErrUnauthenticated para chave desconhecida ou revogada
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*auth.Principal, error) {
+	k, err := s.Store.GetActiveAPIKeyByHash(ctx, auth.HashKey(key))
+	if errors.Is(err, storage.ErrAPIKeyNotFound) {
+		return nil, auth.ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+
+	// last_used_at é informativo: falha aqui não derruba o request
+	_ = s.Store.TouchAPIKey(ctx, k.KeyID)
+
+	scopes := make([]auth.Scope, 0, len(k.Scopes))
+	for _, sc := range k.Scopes {
+		scopes = append(scopes, auth.Scope(sc))
+	}
+	return &auth.Principal{KeyID: k.KeyID, Name: k.Name, Scopes: scopes, ClientUUIDs: k.ClientUUIDs}, nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Validações de orçamento e estratégia de lance feitas antes de chamar a Meta.
+// Valores monetários seguem a Meta: inteiros na menor unidade da moeda (centavos).
+
+var bidStrategies = map[string]struct{}{
+	"LOWEST_COST_WITHOUT_CAP":   {},
+	"LOWEST_COST_WITH_BID_CAP":  {},
+	"COST_CAP":                  {},
+	"LOWEST_COST_WITH_MIN_ROAS": {},
+}
+
+// validateCampaignBudget cobre o orçamento de campanha (CBO / Advantage+ budget):
+// daily e lifetime são mutuamente exclusivos e bid_strategy na campanha só
+// existe quando o orçamento é da campanha
+func validateCampaignBudget(daily, lifetime, spendCap int, bidStrategy string) error {
+	if daily < 0 || lifetime < 0 || spendCap < 0 {
+		return fmt.Errorf("budgets and spend_cap must not be negative")
+	}
+	if daily > 0 && lifetime > 0 {
+		return fmt.Errorf("daily_budget and lifetime_budget are mutually exclusive")
+	}
+	if bidStrategy != "" {
+		if _, ok := bidStrategies[bidStrategy]; !ok {
+			return fmt.Errorf("invalid bid_strategy: %q", bidStrategy)
+		}
+		if daily == 0 && lifetime == 0 {
+			return fmt.Errorf("bid_strategy on campaign requires daily_budget or lifetime_budget")
+		}
+	}
+	if spendCap > 0 && lifetime > 0 && spendCap < lifetime {
+		return fmt.Errorf("spend_cap must be greater than or equal to lifetime_budget")
+	}
+	return nil
+}
+
+// validateAdSetBudget: daily e lifetime são mutuamente exclusivos e lifetime
+// exige end_time. Os horários em si são validados no fuso da ad account
+// (validateAdSetSchedule).
+func validateAdSetBudget(daily, lifetime int, endTime string) error {
+	if daily < 0 || lifetime < 0 {
+		return fmt.Errorf("budgets must not be negative")
+	}
+	if daily > 0 && lifetime > 0 {
+		return fmt.Errorf("daily_budget and lifetime_budget are mutually exclusive")
+	}
+	if lifetime > 0 && endTime == "" {
+		return fmt.Errorf("lifetime_budget requires end_time")
+	}
+	return nil
+}
+
+// validateAdSetAgainstCampaign confere o orçamento do adset com o da campanha:
+// com orçamento de campanha o adset não pode ter orçamento próprio, e sem ele
+// o adset precisa de daily ou lifetime
+func validateAdSetAgainstCampaign(campaign map[string]any, daily, lifetime int) error {
+	campaignBudget := positiveField(campaign, "daily_budget") || positiveField(campaign, "lifetime_budget")
+
+	if campaignBudget && (daily > 0 || lifetime > 0) {
+		return fmt.Errorf("campaign uses campaign budget: adset must not set daily_budget or lifetime_budget")
+	}
+	if !campaignBudget && daily == 0 && lifetime == 0 {
+		return fmt.Errorf("missing adset budget: daily_budget or lifetime_budget is required")
+	}
+	return nil
+}
+
+// resolveAdSetBid decide a estratégia de lance efetiva do adset e valida
+// bid_amount / bid_constraints contra ela. Com orçamento de campanha a estratégia
+// é herdada da campanha e não vai no payload do adset (send=false).
+// Sem estratégia explícita segue o default da Meta: bid_amount implica bid cap.
+func resolveAdSetBid(campaign map[string]any, strategy string, bidAmount int, constraints map[string]any, optimizationGoal string) (effective string, send bool, err error) {
+	send = true
+	campaignBudget := positiveField(campaign, "daily_budget") || positiveField(campaign, "lifetime_budget")
+	if cbs := stringField(campaign, "bid_strategy"); campaignBudget && cbs != "" {
+		if strategy != "" && strategy != cbs {
+			return "", false, fmt.Errorf("bid_strategy must match campaign bid_strategy (%s)", cbs)
+		}
+		strategy, send = cbs, false
+	}
+
+	if strategy == "" {
+		strategy = "LOWEST_COST_WITHOUT_CAP"
+		if bidAmount > 0 {
+			strategy = "LOWEST_COST_WITH_BID_CAP"
+		}
+	}
+
+	if err := validateAdSetBid(strategy, bidAmount, constraints, optimizationGoal); err != nil {
+		return "", false, err
+	}
+	return strategy, send, nil
+}
+
+// resolveAdSetBidUpdate aplica o patch de lance sobre o adset atual e valida a
+// combinação final contra a campanha, como resolveAdSetBid faz na criação.
+// Devolve só os campos de lance que vão no payload do update.
+func resolveAdSetBidUpdate(current, campaign map[string]any, in UpdateAdSetInput) (map[string]any, error) {
+	strategy := stringField(current, "bid_strategy")
+	if in.BidStrategy != nil {
+		strategy = *in.BidStrategy
+	}
+	bidAmount, _ := strconv.Atoi(stringField(current, "bid_amount"))
+	if in.BidAmount != nil {
+		bidAmount = *in.BidAmount
+	}
+	constraints, _ := current["bid_constraints"].(map[string]any)
+	if in.BidConstraints != nil {
+		constraints = in.BidConstraints
+	}
+
+	// Trocar de estratégia descarta o que a nova não aceita
+	if in.BidStrategy != nil && in.BidAmount == nil && (strategy == "LOWEST_COST_WITHOUT_CAP" || strategy == "LOWEST_COST_WITH_MIN_ROAS") {
+		bidAmount = 0
+	}
+	if in.BidStrategy != nil && in.BidConstraints == nil && strategy != "LOWEST_COST_WITH_MIN_ROAS" {
+		constraints = nil
+	}
+
+	effective, send, err := resolveAdSetBid(campaign, strategy, bidAmount, constraints, stringField(current, "optimization_goal"))
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string]any{}
+	if in.BidStrategy != nil && send {
+		out["bid_strategy"] = effective
+	}
+	if in.BidAmount != nil {
+		out["bid_amount"] = *in.BidAmount
+	}
+	if in.BidConstraints != nil {
+		out["bid_constraints"] = in.BidConstraints
+	}
+	return out, nil
+}
+
+// validateAdSetBid confere as combinações que a Meta aceita:
+//   LOWEST_COST_WITHOUT_CAP    - sem bid_amount e sem bid_constraints
+//   LOWEST_COST_WITH_BID_CAP   - bid_amount obrigatório
+//   COST_CAP                   - bid_amount obrigatório
+//   LOWEST_COST_WITH_MIN_ROAS  - bid_constraints.roas_average_floor obrigatório, otimização VALUE
+func validateAdSetBid(strategy string, bidAmount int, constraints map[string]any, optimizationGoal string) error {
+	if _, ok := bidStrategies[strategy]; !ok {
+		return fmt.Errorf("invalid bid_strategy: %q", strategy)
+	}
+	if bidAmount < 0 {
+		return fmt.Errorf("bid_amount must not be negative")
+	}
+
+	switch strategy {
+	case "LOWEST_COST_WITHOUT_CAP":
+		if bidAmount > 0 {
+			return fmt.Errorf("bid_amount is not allowed with LOWEST_COST_WITHOUT_CAP")
+		}
+		if len(constraints) > 0 {
+			return fmt.Errorf("bid_constraints is not allowed with LOWEST_COST_WITHOUT_CAP")
+		}
+	case "LOWEST_COST_WITH_BID_CAP", "COST_CAP":
+		if bidAmount == 0 {
+			return fmt.Errorf("bid_amount is required with %s", strategy)
+		}
+		if len(constraints) > 0 {
+			return fmt.Errorf("bid_constraints is not allowed with %s", strategy)
+		}
+	case "LOWEST_COST_WITH_MIN_ROAS":
+		if bidAmount > 0 {
+			return fmt.Errorf("bid_amount is not allowed with LOWEST_COST_WITH_MIN_ROAS")
+		}
+		if !positiveField(constraints, "roas_average_floor") {
+			return fmt.Errorf("bid_constraints.roas_average_floor is required with LOWEST_COST_WITH_MIN_ROAS")
+		}
+		if optimizationGoal != "" && optimizationGoal != "VALUE" {
+			return fmt.Errorf("LOWEST_COST_WITH_MIN_ROAS requires optimization_goal VALUE")
+		}
+	}
+	return nil
+}
+
+// positiveField lê valores numéricos que a Meta devolve como string ("5000")
+func positiveField(m map[string]any, k string) bool {
+	n, err := strconv.Atoi(stringField(m, k))
+	return err == nil && n > 0
+}
+
+func intValue(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func stringValue(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
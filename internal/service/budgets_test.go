@@ -0,0 +1,170 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidateCampaignBudget(t *testing.T) {
+	tests := []struct {
+		name                      string
+		daily, lifetime, spendCap int
+		bidStrategy               string
+		wantErr                   string
+	}{
+		{name: "no budget"},
+		{name: "daily", daily: 5000},
+		{name: "lifetime with spend cap", lifetime: 10000, spendCap: 10000},
+		{name: "negative", daily: -1, wantErr: "must not be negative"},
+		{name: "daily and lifetime", daily: 5000, lifetime: 10000, wantErr: "mutually exclusive"},
+		{name: "bid strategy with budget", daily: 5000, bidStrategy: "COST_CAP"},
+		{name: "bid strategy without budget", bidStrategy: "COST_CAP", wantErr: "requires daily_budget or lifetime_budget"},
+		{name: "unknown bid strategy", daily: 5000, bidStrategy: "TARGET_COST", wantErr: "invalid bid_strategy"},
+		{name: "spend cap below lifetime", lifetime: 10000, spendCap: 5000, wantErr: "spend_cap must be greater"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErr(t, validateCampaignBudget(tt.daily, tt.lifetime, tt.spendCap, tt.bidStrategy), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateAdSetBudget(t *testing.T) {
+	tests := []struct {
+		name            string
+		daily, lifetime int
+		endTime         string
+		wantErr         string
+	}{
+		{name: "daily", daily: 2000},
+		{name: "lifetime with end time", lifetime: 10000, endTime: "2026-12-31T23:59:00-0300"},
+		{name: "lifetime without end time", lifetime: 10000, wantErr: "requires end_time"},
+		{name: "daily and lifetime", daily: 2000, lifetime: 10000, endTime: "2026-12-31", wantErr: "mutually exclusive"},
+		{name: "negative", lifetime: -5, wantErr: "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErr(t, validateAdSetBudget(tt.daily, tt.lifetime, tt.endTime), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateAdSetAgainstCampaign(t *testing.T) {
+	tests := []struct {
+		name            string
+		campaign        map[string]any
+		daily, lifetime int
+		wantErr         string
+	}{
+		{name: "campaign budget, adset without", campaign: map[string]any{"daily_budget": "5000"}},
+		{name: "campaign budget, adset with", campaign: map[string]any{"daily_budget": "5000"}, daily: 1000, wantErr: "must not set daily_budget"},
+		{name: "adset budget", campaign: map[string]any{}, lifetime: 10000},
+		{name: "no budget anywhere", campaign: map[string]any{"daily_budget": "0"}, wantErr: "missing adset budget"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErr(t, validateAdSetAgainstCampaign(tt.campaign, tt.daily, tt.lifetime), tt.wantErr)
+		})
+	}
+}
+
+func TestResolveAdSetBid(t *testing.T) {
+	roas := map[string]any{"roas_average_floor": 15000}
+	tests := []struct {
+		name         string
+		campaign     map[string]any
+		strategy     string
+		bidAmount    int
+		constraints  map[string]any
+		goal         string
+		wantStrategy string
+		wantSend     bool
+		wantErr      string
+	}{
+		{name: "default lowest cost", campaign: map[string]any{}, wantStrategy: "LOWEST_COST_WITHOUT_CAP", wantSend: true},
+		{name: "bid amount implies bid cap", campaign: map[string]any{}, bidAmount: 300, wantStrategy: "LOWEST_COST_WITH_BID_CAP", wantSend: true},
+		{name: "cost cap", campaign: map[string]any{}, strategy: "COST_CAP", bidAmount: 500, wantStrategy: "COST_CAP", wantSend: true},
+		{name: "cost cap without amount", campaign: map[string]any{}, strategy: "COST_CAP", wantErr: "bid_amount is required"},
+		{name: "lowest cost with amount", campaign: map[string]any{}, strategy: "LOWEST_COST_WITHOUT_CAP", bidAmount: 100, wantErr: "bid_amount is not allowed"},
+		{name: "bid cap with constraints", campaign: map[string]any{}, strategy: "LOWEST_COST_WITH_BID_CAP", bidAmount: 100, constraints: roas, wantErr: "bid_constraints is not allowed"},
+		{name: "min roas", campaign: map[string]any{}, strategy: "LOWEST_COST_WITH_MIN_ROAS", constraints: roas, goal: "VALUE", wantStrategy: "LOWEST_COST_WITH_MIN_ROAS", wantSend: true},
+		{name: "min roas without floor", campaign: map[string]any{}, strategy: "LOWEST_COST_WITH_MIN_ROAS", goal: "VALUE", wantErr: "roas_average_floor is required"},
+		{name: "min roas with other goal", campaign: map[string]any{}, strategy: "LOWEST_COST_WITH_MIN_ROAS", constraints: roas, goal: "LINK_CLICKS", wantErr: "requires optimization_goal VALUE"},
+		{name: "inherits campaign strategy", campaign: map[string]any{"daily_budget": "5000", "bid_strategy": "COST_CAP"}, bidAmount: 400, wantStrategy: "COST_CAP", wantSend: false},
+		{name: "conflicts with campaign strategy", campaign: map[string]any{"daily_budget": "5000", "bid_strategy": "COST_CAP"}, strategy: "LOWEST_COST_WITHOUT_CAP", wantErr: "must match campaign bid_strategy"},
+		{name: "campaign strategy without campaign budget is ignored", campaign: map[string]any{"bid_strategy": "COST_CAP"}, wantStrategy: "LOWEST_COST_WITHOUT_CAP", wantSend: true},
+		{name: "unknown strategy", campaign: map[string]any{}, strategy: "TARGET_COST", wantErr: "invalid bid_strategy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, send, err := resolveAdSetBid(tt.campaign, tt.strategy, tt.bidAmount, tt.constraints, tt.goal)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr != "" {
+				return
+			}
+			if strategy != tt.wantStrategy || send != tt.wantSend {
+				t.Errorf("got (%s, %v), want (%s, %v)", strategy, send, tt.wantStrategy, tt.wantSend)
+			}
+		})
+	}
+}
+
+func TestResolveAdSetBidUpdate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+	bidCap := map[string]any{"bid_strategy": "LOWEST_COST_WITH_BID_CAP", "bid_amount": "300", "optimization_goal": "LINK_CLICKS"}
+	cbo := map[string]any{"daily_budget": "5000", "bid_strategy": "COST_CAP"}
+
+	tests := []struct {
+		name     string
+		current  map[string]any
+		campaign map[string]any
+		in       UpdateAdSetInput
+		want     map[string]any
+		wantErr  string
+	}{
+		{name: "new bid amount", current: bidCap, campaign: map[string]any{}, in: UpdateAdSetInput{BidAmount: num(500)}, want: map[string]any{"bid_amount": 500}},
+		{
+			name:     "switch to lowest cost drops the current amount",
+			current:  bidCap,
+			campaign: map[string]any{},
+			in:       UpdateAdSetInput{BidStrategy: str("LOWEST_COST_WITHOUT_CAP")},
+			want:     map[string]any{"bid_strategy": "LOWEST_COST_WITHOUT_CAP"},
+		},
+		{name: "removing the amount of a bid cap", current: bidCap, campaign: map[string]any{}, in: UpdateAdSetInput{BidAmount: num(0)}, wantErr: "bid_amount is required"},
+		{
+			name:     "campaign budget strategy is inherited",
+			current:  map[string]any{"bid_strategy": "COST_CAP", "bid_amount": "300"},
+			campaign: cbo,
+			in:       UpdateAdSetInput{BidStrategy: str("COST_CAP"), BidAmount: num(400)},
+			want:     map[string]any{"bid_amount": 400},
+		},
+		{
+			name:     "conflicts with campaign strategy",
+			current:  map[string]any{"bid_strategy": "COST_CAP", "bid_amount": "300"},
+			campaign: cbo,
+			in:       UpdateAdSetInput{BidStrategy: str("LOWEST_COST_WITH_BID_CAP"), BidAmount: num(400)},
+			wantErr:  "must match campaign bid_strategy",
+		},
+		{
+			name:     "campaign strategy requires the amount",
+			current:  map[string]any{"bid_strategy": "COST_CAP", "bid_amount": "300"},
+			campaign: cbo,
+			in:       UpdateAdSetInput{BidAmount: num(0)},
+			wantErr:  "bid_amount is required with COST_CAP",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveAdSetBidUpdate(tt.current, tt.campaign, tt.in)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr != "" {
+				return
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("payload = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/secrets"
+	"creative-service/internal/storage"
+)
+
+// Limites do bulk: evita que uma única requisição monopolize a Meta API
+const (
+	bulkMaxTargets  = 1000
+	bulkMaxParallel = 4
+)
+
+var bulkStatuses = map[string]struct{}{
+	"ACTIVE":   {},
+	"PAUSED":   {},
+	"ARCHIVED": {},
+}
+
+type BulkService struct {
+	Store  *storage.Store
+	Tokens secrets.Resolver
+
+	Meta *meta.ClientFactory
+
+	Sched *Scheduler
+}
+
+type BulkTarget struct {
+	Type        string `json:"type"` // campaign, adset ou ad
+	ID          string `json:"id"`
+	AdAccountID string `json:"ad_account_id"`
+}
+
+type BulkStatusInput struct {
+	Targets []BulkTarget
+	Status  string
+
+	DryRun bool // valida cada item com validate_only sem alterar nada
+}
+
+type BulkItemResult struct {
+	Type        string `json:"type"`
+	ID          string `json:"id"`
+	AdAccountID string `json:"ad_account_id"`
+	Success     bool   `json:"success"`
+	Error       string `json:"error,omitempty"`
+
+	DryRun *DryRunResult `json:"dry_run,omitempty"`
+}
+
+type BulkStatusOutput struct {
+	DryRun    bool             `json:"dry_run,omitempty"`
+	Status    string           `json:"status"`
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// bulkGroup reúne os targets de uma ad account já resolvida (conta, token e vaga)
+type bulkGroup struct {
+	adAccount storage.AdAccount
+	mc        *meta.Client
+	slot      Slot
+	idxs      []int
+	dryRun    bool
+}
+
+// bulkItem liga um target à posição dele no relatório, ao client da Meta
+// já resolvido para o token da ad account e à vaga que ele disputa no scheduler
+type bulkItem struct {
+	index  int
+	target BulkTarget
+	mc     *meta.Client
+	slot   Slot
+	dryRun bool
+}
+
+// UpdateStatus aplica o mesmo status a campaigns, adsets e ads de várias ad accounts.
+// Ad accounts e tokens são resolvidos uma única vez por grupo; cada chamada à Meta
+// disputa vaga no scheduler como bulk e no máximo bulkMaxParallel rodam ao mesmo tempo.
+// Falhas são reportadas por item, nunca abortam o lote inteiro. Com DryRun cada
+// item vai para validate_only e Success indica que a Meta aceitou a mudança.
+func (s *BulkService) UpdateStatus(ctx context.Context, in BulkStatusInput) (BulkStatusOutput, error) {
+	status := strings.ToUpper(strings.TrimSpace(in.Status))
+	if _, ok := bulkStatuses[status]; !ok {
+		return BulkStatusOutput{}, fmt.Errorf("invalid status: %q", in.Status)
+	}
+	if len(in.Targets) == 0 {
+		return BulkStatusOutput{}, fmt.Errorf("no targets")
+	}
+	if len(in.Targets) > bulkMaxTargets {
+		return BulkStatusOutput{}, fmt.Errorf("too many targets: %d (max %d)", len(in.Targets), bulkMaxTargets)
+	}
+
+	results := make([]BulkItemResult, len(in.Targets))
+	for i, t := range in.Targets {
+		results[i] = BulkItemResult{Type: t.Type, ID: t.ID, AdAccountID: t.AdAccountID}
+	}
+
+	// Agrupa por ad account: conta e token são resolvidos uma vez por grupo
+	byAccount := map[string][]int{}
+	for i, t := range in.Targets {
+		if err := validateBulkTarget(t); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		byAccount[t.AdAccountID] = append(byAccount[t.AdAccountID], i)
+	}
+
+	var groups []bulkGroup
+	for adAccountID, idxs := range byAccount {
+		adAccount, err := getAdAccount(ctx, s.Store, adAccountID)
+		if err != nil {
+			failBulkItems(results, idxs, fmt.Errorf("get ad account: %w", err))
+			continue
+		}
+
+		token, err := s.Tokens.Resolve(adAccount.TokenRef)
+		if err != nil {
+			failBulkItems(results, idxs, fmt.Errorf("resolve token: %w", err))
+			continue
+		}
+
+		groups = append(groups, bulkGroup{
+			adAccount: adAccount,
+			mc:        s.Meta.Client(token),
+			slot:      accountSlot(adAccount, PriorityBulk),
+			idxs:      idxs,
+			dryRun:    in.DryRun,
+		})
+	}
+
+	s.runGroups(ctx, status, in.Targets, groups, results)
+
+	out := BulkStatusOutput{DryRun: in.DryRun, Status: status, Total: len(results), Results: results}
+	for _, r := range results {
+		if r.Success {
+			out.Succeeded++
+		} else {
+			out.Failed++
+		}
+	}
+	return out, nil
+}
+
+// runGroups é a parte do bulk que fala com a Meta: confere o dono dos IDs de cada
+// grupo e aplica o status com no máximo bulkMaxParallel chamadas ao mesmo tempo
+func (s *BulkService) runGroups(ctx context.Context, status string, targets []BulkTarget, groups []bulkGroup, results []BulkItemResult) {
+	var items []bulkItem
+	for _, g := range groups {
+		// Confere o dono de todos os IDs do grupo de uma vez; só os itens de outra conta falham
+		foreign, err := s.foreignTargets(ctx, g.mc, g.adAccount, g.slot, targets, g.idxs)
+		if err != nil {
+			failBulkItems(results, g.idxs, err)
+			continue
+		}
+		for _, i := range g.idxs {
+			if _, bad := foreign[targets[i].ID]; bad {
+				results[i].Error = fmt.Errorf("%w: %s", ErrObjectNotInAdAccount, targets[i].ID).Error()
+				continue
+			}
+			items = append(items, bulkItem{index: i, target: targets[i], mc: g.mc, slot: g.slot, dryRun: g.dryRun})
+		}
+	}
+
+	queue := make(chan bulkItem)
+	var wg sync.WaitGroup
+	for w := 0; w < bulkMaxParallel && w < len(items); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for it := range queue {
+				dr, err := s.applyStatus(ctx, it, status)
+				if err != nil {
+					results[it.index].Error = err.Error()
+					continue
+				}
+				results[it.index].DryRun = dr
+				if dr != nil && !dr.Valid {
+					results[it.index].Error = dr.Error.Message
+					continue
+				}
+				results[it.index].Success = true
+			}
+		}()
+	}
+	for _, it := range items {
+		queue <- it
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// foreignTargets devolve os IDs do grupo que não pertencem à ad account
+func (s *BulkService) foreignTargets(ctx context.Context, mc *meta.Client, adAccount storage.AdAccount, slot Slot, targets []BulkTarget, idxs []int) (map[string]struct{}, error) {
+	lease, err := s.Sched.Acquire(ctx, slot)
+	if err != nil {
+		return nil, err
+	}
+	defer lease.Release()
+
+	ids := make([]string, len(idxs))
+	for n, i := range idxs {
+		ids[n] = targets[i].ID
+	}
+	foreign, err := foreignObjects(meta.WithAdAccount(ctx, adAccount.AdAccountID), mc, adAccount.AdAccountID, ids...)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]struct{}, len(foreign))
+	for _, id := range foreign {
+		out[id] = struct{}{}
+	}
+	return out, nil
+}
+
+// applyStatus devolve o resultado do validate_only quando o item é dry run
+func (s *BulkService) applyStatus(ctx context.Context, it bulkItem, status string) (*DryRunResult, error) {
+	lease, err := s.Sched.Acquire(ctx, it.slot)
+	if err != nil {
+		return nil, err
+	}
+	defer lease.Release()
+
+	ctx = meta.WithAdAccount(ctx, it.target.AdAccountID)
+	payload := map[string]any{"status": status}
+	if it.dryRun {
+		return dryRun(ctx, it.mc, it.target.ID, payload)
+	}
+	switch it.target.Type {
+	case "campaign":
+		return nil, it.mc.UpdateCampaign(ctx, it.target.ID, payload)
+	case "adset":
+		return nil, it.mc.UpdateAdSet(ctx, it.target.ID, payload)
+	case "ad":
+		return nil, it.mc.UpdateAd(ctx, it.target.ID, payload)
+	}
+	return nil, fmt.Errorf("invalid type: %q", it.target.Type)
+}
+
+func validateBulkTarget(t BulkTarget) error {
+	switch t.Type {
+	case "campaign", "adset", "ad":
+	default:
+		return fmt.Errorf("invalid type: %q", t.Type)
+	}
+	if t.ID == "" {
+		return fmt.Errorf("missing id")
+	}
+	if t.AdAccountID == "" {
+		return fmt.Errorf("missing ad_account_id")
+	}
+	return nil
+}
+
+func failBulkItems(results []BulkItemResult, idxs []int, err error) {
+	for _, i := range idxs {
+		results[i].Error = err.Error()
+	}
+}
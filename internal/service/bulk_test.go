@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"creative-service/internal/meta/metatest"
+	"creative-service/internal/storage"
+)
+
+// checkErr: wantErr "" para sucesso ou um trecho da mensagem esperada
+func checkErr(t *testing.T, err error, wantErr string) {
+	t.Helper()
+	if wantErr == "" {
+		if err != nil {
+			t.Fatalf("err = %v, want nil", err)
+		}
+		return
+	}
+	if err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Fatalf("err = %v, want %q", err, wantErr)
+	}
+}
+
+func TestBulkUpdateStatusValidation(t *testing.T) {
+	tooMany := make([]BulkTarget, bulkMaxTargets+1)
+	for i := range tooMany {
+		tooMany[i] = BulkTarget{Type: "ad", ID: "1", AdAccountID: "act_1"}
+	}
+
+	tests := []struct {
+		name    string
+		in      BulkStatusInput
+		wantErr string
+	}{
+		{name: "invalid status", in: BulkStatusInput{Status: "RUNNING", Targets: tooMany[:1]}, wantErr: "invalid status"},
+		{name: "deleted is not a bulk status", in: BulkStatusInput{Status: "DELETED", Targets: tooMany[:1]}, wantErr: "invalid status"},
+		{name: "no targets", in: BulkStatusInput{Status: "PAUSED"}, wantErr: "no targets"},
+		{name: "too many targets", in: BulkStatusInput{Status: "PAUSED", Targets: tooMany}, wantErr: "too many targets"},
+	}
+
+	s := &BulkService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.UpdateStatus(context.Background(), tt.in)
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBulkUpdateStatusInvalidTargets(t *testing.T) {
+	// Itens inválidos falham sozinhos, sem tocar no banco nem na Meta
+	s := &BulkService{}
+	out, err := s.UpdateStatus(context.Background(), BulkStatusInput{
+		Status: "paused",
+		Targets: []BulkTarget{
+			{Type: "creative", ID: "1", AdAccountID: "act_1"},
+			{Type: "ad", AdAccountID: "act_1"},
+			{Type: "ad", ID: "1"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Status != "PAUSED" || out.Total != 3 || out.Failed != 3 {
+		t.Fatalf("status = %s total = %d failed = %d", out.Status, out.Total, out.Failed)
+	}
+	for i, want := range []string{"invalid type", "missing id", "missing ad_account_id"} {
+		if !strings.Contains(out.Results[i].Error, want) {
+			t.Errorf("results[%d].error = %q, want %q", i, out.Results[i].Error, want)
+		}
+	}
+}
+
+// bulkGroupFor monta o grupo que UpdateStatus resolveria no banco para a conta
+func bulkGroupFor(srv *metatest.Server, adAccountID string, idxs ...int) bulkGroup {
+	adAccount := storage.AdAccount{AdAccountID: adAccountID, TokenRef: "token_" + adAccountID}
+	return bulkGroup{adAccount: adAccount, mc: srv.Client("token"), slot: accountSlot(adAccount, PriorityBulk), idxs: idxs}
+}
+
+func TestBulkRunGroups(t *testing.T) {
+	tests := []struct {
+		name        string
+		failAdSet   bool
+		dryRun      bool
+		foreignAd   bool // o ad é de outra conta do business, não da informada
+		wantSuccess []bool
+		wantStatus  []string // status final de cada objeto na Meta
+	}{
+		{
+			name:        "all updated",
+			wantSuccess: []bool{true, true, true},
+			wantStatus:  []string{"PAUSED", "PAUSED", "PAUSED"},
+		},
+		{
+			name:        "meta error fails only its item",
+			failAdSet:   true,
+			wantSuccess: []bool{true, false, true},
+			wantStatus:  []string{"PAUSED", "ACTIVE", "PAUSED"},
+		},
+		{
+			name:        "foreign object fails only its item",
+			foreignAd:   true,
+			wantSuccess: []bool{true, true, false},
+			wantStatus:  []string{"PAUSED", "PAUSED", "ACTIVE"},
+		},
+		{
+			name:        "dry run validates without updating",
+			dryRun:      true,
+			wantSuccess: []bool{true, true, true},
+			wantStatus:  []string{"ACTIVE", "ACTIVE", "ACTIVE"},
+		},
+		{
+			name:        "dry run reports meta rejection per item",
+			failAdSet:   true,
+			dryRun:      true,
+			wantSuccess: []bool{true, false, true},
+			wantStatus:  []string{"ACTIVE", "ACTIVE", "ACTIVE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := metatest.NewServer()
+			defer srv.Close()
+			adOwner := "act_2"
+			if tt.foreignAd {
+				adOwner = "act_3"
+			}
+			ids := []string{
+				srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"status": "ACTIVE"}),
+				srv.AddObject(metatest.TypeAdSet, "act_1", map[string]any{"status": "ACTIVE"}),
+				srv.AddObject(metatest.TypeAd, adOwner, map[string]any{"status": "ACTIVE"}),
+			}
+			if tt.failAdSet {
+				srv.Inject(metatest.Fault{Method: "POST", Path: ids[1], Error: metatest.ErrInvalidParameter})
+			}
+
+			targets := []BulkTarget{
+				{Type: "campaign", ID: ids[0], AdAccountID: "act_1"},
+				{Type: "adset", ID: ids[1], AdAccountID: "act_1"},
+				{Type: "ad", ID: ids[2], AdAccountID: "act_2"},
+			}
+			results := make([]BulkItemResult, len(targets))
+			groups := []bulkGroup{bulkGroupFor(srv, "act_1", 0, 1), bulkGroupFor(srv, "act_2", 2)}
+			for i := range groups {
+				groups[i].dryRun = tt.dryRun
+			}
+
+			s := &BulkService{Sched: NewScheduler(4, 0, 0)}
+			s.runGroups(context.Background(), "PAUSED", targets, groups, results)
+
+			for i, id := range ids {
+				if results[i].Success != tt.wantSuccess[i] {
+					t.Errorf("results[%d].success = %v (%s), want %v", i, results[i].Success, results[i].Error, tt.wantSuccess[i])
+				}
+				o, _ := srv.Object(id)
+				if o.Fields["status"] != tt.wantStatus[i] {
+					t.Errorf("object %s status = %v, want %s", id, o.Fields["status"], tt.wantStatus[i])
+				}
+				if tt.foreignAd && i == 2 && !strings.Contains(results[i].Error, ErrObjectNotInAdAccount.Error()) {
+					t.Errorf("results[2].error = %q, want %q", results[i].Error, ErrObjectNotInAdAccount)
+				}
+				if dr := results[i].DryRun; (dr != nil) != tt.dryRun || (dr != nil && dr.Valid != tt.wantSuccess[i]) {
+					t.Errorf("results[%d].dry_run = %+v", i, dr)
+				}
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"creative-service/internal/meta"
 	"creative-service/internal/secrets"
@@ -14,11 +13,9 @@ type CampaignService struct {
 	Store  *storage.Store
 	Tokens secrets.Resolver
 
-	BaseURL     string
-	APIVersion  string
-	HTTPTimeout time.Duration
+	Meta *meta.ClientFactory
 
-	Sem *Semaphore
+	Sched *Scheduler
 }
 
 type CreateCampaignInput struct {
@@ -29,30 +26,49 @@ type CreateCampaignInput struct {
 	SpecialAdCategories  []string
 	BuyingType           string
 	IsAdSetBudgetSharingEnabled bool
+
+	// Orçamento de campanha (opcional): daily ou lifetime, nunca os dois
+	DailyBudget          int
+	LifetimeBudget       int
+	SpendCap             int
+	BidStrategy          string // só com orçamento de campanha
+
+	DryRun bool // valida e devolve o payload sem criar
 }
 
 type CreateCampaignOutput struct {
-	CampaignID string `json:"campaign_id"`
+	CampaignID string        `json:"campaign_id"`
+	DryRun     *DryRunResult `json:"dry_run,omitempty"`
 }
 
 func (s *CampaignService) CreateCampaign(ctx context.Context, in CreateCampaignInput) (CreateCampaignOutput, error) {
-	if err := s.Sem.Acquire(ctx); err != nil {
+	if err := validateCampaignBudget(in.DailyBudget, in.LifetimeBudget, in.SpendCap, in.BidStrategy); err != nil {
 		return CreateCampaignOutput{}, err
 	}
-	defer s.Sem.Release()
 
 	// Buscar ad account pelo ID (act_123456789)
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
 	if err != nil {
 		return CreateCampaignOutput{}, fmt.Errorf("get ad account: %w", err)
 	}
+	return s.createCampaign(ctx, adAccount, in)
+}
+
+// createCampaign cria com a ad account já resolvida; o launch reaproveita a mesma conta em todos os passos
+func (s *CampaignService) createCampaign(ctx context.Context, adAccount storage.AdAccount, in CreateCampaignInput) (CreateCampaignOutput, error) {
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
+	if err != nil {
+		return CreateCampaignOutput{}, err
+	}
+	defer lease.Release()
 
 	token, err := s.Tokens.Resolve(adAccount.TokenRef)
 	if err != nil {
 		return CreateCampaignOutput{}, fmt.Errorf("resolve token: %w", err)
 	}
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
 
 	payload := map[string]any{
 		"name":                              in.Name,
@@ -60,11 +76,37 @@ func (s *CampaignService) CreateCampaign(ctx context.Context, in CreateCampaignI
 		"status":                            in.Status,
 		"special_ad_categories":             in.SpecialAdCategories,
 		"buying_type":                       in.BuyingType,
-		"is_adset_budget_sharing_enabled":   in.IsAdSetBudgetSharingEnabled,
+	}
+
+	// Com orçamento de campanha a Meta distribui o budget entre os adsets;
+	// is_adset_budget_sharing_enabled só se aplica a orçamento por adset
+	if in.DailyBudget > 0 || in.LifetimeBudget > 0 {
+		if in.DailyBudget > 0 {
+			payload["daily_budget"] = in.DailyBudget
+		}
+		if in.LifetimeBudget > 0 {
+			payload["lifetime_budget"] = in.LifetimeBudget
+		}
+		if in.BidStrategy != "" {
+			payload["bid_strategy"] = in.BidStrategy
+		}
+	} else {
+		payload["is_adset_budget_sharing_enabled"] = in.IsAdSetBudgetSharingEnabled
+	}
+	if in.SpendCap > 0 {
+		payload["spend_cap"] = in.SpendCap
 	}
 
 	fmt.Printf("=== PAYLOAD PARA META API ===\n%+v\n", payload)
 
+	if in.DryRun {
+		dr, err := dryRun(ctx, mc, meta.Act(adAccount.AdAccountID)+"/campaigns", payload)
+		if err != nil {
+			return CreateCampaignOutput{}, err
+		}
+		return CreateCampaignOutput{DryRun: dr}, nil
+	}
+
 	campaignID, err := mc.CreateCampaign(ctx, adAccount.AdAccountID, payload)
 	if err != nil {
 		return CreateCampaignOutput{}, err
@@ -78,11 +120,15 @@ type ListCampaignsInput struct {
 }
 
 type CampaignItem struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Objective   string `json:"objective,omitempty"`
-	Status      string `json:"status,omitempty"`
-	CreatedTime string `json:"created_time,omitempty"`
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Objective      string `json:"objective,omitempty"`
+	Status         string `json:"status,omitempty"`
+	DailyBudget    string `json:"daily_budget,omitempty"`
+	LifetimeBudget string `json:"lifetime_budget,omitempty"`
+	SpendCap       string `json:"spend_cap,omitempty"`
+	BidStrategy    string `json:"bid_strategy,omitempty"`
+	CreatedTime    string `json:"created_time,omitempty"`
 }
 
 type ListCampaignsOutput struct {
@@ -90,24 +136,26 @@ type ListCampaignsOutput struct {
 }
 
 func (s *CampaignService) ListCampaigns(ctx context.Context, in ListCampaignsInput) (ListCampaignsOutput, error) {
-	if err := s.Sem.Acquire(ctx); err != nil {
-		return ListCampaignsOutput{}, err
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return ListCampaignsOutput{}, fmt.Errorf("get ad account: %w", err)
 	}
-	defer s.Sem.Release()
 
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
 	if err != nil {
-		return ListCampaignsOutput{}, fmt.Errorf("get ad account: %w", err)
+		return ListCampaignsOutput{}, err
 	}
+	defer lease.Release()
 
 	token, err := s.Tokens.Resolve(adAccount.TokenRef)
 	if err != nil {
 		return ListCampaignsOutput{}, fmt.Errorf("resolve token: %w", err)
 	}
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
 
-	fields := []string{"id", "name", "objective", "status", "created_time"}
+	fields := []string{"id", "name", "objective", "status", "daily_budget", "lifetime_budget", "spend_cap", "bid_strategy", "created_time"}
 	data, err := mc.ListCampaigns(ctx, adAccount.AdAccountID, fields)
 	if err != nil {
 		return ListCampaignsOutput{}, err
@@ -128,6 +176,18 @@ func (s *CampaignService) ListCampaigns(ctx context.Context, in ListCampaignsInp
 		if status, ok := item["status"].(string); ok {
 			c.Status = status
 		}
+		if db, ok := item["daily_budget"].(string); ok {
+			c.DailyBudget = db
+		}
+		if lb, ok := item["lifetime_budget"].(string); ok {
+			c.LifetimeBudget = lb
+		}
+		if sc, ok := item["spend_cap"].(string); ok {
+			c.SpendCap = sc
+		}
+		if bs, ok := item["bid_strategy"].(string); ok {
+			c.BidStrategy = bs
+		}
 		if ct, ok := item["created_time"].(string); ok {
 			c.CreatedTime = ct
 		}
@@ -144,25 +204,48 @@ type UpdateCampaignInput struct {
 	CampaignID  string
 	Name        *string // opcional
 	Status      *string // opcional (ACTIVE, PAUSED, DELETED)
+
+	DailyBudget    *int    // opcional
+	LifetimeBudget *int    // opcional
+	SpendCap       *int    // opcional
+	BidStrategy    *string // opcional
+
+	DryRun bool
 }
 
-func (s *CampaignService) UpdateCampaign(ctx context.Context, in UpdateCampaignInput) error {
-	if err := s.Sem.Acquire(ctx); err != nil {
-		return err
+// UpdateCampaign devolve o resultado do dry run quando in.DryRun; nil caso contrário
+func (s *CampaignService) UpdateCampaign(ctx context.Context, in UpdateCampaignInput) (*DryRunResult, error) {
+	if err := validateCampaignBudget(intValue(in.DailyBudget), intValue(in.LifetimeBudget), intValue(in.SpendCap), ""); err != nil {
+		return nil, err
+	}
+	if in.BidStrategy != nil {
+		if _, ok := bidStrategies[*in.BidStrategy]; !ok {
+			return nil, fmt.Errorf("invalid bid_strategy: %q", *in.BidStrategy)
+		}
 	}
-	defer s.Sem.Release()
 
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
 	if err != nil {
-		return fmt.Errorf("get ad account: %w", err)
+		return nil, fmt.Errorf("get ad account: %w", err)
 	}
 
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
+	if err != nil {
+		return nil, err
+	}
+	defer lease.Release()
+
 	token, err := s.Tokens.Resolve(adAccount.TokenRef)
 	if err != nil {
-		return fmt.Errorf("resolve token: %w", err)
+		return nil, fmt.Errorf("resolve token: %w", err)
 	}
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	if err := checkObjectsInAdAccount(ctx, mc, adAccount.AdAccountID, in.CampaignID); err != nil {
+		return nil, err
+	}
 
 	payload := map[string]any{}
 	if in.Name != nil {
@@ -171,12 +254,27 @@ func (s *CampaignService) UpdateCampaign(ctx context.Context, in UpdateCampaignI
 	if in.Status != nil {
 		payload["status"] = *in.Status
 	}
+	if in.DailyBudget != nil {
+		payload["daily_budget"] = *in.DailyBudget
+	}
+	if in.LifetimeBudget != nil {
+		payload["lifetime_budget"] = *in.LifetimeBudget
+	}
+	if in.SpendCap != nil {
+		payload["spend_cap"] = *in.SpendCap
+	}
+	if in.BidStrategy != nil {
+		payload["bid_strategy"] = *in.BidStrategy
+	}
 
 	if len(payload) == 0 {
-		return fmt.Errorf("no fields to update")
+		return nil, fmt.Errorf("no fields to update")
 	}
 
-	return mc.UpdateCampaign(ctx, in.CampaignID, payload)
+	if in.DryRun {
+		return dryRun(ctx, mc, in.CampaignID, payload)
+	}
+	return nil, mc.UpdateCampaign(ctx, in.CampaignID, payload)
 }
 
 // ======= DELETE Campaign (soft delete) =======
@@ -184,25 +282,39 @@ func (s *CampaignService) UpdateCampaign(ctx context.Context, in UpdateCampaignI
 type DeleteCampaignInput struct {
 	AdAccountID string // necessário para resolver token
 	CampaignID  string
+	DryRun      bool
 }
 
-func (s *CampaignService) DeleteCampaign(ctx context.Context, in DeleteCampaignInput) error {
-	if err := s.Sem.Acquire(ctx); err != nil {
-		return err
+func (s *CampaignService) DeleteCampaign(ctx context.Context, in DeleteCampaignInput) (*DryRunResult, error) {
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("get ad account: %w", err)
 	}
-	defer s.Sem.Release()
+	return s.deleteCampaign(ctx, adAccount, in)
+}
 
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+// deleteCampaign é o soft delete para quem já tem a ad account (rollback do launch)
+func (s *CampaignService) deleteCampaign(ctx context.Context, adAccount storage.AdAccount, in DeleteCampaignInput) (*DryRunResult, error) {
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
 	if err != nil {
-		return fmt.Errorf("get ad account: %w", err)
+		return nil, err
 	}
+	defer lease.Release()
 
 	token, err := s.Tokens.Resolve(adAccount.TokenRef)
 	if err != nil {
-		return fmt.Errorf("resolve token: %w", err)
+		return nil, fmt.Errorf("resolve token: %w", err)
 	}
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
 
-	return mc.SoftDeleteCampaign(ctx, in.CampaignID)
+	if err := checkObjectsInAdAccount(ctx, mc, adAccount.AdAccountID, in.CampaignID); err != nil {
+		return nil, err
+	}
+
+	if in.DryRun {
+		return dryRun(ctx, mc, in.CampaignID, map[string]any{"status": "DELETED"})
+	}
+	return nil, mc.SoftDeleteCampaign(ctx, in.CampaignID)
 }
@@ -0,0 +1,551 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/secrets"
+	"creative-service/internal/storage"
+)
+
+type CopyService struct {
+	Store  *storage.Store
+	Tokens secrets.Resolver
+
+	Meta *meta.ClientFactory
+
+	Sched *Scheduler
+}
+
+type CopyInput struct {
+	AdAccountID    string // ad account de origem
+	ObjectType     string // campaign, adset ou ad
+	ObjectID       string
+	DeepCopy       bool
+	StatusOption   string // ACTIVE, PAUSED ou INHERITED_FROM_SOURCE
+	RenameStrategy string // DEEP_RENAME, ONLY_TOP_LEVEL_RENAME ou NO_RENAME
+	RenamePrefix   string
+	RenameSuffix   string
+
+	// Novo pai da cópia: campaign_id para adsets, adset_id para ads (opcional)
+	TargetParentID string
+
+	// Ad account de destino (opcional, apenas campaigns). Se diferente da origem,
+	// a campaign e seus adsets são recriados na conta de destino.
+	TargetAdAccountID string
+
+	// Pixel da conta de destino para o promoted_object dos adsets (cópia entre
+	// ad accounts de adsets que otimizam para conversão)
+	TargetPixelID string
+
+	// Força a cópia via async_batch_requests (deep copies grandes)
+	Async bool
+}
+
+type CopyOutput struct {
+	Status       string              `json:"status"` // completed ou pending
+	CopiedID     string              `json:"copied_id,omitempty"`
+	AdAccountID  string              `json:"ad_account_id"`
+	Objects      []meta.CopiedObject `json:"objects,omitempty"`
+	Skipped      []meta.CopiedObject `json:"skipped,omitempty"`
+	RequestSetID string              `json:"request_set_id,omitempty"`
+	Warnings     []string            `json:"warnings,omitempty"`
+}
+
+type GetCopyInput struct {
+	AdAccountID  string
+	RequestSetID string
+}
+
+type GetCopyOutput struct {
+	RequestSetID string               `json:"request_set_id"`
+	Status       string               `json:"status"` // pending, completed ou failed
+	Set          meta.AsyncRequestSet `json:"set"`
+	Requests     []meta.AsyncRequest  `json:"requests"`
+}
+
+var copyStatusOptions = map[string]struct{}{
+	"ACTIVE":                {},
+	"PAUSED":                {},
+	"INHERITED_FROM_SOURCE": {},
+}
+
+var copyRenameStrategies = map[string]struct{}{
+	"DEEP_RENAME":           {},
+	"ONLY_TOP_LEVEL_RENAME": {},
+	"NO_RENAME":             {},
+}
+
+// Campos lidos da origem numa cópia entre ad accounts
+var (
+	copyCampaignFields = []string{
+		"name", "objective", "status", "special_ad_categories", "buying_type",
+		"bid_strategy", "daily_budget", "lifetime_budget", "spend_cap",
+	}
+	copyAdSetFields = []string{
+		"id", "name", "status", "billing_event", "optimization_goal", "bid_strategy",
+		"bid_amount", "daily_budget", "lifetime_budget", "targeting",
+		"start_time", "end_time", "promoted_object",
+	}
+)
+
+func (s *CopyService) Copy(ctx context.Context, in CopyInput) (CopyOutput, error) {
+	if err := validateCopyInput(in); err != nil {
+		return CopyOutput{}, err
+	}
+
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return CopyOutput{}, fmt.Errorf("get ad account: %w", err)
+	}
+
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityBulk))
+	if err != nil {
+		return CopyOutput{}, err
+	}
+	defer lease.Release()
+
+	token, err := s.Tokens.Resolve(adAccount.TokenRef)
+	if err != nil {
+		return CopyOutput{}, fmt.Errorf("resolve token: %w", err)
+	}
+
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	// Origem e pai de destino precisam ser da conta informada, não só visíveis pelo token
+	ids := []string{in.ObjectID}
+	if in.TargetParentID != "" {
+		ids = append(ids, in.TargetParentID)
+	}
+	if err := checkObjectsInAdAccount(ctx, mc, adAccount.AdAccountID, ids...); err != nil {
+		return CopyOutput{}, err
+	}
+
+	if in.TargetAdAccountID != "" && !sameAdAccount(in.TargetAdAccountID, adAccount.AdAccountID) {
+		target, err := getAdAccount(ctx, s.Store, in.TargetAdAccountID)
+		if err != nil {
+			return CopyOutput{}, fmt.Errorf("get target ad account: %w", err)
+		}
+		return s.copyCampaignAcrossAccounts(ctx, mc, lease, target, in)
+	}
+
+	payload := copyPayload(in)
+
+	if in.Async {
+		return s.copyAsync(ctx, mc, adAccount.AdAccountID, in, payload)
+	}
+
+	var resp meta.CopyResponse
+	var copiedID string
+	switch in.ObjectType {
+	case "campaign":
+		resp, err = mc.CopyCampaign(ctx, in.ObjectID, payload)
+		copiedID = resp.CopiedCampaignID
+	case "adset":
+		resp, err = mc.CopyAdSet(ctx, in.ObjectID, payload)
+		copiedID = resp.CopiedAdSetID
+	case "ad":
+		resp, err = mc.CopyAd(ctx, in.ObjectID, payload)
+		copiedID = resp.CopiedAdID
+	}
+	if meta.IsAsyncCopyRequired(err) {
+		// Deep copy grande demais para o /copies síncrono: a Meta não criou nada,
+		// então a mesma cópia vai pelo batch assíncrono
+		return s.copyAsync(ctx, mc, adAccount.AdAccountID, in, payload)
+	}
+	if err != nil {
+		return CopyOutput{}, err
+	}
+
+	return CopyOutput{
+		Status:      "completed",
+		CopiedID:    copiedID,
+		AdAccountID: adAccount.AdAccountID,
+		Objects:     resp.AdObjectIDs,
+	}, nil
+}
+
+// copyAsync envia a cópia como request de act_{id}/async_batch_requests
+func (s *CopyService) copyAsync(ctx context.Context, mc *meta.Client, adAccountID string, in CopyInput, payload map[string]any) (CopyOutput, error) {
+	// O body de cada request do batch vai url-encoded; valores não-string em JSON
+	body := url.Values{}
+	for k, v := range payload {
+		if str, ok := v.(string); ok {
+			body.Set(k, str)
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return CopyOutput{}, fmt.Errorf("encode %s: %w", k, err)
+		}
+		body.Set(k, string(b))
+	}
+
+	setID, err := mc.CreateAsyncBatch(ctx, adAccountID, fmt.Sprintf("copy %s %s", in.ObjectType, in.ObjectID), []meta.AsyncBatchRequest{{
+		Name:        fmt.Sprintf("copy_%s_%s", in.ObjectType, in.ObjectID),
+		RelativeURL: fmt.Sprintf("%s/copies", in.ObjectID),
+		Body:        body.Encode(),
+	}})
+	if err != nil {
+		return CopyOutput{}, err
+	}
+	return CopyOutput{Status: "pending", AdAccountID: adAccountID, RequestSetID: setID}, nil
+}
+
+// GetCopy consulta o andamento de uma cópia assíncrona
+func (s *CopyService) GetCopy(ctx context.Context, in GetCopyInput) (GetCopyOutput, error) {
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return GetCopyOutput{}, fmt.Errorf("get ad account: %w", err)
+	}
+
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
+	if err != nil {
+		return GetCopyOutput{}, err
+	}
+	defer lease.Release()
+
+	token, err := s.Tokens.Resolve(adAccount.TokenRef)
+	if err != nil {
+		return GetCopyOutput{}, fmt.Errorf("resolve token: %w", err)
+	}
+
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	set, err := mc.GetAsyncRequestSet(ctx, in.RequestSetID)
+	if err != nil {
+		return GetCopyOutput{}, err
+	}
+
+	requests, err := mc.ListAsyncRequests(ctx, in.RequestSetID)
+	if err != nil {
+		return GetCopyOutput{}, err
+	}
+
+	status := "pending"
+	if set.IsCompleted {
+		status = "completed"
+		if set.ErrorCount > 0 && set.SuccessCount == 0 {
+			status = "failed"
+		}
+	}
+
+	return GetCopyOutput{RequestSetID: in.RequestSetID, Status: status, Set: set, Requests: requests}, nil
+}
+
+// copyCampaignAcrossAccounts recria a campaign (e, com deep_copy, seus adsets)
+// na ad account de destino. O edge /copies da Meta só copia dentro da mesma conta.
+// Ads não são copiados: os creatives (image_hash, video_id) pertencem à conta
+// de origem, então eles voltam em Skipped para serem recriados manualmente.
+// A origem inteira é lida antes de criar qualquer coisa no destino, para recusar a
+// cópia sem rollback quando um ID da conta de origem não pode ser remapeado.
+// srcLease (da conta de origem) é liberada antes de pedir a vaga do destino:
+// segurar as duas ao mesmo tempo pode travar o scheduler entre duas cópias cruzadas.
+func (s *CopyService) copyCampaignAcrossAccounts(ctx context.Context, src *meta.Client, srcLease *Lease, target storage.AdAccount, in CopyInput) (CopyOutput, error) {
+	campaign, err := src.GetObject(ctx, in.ObjectID, copyCampaignFields)
+	if err != nil {
+		return CopyOutput{}, fmt.Errorf("get source campaign: %w", err)
+	}
+
+	var adsets []map[string]any
+	var warnings []string
+	if in.DeepCopy {
+		adsets, err = src.ListCampaignAdSets(ctx, in.ObjectID, copyAdSetFields)
+		if err != nil {
+			return CopyOutput{}, fmt.Errorf("list source adsets: %w", err)
+		}
+		for i, as := range adsets {
+			p, notes, err := portAdSet(as, in.TargetPixelID)
+			if err != nil {
+				return CopyOutput{}, fmt.Errorf("adset %s: %w", stringField(as, "id"), err)
+			}
+			adsets[i] = p
+			warnings = append(warnings, notes...)
+		}
+	}
+
+	var skipped []meta.CopiedObject
+	if in.DeepCopy {
+		ads, err := src.ListCampaignAds(ctx, in.ObjectID, []string{"id"})
+		if err != nil {
+			return CopyOutput{}, fmt.Errorf("list source ads: %w", err)
+		}
+		for _, ad := range ads {
+			skipped = append(skipped, meta.CopiedObject{AdObjectType: "ad", SourceID: stringField(ad, "id")})
+		}
+	}
+	srcLease.Release()
+
+	lease, err := s.Sched.Acquire(ctx, accountSlot(target, PriorityBulk))
+	if err != nil {
+		return CopyOutput{}, err
+	}
+	defer lease.Release()
+
+	targetToken, err := s.Tokens.Resolve(target.TokenRef)
+	if err != nil {
+		return CopyOutput{}, fmt.Errorf("resolve target token: %w", err)
+	}
+
+	dst := s.Meta.Client(targetToken)
+	dstCtx := meta.WithAdAccount(ctx, target.AdAccountID)
+
+	payload := pickFields(campaign, copyCampaignFields)
+	payload["name"] = copyName(in, stringField(campaign, "name"), true)
+	payload["status"] = copyStatus(in, stringField(campaign, "status"))
+	if _, ok := payload["special_ad_categories"]; !ok {
+		payload["special_ad_categories"] = []string{}
+	}
+
+	newCampaignID, err := dst.CreateCampaign(dstCtx, target.AdAccountID, payload)
+	if err != nil {
+		return CopyOutput{}, fmt.Errorf("create campaign in target: %w", err)
+	}
+
+	out := CopyOutput{
+		Status:      "completed",
+		CopiedID:    newCampaignID,
+		AdAccountID: target.AdAccountID,
+		Objects:     []meta.CopiedObject{{AdObjectType: "campaign", SourceID: in.ObjectID, CopiedID: newCampaignID}},
+		Warnings:    warnings,
+	}
+	if !in.DeepCopy {
+		return out, nil
+	}
+
+	for _, as := range adsets {
+		p := pickFields(as, copyAdSetFields)
+		delete(p, "id")
+		p["campaign_id"] = newCampaignID
+		p["name"] = copyName(in, stringField(as, "name"), false)
+		p["status"] = copyStatus(in, stringField(as, "status"))
+
+		newID, err := dst.CreateAdSet(dstCtx, target.AdAccountID, p)
+		if err != nil {
+			return CopyOutput{}, errors.Join(
+				fmt.Errorf("create adset %s in target: %w", stringField(as, "id"), err),
+				rollbackCopy(dstCtx, dst, out.Objects),
+			)
+		}
+		out.Objects = append(out.Objects, meta.CopiedObject{AdObjectType: "adset", SourceID: stringField(as, "id"), CopiedID: newID})
+	}
+	out.Skipped = skipped
+
+	return out, nil
+}
+
+// portAdSet prepara um adset da conta de origem para ser recriado em outra conta.
+// Custom audiences (inclusive lookalikes) pertencem à conta de origem e saem do
+// targeting, com um aviso; o pixel do promoted_object é trocado por targetPixelID.
+// Custom conversions não têm equivalente no destino e recusam a cópia.
+func portAdSet(as map[string]any, targetPixelID string) (map[string]any, []string, error) {
+	id := stringField(as, "id")
+	out := cloneMap(as)
+	var notes []string
+
+	if t, ok := as["targeting"].(map[string]any); ok {
+		t = cloneMap(t)
+		for _, k := range []string{"custom_audiences", "excluded_custom_audiences"} {
+			if _, ok := t[k]; ok {
+				delete(t, k)
+				notes = append(notes, fmt.Sprintf("adset %s: %s removed (account-scoped)", id, k))
+			}
+		}
+		if blocks, ok := t["flexible_spec"].([]any); ok {
+			kept := make([]any, 0, len(blocks))
+			removed := false
+			for _, b := range blocks {
+				block, ok := b.(map[string]any)
+				if !ok {
+					kept = append(kept, b)
+					continue
+				}
+				if _, ok := block["custom_audiences"]; ok {
+					block = cloneMap(block)
+					delete(block, "custom_audiences")
+					removed = true
+				}
+				if len(block) > 0 {
+					kept = append(kept, block)
+				}
+			}
+			if removed {
+				notes = append(notes, fmt.Sprintf("adset %s: flexible_spec custom_audiences removed (account-scoped)", id))
+			}
+			if len(kept) > 0 {
+				t["flexible_spec"] = kept
+			} else {
+				delete(t, "flexible_spec")
+			}
+		}
+		out["targeting"] = t
+	}
+
+	if po, ok := as["promoted_object"].(map[string]any); ok {
+		if _, ok := po["custom_conversion_id"]; ok {
+			return nil, nil, fmt.Errorf("promoted_object uses a custom conversion, which cannot be copied across ad accounts")
+		}
+		if pixelID := stringField(po, "pixel_id"); pixelID != "" {
+			if targetPixelID == "" {
+				return nil, nil, fmt.Errorf("promoted_object uses pixel %s: target_pixel_id is required", pixelID)
+			}
+			po = cloneMap(po)
+			po["pixel_id"] = targetPixelID
+			out["promoted_object"] = po
+		}
+	}
+	return out, notes, nil
+}
+
+func cloneMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// rollbackCopy remove (soft delete) o que já foi criado no destino, na ordem inversa
+func rollbackCopy(ctx context.Context, mc *meta.Client, objects []meta.CopiedObject) error {
+	ctx = context.WithoutCancel(ctx)
+	var errs []error
+	for i := len(objects) - 1; i >= 0; i-- {
+		obj := objects[i]
+		var err error
+		switch obj.AdObjectType {
+		case "campaign":
+			err = mc.SoftDeleteCampaign(ctx, obj.CopiedID)
+		case "adset":
+			err = mc.SoftDeleteAdSet(ctx, obj.CopiedID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rollback %s %s: %w", obj.AdObjectType, obj.CopiedID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func copyPayload(in CopyInput) map[string]any {
+	payload := map[string]any{"deep_copy": in.DeepCopy}
+	if in.StatusOption != "" {
+		payload["status_option"] = in.StatusOption
+	}
+
+	if in.RenameStrategy != "" || in.RenamePrefix != "" || in.RenameSuffix != "" {
+		ro := map[string]any{}
+		if in.RenameStrategy != "" {
+			ro["rename_strategy"] = in.RenameStrategy
+		}
+		if in.RenamePrefix != "" {
+			ro["rename_prefix"] = in.RenamePrefix
+		}
+		if in.RenameSuffix != "" {
+			ro["rename_suffix"] = in.RenameSuffix
+		}
+		payload["rename_options"] = ro
+	}
+
+	if in.TargetParentID != "" {
+		switch in.ObjectType {
+		case "adset":
+			payload["campaign_id"] = in.TargetParentID
+		case "ad":
+			payload["adset_id"] = in.TargetParentID
+		}
+	}
+	return payload
+}
+
+// copyName aplica rename_options do mesmo jeito que a Meta faz no /copies
+func copyName(in CopyInput, name string, topLevel bool) string {
+	switch in.RenameStrategy {
+	case "NO_RENAME":
+		return name
+	case "ONLY_TOP_LEVEL_RENAME":
+		if !topLevel {
+			return name
+		}
+	}
+	return in.RenamePrefix + name + in.RenameSuffix
+}
+
+func copyStatus(in CopyInput, source string) string {
+	switch in.StatusOption {
+	case "ACTIVE", "PAUSED":
+		return in.StatusOption
+	case "INHERITED_FROM_SOURCE":
+		if source == "ACTIVE" || source == "PAUSED" {
+			return source
+		}
+	}
+	return "PAUSED"
+}
+
+func validateCopyInput(in CopyInput) error {
+	switch in.ObjectType {
+	case "campaign", "adset", "ad":
+	default:
+		return fmt.Errorf("invalid object type: %q", in.ObjectType)
+	}
+	if in.ObjectID == "" {
+		return fmt.Errorf("missing object id")
+	}
+	if in.StatusOption != "" {
+		if _, ok := copyStatusOptions[in.StatusOption]; !ok {
+			return fmt.Errorf("invalid status_option: %q", in.StatusOption)
+		}
+	}
+	if in.RenameStrategy != "" {
+		if _, ok := copyRenameStrategies[in.RenameStrategy]; !ok {
+			return fmt.Errorf("invalid rename_strategy: %q", in.RenameStrategy)
+		}
+	}
+	if in.TargetParentID != "" && in.ObjectType == "campaign" {
+		return fmt.Errorf("target parent is only valid for adsets and ads")
+	}
+	if in.TargetAdAccountID != "" && !sameAdAccount(in.TargetAdAccountID, in.AdAccountID) {
+		if in.ObjectType != "campaign" {
+			return fmt.Errorf("cross-account copy is only supported for campaigns")
+		}
+		if in.Async {
+			return fmt.Errorf("async copy is not supported across ad accounts")
+		}
+	} else if in.TargetPixelID != "" {
+		return fmt.Errorf("target_pixel_id is only valid for cross-account copies")
+	}
+	return nil
+}
+
+// sameAdAccount compara IDs de ad account com ou sem o prefixo act_
+func sameAdAccount(a, b string) bool {
+	return meta.Act(a) == meta.Act(b)
+}
+
+func pickFields(src map[string]any, fields []string) map[string]any {
+	out := map[string]any{}
+	for _, f := range fields {
+		if v, ok := src[f]; ok && v != nil {
+			out[f] = v
+		}
+	}
+	return out
+}
+
+func stringField(m map[string]any, k string) string {
+	switch v := m[k].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"creative-service/internal/meta/metatest"
+	"creative-service/internal/storage"
+)
+
+func TestValidateCopyInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      CopyInput
+		wantErr string
+	}{
+		{name: "same account copy", in: CopyInput{AdAccountID: "act_1", ObjectType: "adset", ObjectID: "1"}},
+		{name: "invalid type", in: CopyInput{ObjectType: "creative", ObjectID: "1"}, wantErr: "invalid object type"},
+		{name: "missing id", in: CopyInput{ObjectType: "ad"}, wantErr: "missing object id"},
+		{name: "invalid status option", in: CopyInput{ObjectType: "ad", ObjectID: "1", StatusOption: "DELETED"}, wantErr: "invalid status_option"},
+		{name: "campaign with target parent", in: CopyInput{ObjectType: "campaign", ObjectID: "1", TargetParentID: "2"}, wantErr: "target parent"},
+		{
+			name:    "cross-account adset",
+			in:      CopyInput{AdAccountID: "act_1", TargetAdAccountID: "act_2", ObjectType: "adset", ObjectID: "1"},
+			wantErr: "only supported for campaigns",
+		},
+		{
+			name:    "cross-account async",
+			in:      CopyInput{AdAccountID: "act_1", TargetAdAccountID: "act_2", ObjectType: "campaign", ObjectID: "1", Async: true},
+			wantErr: "async copy is not supported",
+		},
+		{
+			// act_1 e 1 são a mesma conta: não é cópia entre contas
+			name: "target without act prefix is the same account",
+			in:   CopyInput{AdAccountID: "act_1", TargetAdAccountID: "1", ObjectType: "adset", ObjectID: "1"},
+		},
+		{
+			name:    "pixel without cross-account",
+			in:      CopyInput{AdAccountID: "act_1", TargetAdAccountID: "1", ObjectType: "campaign", ObjectID: "1", TargetPixelID: "9"},
+			wantErr: "target_pixel_id is only valid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErr(t, validateCopyInput(tt.in), tt.wantErr)
+		})
+	}
+}
+
+func TestCopyCampaignAcrossAccounts(t *testing.T) {
+	// Mais filhos que uma página da Meta (25), para cobrir a paginação
+	const children = 30
+
+	tests := []struct {
+		name        string
+		deepCopy    bool
+		failAds     bool
+		wantErr     string
+		wantObjects int
+		wantSkipped int
+	}{
+		{name: "shallow", wantObjects: 1},
+		{name: "deep copy reads every page", deepCopy: true, wantObjects: 1 + children, wantSkipped: children},
+		{name: "ads listing fails before creating", deepCopy: true, failAds: true, wantErr: "list source ads"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := metatest.NewServer()
+			defer srv.Close()
+			campaignID := srv.AddObject(metatest.TypeCampaign, "act_1", map[string]any{"name": "c", "objective": "OUTCOME_TRAFFIC", "status": "ACTIVE"})
+			for i := range children {
+				adsetID := srv.AddObject(metatest.TypeAdSet, "act_1", map[string]any{"name": fmt.Sprintf("as%d", i), "campaign_id": campaignID})
+				srv.AddObject(metatest.TypeAd, "act_1", map[string]any{"name": fmt.Sprintf("ad%d", i), "campaign_id": campaignID, "adset_id": adsetID})
+			}
+			if tt.failAds {
+				srv.Inject(metatest.Fault{Method: "GET", Path: "ads", Error: metatest.ErrInvalidParameter})
+			}
+
+			// Uma vaga só: se a vaga da origem não for liberada, a do destino nunca sai
+			sched := NewScheduler(1, 0, 0)
+			s := &CopyService{Tokens: staticTokens("token"), Meta: srv.ClientFactory(), Sched: sched}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			source := storage.AdAccount{AdAccountID: "act_1", TokenRef: "ENV:SOURCE"}
+			srcLease, err := sched.Acquire(ctx, accountSlot(source, PriorityBulk))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer srcLease.Release()
+
+			in := CopyInput{AdAccountID: "act_1", TargetAdAccountID: "act_2", ObjectType: "campaign", ObjectID: campaignID, DeepCopy: tt.deepCopy}
+			target := storage.AdAccount{AdAccountID: "act_2", TokenRef: "ENV:TARGET"}
+			out, err := s.copyCampaignAcrossAccounts(ctx, srv.Client("token"), srcLease, target, in)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr != "" {
+				if n := len(srv.Objects(metatest.TypeCampaign, "act_2")); n != 0 {
+					t.Errorf("target campaigns = %d, want 0", n)
+				}
+				return
+			}
+
+			if len(out.Objects) != tt.wantObjects || len(out.Skipped) != tt.wantSkipped {
+				t.Errorf("objects = %d skipped = %d, want %d and %d", len(out.Objects), len(out.Skipped), tt.wantObjects, tt.wantSkipped)
+			}
+			if n := len(srv.Objects(metatest.TypeAdSet, "act_2")); n != tt.wantObjects-1 {
+				t.Errorf("target adsets = %d, want %d", n, tt.wantObjects-1)
+			}
+			if out.AdAccountID != "act_2" {
+				t.Errorf("ad account = %s, want act_2", out.AdAccountID)
+			}
+		})
+	}
+}
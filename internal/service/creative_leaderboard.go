@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/storage"
+)
+
+// ======= CREATIVE LEADERBOARD =======
+// Junta insights por ad com o creative de cada ad (AdItem.Creative) e agrega
+// por creatives.creative_id. Ads cujo creative não foi criado por este serviço
+// entram só no total de unmanaged.
+
+const (
+	defaultLeaderboardPreset = "last_30d"
+	defaultLeaderboardLimit  = 50
+	maxLeaderboardLimit      = 500
+)
+
+var leaderboardInsightsFields = []string{"ad_id", "spend", "impressions", "clicks", "actions", "action_values"}
+
+// Métricas de ordenação; cpa é o único em que menor é melhor
+var leaderboardMetrics = map[string]bool{
+	"spend": false, "impressions": false, "clicks": false, "conversions": false,
+	"ctr": false, "roas": false, "cpa": true,
+}
+
+type CreativeLeaderboardInput struct {
+	AdAccountID string
+	Since       string // YYYY-MM-DD (junto com Until)
+	Until       string
+	DatePreset  string // default last_30d quando não há Since/Until
+	Metric      string // default spend
+	ActionType  string // conversão usada em CPA/ROAS; default purchase
+	Limit       int
+}
+
+type CreativePerformance struct {
+	CreativeID      string   `json:"creative_id"`
+	Name            string   `json:"name"`
+	Type            string   `json:"type"`
+	URL             string   `json:"url"`
+	ThumbURL        *string  `json:"thumb_url,omitempty"`
+	Ads             int      `json:"ads"`
+	Spend           float64  `json:"spend"`
+	Impressions     int64    `json:"impressions"`
+	Clicks          int64    `json:"clicks"`
+	Conversions     float64  `json:"conversions"`
+	ConversionValue float64  `json:"conversion_value"`
+	CTR             *float64 `json:"ctr"`  // % (clicks / impressions)
+	CPA             *float64 `json:"cpa"`  // spend / conversions
+	ROAS            *float64 `json:"roas"` // conversion_value / spend
+}
+
+type CreativeLeaderboardOutput struct {
+	Metric         string                `json:"metric"`
+	ActionType     string                `json:"action_type"`
+	DatePreset     string                `json:"date_preset,omitempty"`
+	Since          string                `json:"since,omitempty"`
+	Until          string                `json:"until,omitempty"`
+	Count          int                   `json:"count"`
+	Creatives      []CreativePerformance `json:"creatives"`
+	UnmanagedAds   int                   `json:"unmanaged_ads"` // ads com spend cujo creative não está em creatives
+	UnmanagedSpend float64               `json:"unmanaged_spend"`
+}
+
+func (s *InsightsService) CreativeLeaderboard(ctx context.Context, in CreativeLeaderboardInput) (CreativeLeaderboardOutput, error) {
+	if in.Metric == "" {
+		in.Metric = "spend"
+	}
+	if _, ok := leaderboardMetrics[in.Metric]; !ok {
+		return CreativeLeaderboardOutput{}, fmt.Errorf("invalid metric: %q (use spend, impressions, clicks, conversions, ctr, cpa or roas)", in.Metric)
+	}
+	if in.Limit <= 0 {
+		in.Limit = defaultLeaderboardLimit
+	}
+	if in.Limit > maxLeaderboardLimit {
+		in.Limit = maxLeaderboardLimit
+	}
+
+	conversionTypes := purchaseActionTypes
+	if in.ActionType != "" {
+		conversionTypes = []string{in.ActionType}
+	} else {
+		in.ActionType = "purchase"
+	}
+
+	insIn := InsightsInput{
+		AdAccountID: in.AdAccountID,
+		Level:       "ad",
+		Fields:      leaderboardInsightsFields,
+		DatePreset:  in.DatePreset,
+		Since:       in.Since,
+		Until:       in.Until,
+	}
+	if insIn.DatePreset == "" && insIn.Since == "" && insIn.Until == "" {
+		insIn.DatePreset = defaultLeaderboardPreset
+	}
+	params, err := insightsParams(&insIn)
+	if err != nil {
+		return CreativeLeaderboardOutput{}, err
+	}
+
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return CreativeLeaderboardOutput{}, fmt.Errorf("get ad account: %w", err)
+	}
+
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
+	if err != nil {
+		return CreativeLeaderboardOutput{}, err
+	}
+	defer lease.Release()
+
+	token, err := s.Tokens.Resolve(adAccount.TokenRef)
+	if err != nil {
+		return CreativeLeaderboardOutput{}, fmt.Errorf("resolve token: %w", err)
+	}
+
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	rows, err := mc.GetInsights(ctx, meta.Act(adAccount.AdAccountID), params)
+	if err != nil {
+		return CreativeLeaderboardOutput{}, fmt.Errorf("get insights: %w", err)
+	}
+
+	adIDs := make([]string, 0, len(rows))
+	for _, r := range rows {
+		if id := stringField(r, "ad_id"); id != "" {
+			adIDs = append(adIDs, id)
+		}
+	}
+
+	// Busca por ID (e não /ads) para incluir ads já deletados ou arquivados
+	ads, err := mc.GetObjects(ctx, adIDs, []string{"id", "creative{id,name}"})
+	if err != nil {
+		return CreativeLeaderboardOutput{}, fmt.Errorf("get ads: %w", err)
+	}
+
+	adCreative := make(map[string]string, len(ads))
+	creativeIDs := []string{}
+	seen := map[string]struct{}{}
+	for id, ad := range ads {
+		creative, _ := ad["creative"].(map[string]any) // mesmo formato de AdItem.Creative
+		creativeID := stringField(creative, "id")
+		if creativeID == "" {
+			continue
+		}
+		adCreative[id] = creativeID
+		if _, ok := seen[creativeID]; !ok {
+			seen[creativeID] = struct{}{}
+			creativeIDs = append(creativeIDs, creativeID)
+		}
+	}
+
+	local, err := s.Store.GetCreativesByIDs(ctx, adAccount.AdAccountID, creativeIDs)
+	if err != nil {
+		return CreativeLeaderboardOutput{}, fmt.Errorf("get creatives: %w", err)
+	}
+
+	out := CreativeLeaderboardOutput{
+		Metric:     in.Metric,
+		ActionType: in.ActionType,
+		DatePreset: insIn.DatePreset,
+		Since:      insIn.Since,
+		Until:      insIn.Until,
+	}
+
+	out.Creatives, out.UnmanagedAds, out.UnmanagedSpend = aggregateCreatives(rows, adCreative, local, conversionTypes)
+	sortLeaderboard(out.Creatives, in.Metric)
+
+	if len(out.Creatives) > in.Limit {
+		out.Creatives = out.Creatives[:in.Limit]
+	}
+	out.Count = len(out.Creatives)
+	return out, nil
+}
+
+// aggregateCreatives soma as linhas de insights (uma por ad) por creative e calcula
+// CTR, CPA e ROAS do total. Ads sem creative em local entram em unmanaged.
+func aggregateCreatives(rows []map[string]any, adCreative map[string]string, local map[string]storage.Creative, conversionTypes []string) ([]CreativePerformance, int, float64) {
+	var unmanagedAds int
+	var unmanagedSpend float64
+	byCreative := map[string]*CreativePerformance{}
+	for _, r := range rows {
+		spend := floatField(r, "spend")
+		c, ok := local[adCreative[stringField(r, "ad_id")]]
+		if !ok {
+			if spend > 0 {
+				unmanagedAds++
+				unmanagedSpend += spend
+			}
+			continue
+		}
+
+		p := byCreative[c.CreativeID]
+		if p == nil {
+			p = &CreativePerformance{CreativeID: c.CreativeID, Name: c.Name, Type: c.Type, URL: c.URL, ThumbURL: c.ThumbURL}
+			byCreative[c.CreativeID] = p
+		}
+		p.Ads++
+		p.Spend += spend
+		p.Impressions += int64(floatField(r, "impressions"))
+		p.Clicks += int64(floatField(r, "clicks"))
+		p.Conversions += actionValue(r["actions"], conversionTypes)
+		p.ConversionValue += actionValue(r["action_values"], conversionTypes)
+	}
+
+	creatives := make([]CreativePerformance, 0, len(byCreative))
+	for _, p := range byCreative {
+		p.Spend, p.ConversionValue = round(p.Spend, 2), round(p.ConversionValue, 2)
+		if p.Impressions > 0 {
+			p.CTR = ratio(float64(p.Clicks)*100, float64(p.Impressions), 4)
+		}
+		if p.Conversions > 0 {
+			p.CPA = ratio(p.Spend, p.Conversions, 2)
+		}
+		if p.Spend > 0 {
+			p.ROAS = ratio(p.ConversionValue, p.Spend, 4)
+		}
+		creatives = append(creatives, *p)
+	}
+	return creatives, unmanagedAds, round(unmanagedSpend, 2)
+}
+
+// sortLeaderboard ordena pela métrica (cpa crescente, as demais decrescentes);
+// empates e creatives sem valor para a métrica seguem o spend
+func sortLeaderboard(creatives []CreativePerformance, metric string) {
+	lowerIsBetter := leaderboardMetrics[metric]
+	sort.SliceStable(creatives, func(i, j int) bool {
+		a, aok := leaderboardValue(creatives[i], metric)
+		b, bok := leaderboardValue(creatives[j], metric)
+		switch {
+		case aok != bok:
+			return aok // sem valor (ex: CPA sem conversões) vai para o fim
+		case a != b:
+			if lowerIsBetter {
+				return a < b
+			}
+			return a > b
+		}
+		return creatives[i].Spend > creatives[j].Spend
+	})
+}
+
+func leaderboardValue(p CreativePerformance, metric string) (float64, bool) {
+	deref := func(v *float64) (float64, bool) {
+		if v == nil {
+			return 0, false
+		}
+		return *v, true
+	}
+	switch metric {
+	case "impressions":
+		return float64(p.Impressions), true
+	case "clicks":
+		return float64(p.Clicks), true
+	case "conversions":
+		return p.Conversions, true
+	case "ctr":
+		return deref(p.CTR)
+	case "cpa":
+		return deref(p.CPA)
+	case "roas":
+		return deref(p.ROAS)
+	}
+	return p.Spend, true
+}
+
+func ratio(a, b float64, decimals int) *float64 {
+	v := round(a/b, decimals)
+	return &v
+}
+
+func round(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
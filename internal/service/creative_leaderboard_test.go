@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+
+	"creative-service/internal/storage"
+)
+
+// leaderboardRows: três ads de dois creatives gerenciados e um ad de creative externo
+func leaderboardRows() ([]map[string]any, map[string]string, map[string]storage.Creative) {
+	purchase := func(n, value string) (any, any) {
+		return []any{map[string]any{"action_type": "purchase", "value": n}},
+			[]any{map[string]any{"action_type": "purchase", "value": value}}
+	}
+	a1, v1 := purchase("2", "150")
+	a2, v2 := purchase("1", "30")
+	rows := []map[string]any{
+		{"ad_id": "1", "spend": "50.4", "impressions": "1000", "clicks": "10", "actions": a1, "action_values": v1},
+		{"ad_id": "2", "spend": "50", "impressions": "1000", "clicks": "30"},
+		{"ad_id": "3", "spend": "20", "impressions": "4000", "clicks": "40", "actions": a2, "action_values": v2},
+		{"ad_id": "4", "spend": "7.5", "impressions": "100", "clicks": "1"},
+		{"ad_id": "5", "spend": "0", "impressions": "0", "clicks": "0"},
+	}
+	adCreative := map[string]string{"1": "c1", "2": "c1", "3": "c2", "4": "external"}
+	local := map[string]storage.Creative{
+		"c1": {CreativeID: "c1", Name: "one", Type: "image"},
+		"c2": {CreativeID: "c2", Name: "two", Type: "video"},
+	}
+	return rows, adCreative, local
+}
+
+func TestAggregateCreatives(t *testing.T) {
+	rows, adCreative, local := leaderboardRows()
+	creatives, unmanagedAds, unmanagedSpend := aggregateCreatives(rows, adCreative, local, purchaseActionTypes)
+
+	// Ads sem spend não contam como unmanaged
+	if unmanagedAds != 1 || unmanagedSpend != 7.5 {
+		t.Errorf("unmanaged = %d ads %v spend, want 1 and 7.5", unmanagedAds, unmanagedSpend)
+	}
+	if len(creatives) != 2 {
+		t.Fatalf("creatives = %d, want 2", len(creatives))
+	}
+	sortLeaderboard(creatives, "spend")
+
+	ptr := func(v float64) *float64 { return &v }
+	tests := []struct {
+		got, want CreativePerformance
+	}{
+		{got: creatives[0], want: CreativePerformance{CreativeID: "c1", Ads: 2, Spend: 100.4, Impressions: 2000, Clicks: 40, Conversions: 2, ConversionValue: 150, CTR: ptr(2), CPA: ptr(50.2), ROAS: ptr(1.494)}},
+		{got: creatives[1], want: CreativePerformance{CreativeID: "c2", Ads: 1, Spend: 20, Impressions: 4000, Clicks: 40, Conversions: 1, ConversionValue: 30, CTR: ptr(1), CPA: ptr(20), ROAS: ptr(1.5)}},
+	}
+	for _, tt := range tests {
+		g, w := tt.got, tt.want
+		if g.CreativeID != w.CreativeID || g.Ads != w.Ads || g.Spend != w.Spend || g.Impressions != w.Impressions ||
+			g.Clicks != w.Clicks || g.Conversions != w.Conversions || g.ConversionValue != w.ConversionValue {
+			t.Errorf("%s = %+v, want %+v", w.CreativeID, g, w)
+		}
+		for name, pair := range map[string][2]*float64{"ctr": {g.CTR, w.CTR}, "cpa": {g.CPA, w.CPA}, "roas": {g.ROAS, w.ROAS}} {
+			if pair[0] == nil || *pair[0] != *pair[1] {
+				t.Errorf("%s %s = %v, want %v", w.CreativeID, name, pair[0], *pair[1])
+			}
+		}
+	}
+}
+
+func TestSortLeaderboard(t *testing.T) {
+	ptr := func(v float64) *float64 { return &v }
+	creatives := []CreativePerformance{
+		{CreativeID: "a", Spend: 10, Impressions: 500, CPA: ptr(5), ROAS: ptr(2)},
+		{CreativeID: "b", Spend: 30, Impressions: 100, ROAS: ptr(0)}, // sem conversões: sem CPA
+		{CreativeID: "c", Spend: 20, Impressions: 500, CPA: ptr(2), ROAS: ptr(3)},
+		{CreativeID: "d", Spend: 5, Impressions: 50},
+	}
+
+	tests := []struct {
+		metric string
+		want   string
+	}{
+		{metric: "spend", want: "bcad"},
+		{metric: "impressions", want: "cabd"}, // empate em impressions desempata por spend
+		{metric: "cpa", want: "cabd"},         // menor primeiro; sem CPA no fim, por spend
+		{metric: "roas", want: "cabd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			sorted := append([]CreativePerformance(nil), creatives...)
+			sortLeaderboard(sorted, tt.metric)
+			got := ""
+			for _, c := range sorted {
+				got += c.CreativeID
+			}
+			if got != tt.want {
+				t.Errorf("order = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
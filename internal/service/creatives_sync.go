@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"fmt"
-	"time"
 	"bytes"
 	"encoding/json"
 
@@ -20,11 +19,9 @@ type CreativeSyncService struct {
 	Tokens secrets.Resolver
 	S3 *s3.Client
 
-	BaseURL     string
-	APIVersion  string
-	HTTPTimeout time.Duration
+	Meta *meta.ClientFactory
 
-	Sem *Semaphore
+	Sched *Scheduler
 }
 
 type ImageCreativeInput struct {
@@ -72,13 +69,15 @@ type VideoCreativeOutput struct {
 }
 
 func (s *CreativeSyncService) CreateImageCreative(ctx context.Context, in ImageCreativeInput) (ImageCreativeOutput, error) {
-	if err := s.Sem.Acquire(ctx); err != nil { return ImageCreativeOutput{}, err }
-	defer s.Sem.Release()
-
 	// Buscar ad account pelo ID (act_123456789)
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
 	if err != nil { return ImageCreativeOutput{}, fmt.Errorf("get ad account: %w", err) }
 
+	// Uploads disputam vaga como bulk: não seguram leituras de outros clientes
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityBulk))
+	if err != nil { return ImageCreativeOutput{}, err }
+	defer lease.Release()
+
 	// Buscar client para pegar nome (usado no path S3)
 	client, err := s.Store.GetClientByUUID(ctx, adAccount.ClientUUID)
 	if err != nil { return ImageCreativeOutput{}, fmt.Errorf("get client: %w", err) }
@@ -101,7 +100,8 @@ func (s *CreativeSyncService) CreateImageCreative(ctx context.Context, in ImageC
 	token, err := s.Tokens.Resolve(adAccount.TokenRef)
 	if err != nil { return ImageCreativeOutput{}, fmt.Errorf("resolve token: %w", err) }
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
 
 	imageHash, err := mc.UploadImage(ctx, adAccount.AdAccountID, in.ImageName, in.ImageBytes)
 	if err != nil { return ImageCreativeOutput{}, err }
@@ -147,13 +147,15 @@ func (s *CreativeSyncService) CreateImageCreative(ctx context.Context, in ImageC
 }
 
 func (s *CreativeSyncService) CreateVideoCreative(ctx context.Context, in VideoCreativeInput) (VideoCreativeOutput, error) {
-	if err := s.Sem.Acquire(ctx); err != nil { return VideoCreativeOutput{}, err }
-	defer s.Sem.Release()
-
 	// Buscar ad account pelo ID (act_123456789)
-	adAccount, err := s.Store.GetAdAccount(ctx, in.AdAccountID)
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
 	if err != nil { return VideoCreativeOutput{}, fmt.Errorf("get ad account: %w", err) }
 
+	// Uploads disputam vaga como bulk: não seguram leituras de outros clientes
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityBulk))
+	if err != nil { return VideoCreativeOutput{}, err }
+	defer lease.Release()
+
 	// Buscar client para pegar nome (usado no path S3)
 	client, err := s.Store.GetClientByUUID(ctx, adAccount.ClientUUID)
 	if err != nil { return VideoCreativeOutput{}, fmt.Errorf("get client: %w", err) }
@@ -187,7 +189,8 @@ func (s *CreativeSyncService) CreateVideoCreative(ctx context.Context, in VideoC
 		return VideoCreativeOutput{}, fmt.Errorf("upload thumb to S3: %w", err)
 	}
 
-	mc := meta.New(s.BaseURL, s.APIVersion, token, s.HTTPTimeout)
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
 
    	videoID, err := mc.UploadVideo(ctx, adAccount.AdAccountID, in.Name, in.VideoName, in.VideoBytes)
    	if err != nil { return VideoCreativeOutput{}, fmt.Errorf("upload video to Meta: %w", err) }
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"creative-service/internal/meta"
+)
+
+// ======= DRY RUN =======
+// Creates, updates e deletes com DryRun passam por todas as validações locais,
+// resolvem ad account e token e montam o payload exato, mas em vez da chamada
+// real mandam o payload com execution_options=["validate_only"]. Nada é criado
+// nem alterado na Meta.
+
+type DryRunResult struct {
+	DryRun  bool           `json:"dry_run"` // sempre true; deixa explícito na resposta
+	Method  string         `json:"method"`
+	Path    string         `json:"path"` // relativo à versão da API: act_123/campaigns, 120210.../
+	Payload map[string]any `json:"payload"`
+	Valid   bool           `json:"valid"` // a Meta aceitou o payload com validate_only
+	Error   *DryRunError   `json:"error,omitempty"`
+}
+
+// DryRunError é o motivo da recusa da Meta, no mesmo formato dos erros da API
+type DryRunError struct {
+	Kind      meta.ErrorKind `json:"kind"`
+	Code      int            `json:"code"`
+	Subcode   int            `json:"subcode,omitempty"`
+	Message   string         `json:"message"`
+	UserTitle string         `json:"user_title,omitempty"`
+	UserMsg   string         `json:"user_msg,omitempty"`
+}
+
+// dryRun valida payload em path. Recusas da Meta sobre o payload ou o objeto
+// (parâmetro inválido, sem permissão, inexistente) viram Valid=false; token
+// inválido, throttling e falhas transientes voltam como erro, porque a
+// validação não chegou a acontecer.
+func dryRun(ctx context.Context, mc *meta.Client, path string, payload map[string]any) (*DryRunResult, error) {
+	res := &DryRunResult{DryRun: true, Method: "POST", Path: path, Payload: payload, Valid: true}
+
+	err := mc.ValidateOnly(ctx, path, payload)
+	if err == nil {
+		return res, nil
+	}
+
+	var me *meta.Error
+	if !errors.As(err, &me) || !meta.IsPermanent(err) || me.Kind == meta.KindAuthExpired {
+		return nil, err
+	}
+	res.Valid = false
+	res.Error = &DryRunError{
+		Kind:      me.Kind,
+		Code:      me.Code,
+		Subcode:   me.Subcode,
+		Message:   me.Message,
+		UserTitle: me.UserTitle,
+		UserMsg:   me.UserMsg,
+	}
+	return res, nil
+}
+
+// localDryRun é o resultado de um payload que não pode ir para validate_only
+// porque aponta para um pai que só existe depois da execução (dry run do
+// launch). As validações locais já passaram.
+func localDryRun(path string, payload map[string]any) *DryRunResult {
+	return &DryRunResult{DryRun: true, Method: "POST", Path: path, Payload: payload, Valid: true}
+}
+
+// localDryRunError é a recusa de uma validação local: o payload não chegou a
+// ser montado
+func localDryRunError(path string, err error) *DryRunResult {
+	return &DryRunResult{
+		DryRun: true,
+		Method: "POST",
+		Path:   path,
+		Error:  &DryRunError{Kind: meta.KindInvalidParameter, Message: err.Error()},
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"creative-service/internal/meta"
+)
+
+// ======= ESTIMATES =======
+// Estimativa de público e resultados diários para um adset ainda não criado.
+// Recebe o mesmo input de CreateAdSet; nada é criado na Meta.
+
+type OutcomeEstimate struct {
+	Spend       int   `json:"spend"` // centavos por dia
+	Reach       int64 `json:"reach"`
+	Impressions int64 `json:"impressions"`
+	Results     int64 `json:"results"` // resultados do optimization_goal
+}
+
+type EstimateAdSetOutput struct {
+	EstimateReady          bool  `json:"estimate_ready"`
+	AudienceSizeLowerBound int64 `json:"audience_size_lower_bound"`
+	AudienceSizeUpperBound int64 `json:"audience_size_upper_bound"`
+	EstimatedDAU           int64 `json:"estimated_dau"`
+
+	// Orçamento diário usado na estimativa (lifetime é dividido pelos dias restantes);
+	// sem orçamento no adset usa o da campanha
+	DailyBudget  int              `json:"daily_budget,omitempty"`
+	DailyResults *OutcomeEstimate `json:"daily_results,omitempty"`
+
+	Curve []OutcomeEstimate `json:"curve"`
+}
+
+func (s *AdSetService) EstimateAdSet(ctx context.Context, in CreateAdSetInput) (EstimateAdSetOutput, error) {
+	if err := validateAdSetBudget(in.DailyBudget, in.LifetimeBudget, in.EndTime); err != nil {
+		return EstimateAdSetOutput{}, err
+	}
+	if err := validateTargeting(in.Targeting); err != nil {
+		return EstimateAdSetOutput{}, err
+	}
+
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return EstimateAdSetOutput{}, fmt.Errorf("get ad account: %w", err)
+	}
+
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
+	if err != nil {
+		return EstimateAdSetOutput{}, err
+	}
+	defer lease.Release()
+
+	token, err := s.Tokens.Resolve(adAccount.TokenRef)
+	if err != nil {
+		return EstimateAdSetOutput{}, fmt.Errorf("resolve token: %w", err)
+	}
+
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	// end_time sem offset está no fuso da conta
+	loc := time.UTC
+	if in.DailyBudget == 0 && in.LifetimeBudget > 0 {
+		if loc, err = accountLocation(ctx, mc, adAccount.AdAccountID); err != nil {
+			return EstimateAdSetOutput{}, err
+		}
+	}
+
+	daily, err := dailySpend(in.DailyBudget, in.LifetimeBudget, in.EndTime, loc)
+	if err != nil {
+		return EstimateAdSetOutput{}, err
+	}
+	if daily == 0 && in.CampaignID != "" {
+		campaign, err := mc.GetObject(ctx, in.CampaignID, []string{"daily_budget", "lifetime_budget", "stop_time"})
+		if err != nil {
+			return EstimateAdSetOutput{}, fmt.Errorf("get campaign: %w", err)
+		}
+		cd, _ := strconv.Atoi(stringField(campaign, "daily_budget"))
+		cl, _ := strconv.Atoi(stringField(campaign, "lifetime_budget"))
+		if daily, err = dailySpend(cd, cl, stringField(campaign, "stop_time"), loc); err != nil {
+			return EstimateAdSetOutput{}, err
+		}
+	}
+
+	reach, err := mc.GetReachEstimate(ctx, adAccount.AdAccountID, in.Targeting)
+	if err != nil {
+		return EstimateAdSetOutput{}, fmt.Errorf("reach estimate: %w", err)
+	}
+	delivery, err := mc.GetDeliveryEstimate(ctx, adAccount.AdAccountID, in.Targeting, in.OptimizationGoal, nil)
+	if err != nil {
+		return EstimateAdSetOutput{}, fmt.Errorf("delivery estimate: %w", err)
+	}
+
+	out := EstimateAdSetOutput{
+		EstimateReady:          reach.EstimateReady && delivery.EstimateReady,
+		AudienceSizeLowerBound: reach.UsersLowerBound,
+		AudienceSizeUpperBound: reach.UsersUpperBound,
+		EstimatedDAU:           delivery.EstimateDAU,
+		DailyBudget:            daily,
+		Curve:                  make([]OutcomeEstimate, 0, len(delivery.DailyOutcomesCurve)),
+	}
+	// reachestimate às vezes volta zerado enquanto o delivery_estimate já tem MAU
+	if out.AudienceSizeUpperBound == 0 {
+		out.AudienceSizeLowerBound = delivery.EstimateMAULowerBound
+		out.AudienceSizeUpperBound = delivery.EstimateMAUUpperBound
+	}
+
+	curve := delivery.DailyOutcomesCurve
+	sort.Slice(curve, func(i, j int) bool { return curve[i].Spend < curve[j].Spend })
+	for _, p := range curve {
+		out.Curve = append(out.Curve, toOutcomeEstimate(p))
+	}
+	if daily > 0 && len(curve) > 0 {
+		e := toOutcomeEstimate(interpolateOutcome(curve, float64(daily)))
+		e.Spend = daily
+		out.DailyResults = &e
+	}
+
+	return out, nil
+}
+
+// dailySpend converte o orçamento em gasto diário; lifetime é dividido pelos
+// dias que faltam até o fim (mínimo 1). endTime sem offset é lido no fuso loc.
+func dailySpend(daily, lifetime int, endTime string, loc *time.Location) (int, error) {
+	if daily > 0 {
+		return daily, nil
+	}
+	if lifetime == 0 || endTime == "" {
+		return 0, nil
+	}
+	end, err := parseScheduleTime(endTime, loc)
+	if err != nil {
+		return 0, fmt.Errorf("invalid end_time: %w", err)
+	}
+	days := math.Ceil(time.Until(end).Hours() / 24)
+	if days < 1 {
+		days = 1
+	}
+	return int(float64(lifetime) / days), nil
+}
+
+// interpolateOutcome interpola linearmente a curva (ordenada por spend) no gasto
+// pedido. Abaixo do primeiro ponto escala a partir de zero; acima do último
+// devolve o último ponto (a Meta não projeta além dele).
+func interpolateOutcome(curve []meta.OutcomePoint, spend float64) meta.OutcomePoint {
+	prev := meta.OutcomePoint{}
+	for _, p := range curve {
+		if spend <= p.Spend {
+			if p.Spend == prev.Spend {
+				return p
+			}
+			f := (spend - prev.Spend) / (p.Spend - prev.Spend)
+			return meta.OutcomePoint{
+				Spend:       spend,
+				Reach:       prev.Reach + f*(p.Reach-prev.Reach),
+				Impressions: prev.Impressions + f*(p.Impressions-prev.Impressions),
+				Actions:     prev.Actions + f*(p.Actions-prev.Actions),
+			}
+		}
+		prev = p
+	}
+	return prev
+}
+
+func toOutcomeEstimate(p meta.OutcomePoint) OutcomeEstimate {
+	return OutcomeEstimate{
+		Spend:       int(math.Round(p.Spend)),
+		Reach:       int64(math.Round(p.Reach)),
+		Impressions: int64(math.Round(p.Impressions)),
+		Results:     int64(math.Round(p.Actions)),
+	}
+}
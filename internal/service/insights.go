@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/secrets"
+	"creative-service/internal/storage"
+)
+
+// ======= INSIGHTS =======
+
+const (
+	insightsDateLayout = "2006-01-02"
+
+	InsightsDefaultPageSize = 500
+	insightsMaxPageSize     = 5000
+)
+
+var (
+	insightsLevels = map[string]struct{}{"account": {}, "campaign": {}, "adset": {}, "ad": {}}
+
+	insightsDatePresets = map[string]struct{}{
+		"today": {}, "yesterday": {}, "this_month": {}, "last_month": {}, "this_quarter": {},
+		"maximum": {}, "last_3d": {}, "last_7d": {}, "last_14d": {}, "last_28d": {}, "last_30d": {},
+		"last_90d": {}, "last_week_mon_sun": {}, "last_week_sun_sat": {}, "last_quarter": {},
+		"last_year": {}, "this_week_mon_today": {}, "this_week_sun_today": {}, "this_year": {},
+	}
+
+	insightsBreakdowns = map[string]struct{}{
+		"age": {}, "gender": {}, "country": {}, "region": {}, "dma": {},
+		"publisher_platform": {}, "platform_position": {}, "device_platform": {},
+		"impression_device": {}, "hourly_stats_aggregated_by_advertiser_time_zone": {},
+		"hourly_stats_aggregated_by_audience_time_zone": {}, "frequency_value": {},
+	}
+
+	insightsAttributionWindows = map[string]struct{}{
+		"1d_click": {}, "7d_click": {}, "28d_click": {}, "1d_view": {}, "7d_view": {}, "28d_view": {},
+	}
+
+	// Campos padrão quando ?fields= não é informado
+	defaultInsightsFields = []string{
+		"account_id", "campaign_id", "campaign_name", "adset_id", "adset_name", "ad_id", "ad_name",
+		"spend", "impressions", "reach", "clicks", "ctr", "cpc", "cpm", "frequency",
+		"actions", "action_values", "cost_per_action_type", "purchase_roas",
+		"date_start", "date_stop",
+	}
+)
+
+type InsightsService struct {
+	Store  *storage.Store
+	Tokens secrets.Resolver
+
+	Meta *meta.ClientFactory
+
+	Sched *Scheduler
+}
+
+type InsightsInput struct {
+	AdAccountID        string   `json:"ad_account_id"`
+	ObjectID           string   `json:"object_id,omitempty"` // opcional: campanha, adset ou ad; default é a conta inteira
+	Level              string   `json:"level"`               // default: ad
+	Fields             []string `json:"fields"`
+	DatePreset         string   `json:"date_preset,omitempty"` // default: last_7d (quando não há Since/Until)
+	Since              string   `json:"since,omitempty"`       // YYYY-MM-DD
+	Until              string   `json:"until,omitempty"`
+	TimeIncrement      string   `json:"time_increment,omitempty"` // 1..90, monthly ou all_days
+	Breakdowns         []string `json:"breakdowns,omitempty"`
+	AttributionWindows []string `json:"action_attribution_windows,omitempty"`
+
+	// Paginação da API (só GET /v1/insights): linhas por página e cursor da página
+	// anterior. Limit 0 traz todas as páginas da Meta (exports).
+	Limit int    `json:"-"`
+	After string `json:"-"`
+}
+
+type InsightsPaging struct {
+	After string `json:"after"` // cursor da próxima página
+}
+
+type InsightsOutput struct {
+	Level      string           `json:"level"`
+	DatePreset string           `json:"date_preset,omitempty"`
+	Since      string           `json:"since,omitempty"`
+	Until      string           `json:"until,omitempty"`
+	Count      int              `json:"count"`
+	Rows       []map[string]any `json:"rows"`
+	Paging     *InsightsPaging  `json:"paging,omitempty"` // ausente na última página
+}
+
+func (s *InsightsService) GetInsights(ctx context.Context, in InsightsInput) (InsightsOutput, error) {
+	params, err := insightsParams(&in)
+	if err != nil {
+		return InsightsOutput{}, err
+	}
+
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return InsightsOutput{}, fmt.Errorf("get ad account: %w", err)
+	}
+
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
+	if err != nil {
+		return InsightsOutput{}, err
+	}
+	defer lease.Release()
+
+	token, err := s.Tokens.Resolve(adAccount.TokenRef)
+	if err != nil {
+		return InsightsOutput{}, fmt.Errorf("resolve token: %w", err)
+	}
+
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	objectID := meta.Act(adAccount.AdAccountID)
+	if in.ObjectID != "" {
+		if err := checkObjectsInAdAccount(ctx, mc, adAccount.AdAccountID, in.ObjectID); err != nil {
+			return InsightsOutput{}, err
+		}
+		objectID = in.ObjectID
+	}
+
+	var rows []map[string]any
+	var next string
+	if in.Limit > 0 {
+		params.Limit = in.Limit
+		rows, next, err = mc.GetInsightsPage(ctx, objectID, params, in.After)
+	} else {
+		rows, err = mc.GetInsights(ctx, objectID, params)
+	}
+	if err != nil {
+		return InsightsOutput{}, fmt.Errorf("get insights: %w", err)
+	}
+	if rows == nil {
+		rows = []map[string]any{}
+	}
+
+	out := InsightsOutput{
+		Level:      in.Level,
+		DatePreset: in.DatePreset,
+		Since:      in.Since,
+		Until:      in.Until,
+		Count:      len(rows),
+		Rows:       rows,
+	}
+	if next != "" {
+		out.Paging = &InsightsPaging{After: next}
+	}
+	return out, nil
+}
+
+// insightsParams valida o input (aplicando defaults) e monta os parâmetros da Meta
+func insightsParams(in *InsightsInput) (meta.InsightsParams, error) {
+	if in.AdAccountID == "" {
+		return meta.InsightsParams{}, fmt.Errorf("missing ad_account_id")
+	}
+	if in.Level == "" {
+		in.Level = "ad"
+	}
+	if _, ok := insightsLevels[in.Level]; !ok {
+		return meta.InsightsParams{}, fmt.Errorf("invalid level: %q", in.Level)
+	}
+	if len(in.Fields) == 0 {
+		in.Fields = defaultInsightsFields
+	}
+	if in.Limit < 0 || in.Limit > insightsMaxPageSize {
+		return meta.InsightsParams{}, fmt.Errorf("limit must be between 1 and %d", insightsMaxPageSize)
+	}
+	if in.After != "" && in.Limit == 0 {
+		in.Limit = InsightsDefaultPageSize
+	}
+
+	p := meta.InsightsParams{
+		Level:         in.Level,
+		Fields:        in.Fields,
+		TimeIncrement: in.TimeIncrement,
+	}
+
+	switch {
+	case in.Since != "" || in.Until != "":
+		if in.DatePreset != "" {
+			return p, fmt.Errorf("date_preset and since/until are mutually exclusive")
+		}
+		since, err := time.Parse(insightsDateLayout, in.Since)
+		if err != nil {
+			return p, fmt.Errorf("since must be YYYY-MM-DD")
+		}
+		until, err := time.Parse(insightsDateLayout, in.Until)
+		if err != nil {
+			return p, fmt.Errorf("until must be YYYY-MM-DD")
+		}
+		if until.Before(since) {
+			return p, fmt.Errorf("until must not be before since")
+		}
+		p.TimeRange = &meta.TimeRange{Since: in.Since, Until: in.Until}
+	default:
+		if in.DatePreset == "" {
+			in.DatePreset = "last_7d"
+		}
+		if _, ok := insightsDatePresets[in.DatePreset]; !ok {
+			return p, fmt.Errorf("invalid date_preset: %q", in.DatePreset)
+		}
+		p.DatePreset = in.DatePreset
+	}
+
+	for _, b := range in.Breakdowns {
+		if _, ok := insightsBreakdowns[b]; !ok {
+			return p, fmt.Errorf("invalid breakdown: %q", b)
+		}
+	}
+	p.Breakdowns = in.Breakdowns
+
+	for _, w := range in.AttributionWindows {
+		if _, ok := insightsAttributionWindows[w]; !ok {
+			return p, fmt.Errorf("invalid action_attribution_window: %q", w)
+		}
+	}
+	p.ActionAttributionWindows = in.AttributionWindows
+
+	return p, nil
+}
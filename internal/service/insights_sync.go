@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/secrets"
+	"creative-service/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// ======= INSIGHTS SYNC =======
+// Copia insights diários por ad para insights_daily (consulta via SQL pelo BI).
+// Cada conta tem um cursor (synced_until); a cada execução os últimos
+// LookbackDays são buscados de novo porque conversões atribuídas chegam atrasadas.
+
+const (
+	JobTypeInsightsSync = "insights_sync"
+
+	defaultSyncBackfillDays = 90
+	defaultSyncLookbackDays = 7
+	insightsSyncChunkDays   = 7 // dias por chamada à Meta
+)
+
+var insightsSyncFields = []string{
+	"account_currency", "campaign_id", "campaign_name", "adset_id", "adset_name", "ad_id", "ad_name",
+	"spend", "impressions", "reach", "clicks", "inline_link_clicks",
+	"actions", "action_values", "date_start",
+}
+
+// Action types usados nas colunas de conversão (o primeiro presente vence)
+var (
+	purchaseActionTypes = []string{"purchase", "omni_purchase", "offsite_conversion.fb_pixel_purchase"}
+	leadActionTypes     = []string{"lead", "onsite_conversion.lead_grouped", "offsite_conversion.fb_pixel_lead"}
+)
+
+type InsightsSyncService struct {
+	Store  *storage.Store
+	Tokens secrets.Resolver
+
+	Meta *meta.ClientFactory
+
+	Sched *Scheduler
+
+	Interval     time.Duration // intervalo mínimo entre syncs da mesma conta
+	BackfillDays int           // primeiro sync (default 90)
+	LookbackDays int           // janela re-sincronizada a cada execução (default 7)
+}
+
+type insightsSyncInput struct {
+	AdAccountID string `json:"ad_account_id"`
+}
+
+// InsightsSyncProgress é o result_json do job
+type InsightsSyncProgress struct {
+	Since        string `json:"since"`
+	Until        string `json:"until"`
+	SyncedUntil  string `json:"synced_until,omitempty"`
+	RowsUpserted int    `json:"rows_upserted"`
+}
+
+// EnqueueDue enfileira um insights_sync para cada conta ativa vencida.
+// O índice único em jobs garante um único sync ativo por conta.
+func (s *InsightsSyncService) EnqueueDue(ctx context.Context) (int, error) {
+	accounts, err := s.Store.ListAdAccountsDueForInsightsSync(ctx, s.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("list ad accounts: %w", err)
+	}
+
+	n := 0
+	for _, aa := range accounts {
+		input, err := json.Marshal(insightsSyncInput{AdAccountID: aa.AdAccountID})
+		if err != nil {
+			return n, err
+		}
+		created, err := s.Store.EnqueueJobIfIdle(ctx, storage.Job{
+			JobID:       uuid.New().String(),
+			ClientUUID:  &aa.ClientUUID,
+			AdAccountID: &aa.AdAccountID,
+			JobType:     JobTypeInsightsSync,
+			Input:       input,
+		})
+		if err != nil {
+			return n, fmt.Errorf("enqueue sync %s: %w", aa.AdAccountID, err)
+		}
+		if created {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// RunSyncJob é o JobHandler de insights_sync
+func (s *InsightsSyncService) RunSyncJob(ctx context.Context, job storage.Job, progress func(any) error) (any, error) {
+	var in insightsSyncInput
+	if err := json.Unmarshal(job.Input, &in); err != nil {
+		return nil, fmt.Errorf("%w: decode input: %v", ErrPermanent, err)
+	}
+
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("get ad account: %w", err)
+	}
+	token, err := s.Tokens.Resolve(adAccount.TokenRef)
+	if err != nil {
+		return nil, fmt.Errorf("resolve token: %w", err)
+	}
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	st, err := s.Store.GetInsightsSyncState(ctx, adAccount.AdAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("get sync state: %w", err)
+	}
+
+	loc, err := s.location(ctx, mc, adAccount)
+	if err != nil {
+		return nil, err
+	}
+	since, until := s.syncRange(st, time.Now(), loc)
+	p := InsightsSyncProgress{Since: since.Format(insightsDateLayout), Until: until.Format(insightsDateLayout)}
+
+	// last_run_at só é gravado no fim: uma execução que falhou por algo passageiro
+	// deixa a conta vencida para o próximo EnqueueDue, sem esperar o Interval
+	startedAt := time.Now()
+	st.RowsUpserted = 0
+
+	if err := s.Store.EnsureInsightsPartitions(ctx, since, until); err != nil {
+		return nil, err
+	}
+
+	fail := func(err error) (any, error) {
+		msg := err.Error()
+		st.LastError = &msg
+		if errors.Is(err, ErrPermanent) || meta.IsPermanent(err) {
+			st.LastRunAt = &startedAt
+		}
+		if saveErr := s.Store.SaveInsightsSyncState(context.WithoutCancel(ctx), st); saveErr != nil {
+			return p, fmt.Errorf("%w (save sync state: %v)", err, saveErr)
+		}
+		return p, err
+	}
+
+	for from := since; !from.After(until); from = from.AddDate(0, 0, insightsSyncChunkDays) {
+		to := from.AddDate(0, 0, insightsSyncChunkDays-1)
+		if to.After(until) {
+			to = until
+		}
+
+		raw, err := s.fetchChunk(ctx, mc, adAccount, from, to)
+		if err != nil {
+			return fail(fmt.Errorf("get insights %s..%s: %w", from.Format(insightsDateLayout), to.Format(insightsDateLayout), err))
+		}
+
+		rows, err := insightsDailyRows(adAccount.AdAccountID, raw)
+		if err != nil {
+			return fail(fmt.Errorf("%w: %v", ErrPermanent, err))
+		}
+		if err := s.Store.UpsertInsightsDaily(ctx, rows); err != nil {
+			return fail(fmt.Errorf("upsert insights: %w", err))
+		}
+
+		// Cursor avança por bloco: uma falha no meio não perde o que já foi gravado
+		cursor := to
+		st.SyncedUntil = &cursor
+		st.RowsUpserted += int64(len(rows))
+		if err := s.Store.SaveInsightsSyncState(ctx, st); err != nil {
+			return p, fmt.Errorf("save sync state: %w", err)
+		}
+
+		p.SyncedUntil = to.Format(insightsDateLayout)
+		p.RowsUpserted += len(rows)
+		if err := progress(p); err != nil {
+			return nil, fmt.Errorf("save progress: %w", err)
+		}
+	}
+
+	done := time.Now()
+	st.LastRunAt, st.LastSuccessAt, st.LastError = &startedAt, &done, nil
+	if err := s.Store.SaveInsightsSyncState(ctx, st); err != nil {
+		return p, fmt.Errorf("save sync state: %w", err)
+	}
+	return p, nil
+}
+
+func (s *InsightsSyncService) fetchChunk(ctx context.Context, mc *meta.Client, adAccount storage.AdAccount, from, to time.Time) ([]map[string]any, error) {
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityBackground))
+	if err != nil {
+		return nil, err
+	}
+	defer lease.Release()
+
+	return mc.GetInsights(ctx, meta.Act(adAccount.AdAccountID), meta.InsightsParams{
+		Level:         "ad",
+		Fields:        insightsSyncFields,
+		TimeRange:     &meta.TimeRange{Since: from.Format(insightsDateLayout), Until: to.Format(insightsDateLayout)},
+		TimeIncrement: "1",
+		Limit:         500,
+	})
+}
+
+// location lê o fuso da conta, que define os dias dos insights na Meta
+func (s *InsightsSyncService) location(ctx context.Context, mc *meta.Client, adAccount storage.AdAccount) (*time.Location, error) {
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityBackground))
+	if err != nil {
+		return nil, err
+	}
+	defer lease.Release()
+
+	return accountLocation(ctx, mc, adAccount.AdAccountID)
+}
+
+// syncRange: primeiro sync faz backfill; os seguintes recomeçam LookbackDays
+// antes do cursor. Vai até hoje no fuso da conta (loc), que é como a Meta corta
+// os dias; o dia corrente é atualizado na próxima execução. As datas devolvidas
+// são meia-noite UTC do dia da conta, como a coluna date.
+func (s *InsightsSyncService) syncRange(st storage.InsightsSyncState, now time.Time, loc *time.Location) (time.Time, time.Time) {
+	backfill, lookback := s.BackfillDays, s.LookbackDays
+	if backfill <= 0 {
+		backfill = defaultSyncBackfillDays
+	}
+	if lookback <= 0 {
+		lookback = defaultSyncLookbackDays
+	}
+
+	now = now.In(loc)
+	until := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	since := until.AddDate(0, 0, -(backfill - 1))
+	if st.SyncedUntil != nil {
+		cursor := time.Date(st.SyncedUntil.Year(), st.SyncedUntil.Month(), st.SyncedUntil.Day(), 0, 0, 0, 0, time.UTC)
+		if c := cursor.AddDate(0, 0, -(lookback - 1)); c.After(since) {
+			since = c
+		}
+	}
+	return since, until
+}
+
+// insightsDailyRows converte as linhas da Meta (métricas vêm como string)
+func insightsDailyRows(adAccountID string, raw []map[string]any) ([]storage.InsightsDailyRow, error) {
+	rows := make([]storage.InsightsDailyRow, 0, len(raw))
+	for _, r := range raw {
+		date, err := time.Parse(insightsDateLayout, stringField(r, "date_start"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid date_start %q for ad %s", stringField(r, "date_start"), stringField(r, "ad_id"))
+		}
+
+		actions, _ := json.Marshal(r["actions"])
+		actionValues, _ := json.Marshal(r["action_values"])
+
+		rows = append(rows, storage.InsightsDailyRow{
+			Date:          date,
+			AdAccountID:   adAccountID,
+			CampaignID:    stringField(r, "campaign_id"),
+			CampaignName:  stringField(r, "campaign_name"),
+			AdSetID:       stringField(r, "adset_id"),
+			AdSetName:     stringField(r, "adset_name"),
+			AdID:          stringField(r, "ad_id"),
+			AdName:        stringField(r, "ad_name"),
+			Currency:      stringField(r, "account_currency"),
+			Spend:         floatField(r, "spend"),
+			Impressions:   int64(floatField(r, "impressions")),
+			Reach:         int64(floatField(r, "reach")),
+			Clicks:        int64(floatField(r, "clicks")),
+			LinkClicks:    int64(floatField(r, "inline_link_clicks")),
+			Purchases:     actionValue(r["actions"], purchaseActionTypes),
+			PurchaseValue: actionValue(r["action_values"], purchaseActionTypes),
+			Leads:         actionValue(r["actions"], leadActionTypes),
+			Actions:       nullJSON(actions),
+			ActionValues:  nullJSON(actionValues),
+		})
+	}
+	return rows, nil
+}
+
+// actionValue soma o valor do primeiro action_type da lista presente em actions
+func actionValue(v any, types []string) float64 {
+	list, _ := v.([]any)
+	byType := map[string]float64{}
+	for _, item := range list {
+		m, _ := item.(map[string]any)
+		byType[stringField(m, "action_type")] += floatField(m, "value")
+	}
+	for _, t := range types {
+		if val, ok := byType[t]; ok {
+			return val
+		}
+	}
+	return 0
+}
+
+// floatField lê métricas que a Meta devolve como string ("12.34"); 0 se ausente
+func floatField(m map[string]any, k string) float64 {
+	f, _ := strconv.ParseFloat(stringField(m, k), 64)
+	return f
+}
+
+func nullJSON(b []byte) json.RawMessage {
+	if string(b) == "null" {
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"creative-service/internal/storage"
+)
+
+func TestSyncRange(t *testing.T) {
+	saoPaulo := time.FixedZone("America/Sao_Paulo", -3*3600)
+	tokyo := time.FixedZone("Asia/Tokyo", 9*3600)
+	day := func(s string) time.Time {
+		d, err := time.Parse(insightsDateLayout, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	cursor := func(s string) storage.InsightsSyncState {
+		d := day(s)
+		return storage.InsightsSyncState{SyncedUntil: &d}
+	}
+
+	tests := []struct {
+		name      string
+		s         InsightsSyncService
+		st        storage.InsightsSyncState
+		now       string // RFC3339
+		loc       *time.Location
+		wantSince string
+		wantUntil string
+	}{
+		{name: "first sync backfills", now: "2024-03-10T15:00:00Z", loc: time.UTC, wantSince: "2023-12-12", wantUntil: "2024-03-10"},
+		{name: "custom backfill", s: InsightsSyncService{BackfillDays: 3}, now: "2024-03-10T15:00:00Z", loc: time.UTC, wantSince: "2024-03-08", wantUntil: "2024-03-10"},
+		{name: "lookback before cursor", st: cursor("2024-03-09"), now: "2024-03-10T15:00:00Z", loc: time.UTC, wantSince: "2024-03-03", wantUntil: "2024-03-10"},
+		{
+			name: "lookback never goes past the backfill", s: InsightsSyncService{BackfillDays: 2, LookbackDays: 7},
+			st: cursor("2024-03-09"), now: "2024-03-10T15:00:00Z", loc: time.UTC, wantSince: "2024-03-09", wantUntil: "2024-03-10",
+		},
+		// 02:00 UTC ainda é o dia anterior em São Paulo
+		{name: "account west of utc", s: InsightsSyncService{BackfillDays: 1}, now: "2024-03-10T02:00:00Z", loc: saoPaulo, wantSince: "2024-03-09", wantUntil: "2024-03-09"},
+		// 20:00 UTC já é o dia seguinte em Tóquio
+		{name: "account east of utc", s: InsightsSyncService{BackfillDays: 1}, now: "2024-03-10T20:00:00Z", loc: tokyo, wantSince: "2024-03-11", wantUntil: "2024-03-11"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			since, until := tt.s.syncRange(tt.st, now, tt.loc)
+			if !since.Equal(day(tt.wantSince)) || !until.Equal(day(tt.wantUntil)) {
+				t.Errorf("range = %s..%s, want %s..%s", since.Format(insightsDateLayout), until.Format(insightsDateLayout), tt.wantSince, tt.wantUntil)
+			}
+		})
+	}
+}
+
+func TestInsightsDailyRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     map[string]any
+		want    storage.InsightsDailyRow
+		wantErr string
+	}{
+		{
+			name: "metrics as strings",
+			raw: map[string]any{
+				"date_start": "2024-03-10", "account_currency": "BRL", "campaign_id": "1", "adset_id": "2", "ad_id": "3", "ad_name": "ad",
+				"spend": "12.34", "impressions": "1000", "reach": "800", "clicks": "25", "inline_link_clicks": "20",
+			},
+			want: storage.InsightsDailyRow{
+				AdAccountID: "act_1", CampaignID: "1", AdSetID: "2", AdID: "3", AdName: "ad", Currency: "BRL",
+				Spend: 12.34, Impressions: 1000, Reach: 800, Clicks: 25, LinkClicks: 20,
+			},
+		},
+		{
+			name: "first listed action type wins",
+			raw: map[string]any{
+				"date_start": "2024-03-10", "ad_id": "3",
+				"actions": []any{
+					map[string]any{"action_type": "omni_purchase", "value": "5"},
+					map[string]any{"action_type": "purchase", "value": "4"},
+					map[string]any{"action_type": "offsite_conversion.fb_pixel_lead", "value": "2"},
+				},
+				"action_values": []any{map[string]any{"action_type": "omni_purchase", "value": "99.5"}},
+			},
+			want: storage.InsightsDailyRow{AdAccountID: "act_1", AdID: "3", Purchases: 4, PurchaseValue: 99.5, Leads: 2},
+		},
+		{name: "missing metrics are zero", raw: map[string]any{"date_start": "2024-03-10", "ad_id": "3"}, want: storage.InsightsDailyRow{AdAccountID: "act_1", AdID: "3"}},
+		{name: "invalid date", raw: map[string]any{"date_start": "10/03/2024", "ad_id": "3"}, wantErr: "invalid date_start"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := insightsDailyRows("act_1", []map[string]any{tt.raw})
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr != "" {
+				return
+			}
+			got := rows[0]
+			if got.Date.Format(insightsDateLayout) != "2024-03-10" {
+				t.Errorf("date = %s", got.Date)
+			}
+			// actions ausentes viram NULL (nullJSON); presentes vão como o JSON da Meta
+			if _, ok := tt.raw["actions"]; ok != (len(got.Actions) > 0) {
+				t.Errorf("actions = %s", got.Actions)
+			}
+			got.Date, got.Actions, got.ActionValues = time.Time{}, nil, nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("row = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"creative-service/internal/meta"
+)
+
+func TestInsightsParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      InsightsInput
+		want    meta.InsightsParams
+		wantIn  InsightsInput // campos do input depois dos defaults (só os conferidos abaixo)
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			in:     InsightsInput{AdAccountID: "act_1"},
+			want:   meta.InsightsParams{Level: "ad", Fields: defaultInsightsFields, DatePreset: "last_7d"},
+			wantIn: InsightsInput{Level: "ad", DatePreset: "last_7d"},
+		},
+		{
+			name: "time range with breakdowns",
+			in: InsightsInput{
+				AdAccountID: "act_1", Level: "campaign", Fields: []string{"spend"}, Since: "2024-01-01", Until: "2024-01-31",
+				TimeIncrement: "1", Breakdowns: []string{"age", "gender"}, AttributionWindows: []string{"7d_click"},
+			},
+			want: meta.InsightsParams{
+				Level: "campaign", Fields: []string{"spend"}, TimeRange: &meta.TimeRange{Since: "2024-01-01", Until: "2024-01-31"},
+				TimeIncrement: "1", Breakdowns: []string{"age", "gender"}, ActionAttributionWindows: []string{"7d_click"},
+			},
+			wantIn: InsightsInput{Level: "campaign"},
+		},
+		{
+			name:   "cursor without limit uses the default page size",
+			in:     InsightsInput{AdAccountID: "act_1", DatePreset: "yesterday", After: "abc"},
+			want:   meta.InsightsParams{Level: "ad", Fields: defaultInsightsFields, DatePreset: "yesterday"},
+			wantIn: InsightsInput{Level: "ad", DatePreset: "yesterday", Limit: InsightsDefaultPageSize},
+		},
+		{name: "missing ad account", in: InsightsInput{}, wantErr: "missing ad_account_id"},
+		{name: "invalid level", in: InsightsInput{AdAccountID: "act_1", Level: "creative"}, wantErr: "invalid level"},
+		{name: "limit too large", in: InsightsInput{AdAccountID: "act_1", Limit: insightsMaxPageSize + 1}, wantErr: "limit must be between"},
+		{name: "negative limit", in: InsightsInput{AdAccountID: "act_1", Limit: -1}, wantErr: "limit must be between"},
+		{
+			name:    "preset and range",
+			in:      InsightsInput{AdAccountID: "act_1", DatePreset: "last_7d", Since: "2024-01-01", Until: "2024-01-02"},
+			wantErr: "mutually exclusive",
+		},
+		{name: "since without until", in: InsightsInput{AdAccountID: "act_1", Since: "2024-01-01"}, wantErr: "until must be YYYY-MM-DD"},
+		{name: "bad since", in: InsightsInput{AdAccountID: "act_1", Since: "01/01/2024", Until: "2024-01-02"}, wantErr: "since must be YYYY-MM-DD"},
+		{name: "until before since", in: InsightsInput{AdAccountID: "act_1", Since: "2024-02-01", Until: "2024-01-01"}, wantErr: "until must not be before since"},
+		{name: "invalid preset", in: InsightsInput{AdAccountID: "act_1", DatePreset: "last_2d"}, wantErr: "invalid date_preset"},
+		{name: "invalid breakdown", in: InsightsInput{AdAccountID: "act_1", Breakdowns: []string{"city"}}, wantErr: "invalid breakdown"},
+		{name: "invalid attribution window", in: InsightsInput{AdAccountID: "act_1", AttributionWindows: []string{"3d_click"}}, wantErr: "invalid action_attribution_window"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.in
+			got, err := insightsParams(&in)
+			checkErr(t, err, tt.wantErr)
+			if tt.wantErr != "" {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("params = %+v, want %+v", got, tt.want)
+			}
+			if in.Level != tt.wantIn.Level || in.DatePreset != tt.wantIn.DatePreset || in.Limit != tt.wantIn.Limit {
+				t.Errorf("input level = %s preset = %s limit = %d, want %s %s %d",
+					in.Level, in.DatePreset, in.Limit, tt.wantIn.Level, tt.wantIn.DatePreset, tt.wantIn.Limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/storage"
+)
+
+// ======= JOB RUNNER =======
+// Executa jobs da tabela jobs no worker. Cada job_type tem um JobHandler;
+// o runner cuida de reivindicar, renovar lease, repetir e gravar o resultado.
+
+const (
+	jobLease       = 2 * time.Minute
+	jobMaxAttempts = 3
+	jobRetryDelay  = 30 * time.Second // dobra a cada tentativa
+)
+
+// JobHandler executa um job. progress grava um resultado parcial (e renova o
+// lease), permitindo retomar o job de onde parou se o worker morrer.
+type JobHandler func(ctx context.Context, job storage.Job, progress func(result any) error) (result any, err error)
+
+// ErrPermanent marca falhas que não adianta repetir (input inválido etc.);
+// erros permanentes da Meta (token, permissão, parâmetro) também não são repetidos
+var ErrPermanent = errors.New("permanent job failure")
+
+type JobRunner struct {
+	Store    *storage.Store
+	Handlers map[string]JobHandler
+}
+
+// RunNext executa um job da fila; devolve false quando não havia job
+func (r *JobRunner) RunNext(ctx context.Context) (bool, error) {
+	types := make([]string, 0, len(r.Handlers))
+	for t := range r.Handlers {
+		types = append(types, t)
+	}
+
+	job, err := r.Store.ClaimJob(ctx, types, jobLease)
+	if errors.Is(err, storage.ErrNoJob) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claim job: %w", err)
+	}
+
+	progress := func(result any) error {
+		b, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		return r.Store.UpdateJobProgress(ctx, job, b, jobLease)
+	}
+
+	result, runErr := r.Handlers[job.JobType](ctx, job, progress)
+
+	var resultJSON json.RawMessage
+	if result != nil {
+		if resultJSON, err = json.Marshal(result); err != nil {
+			runErr = fmt.Errorf("encode result: %w", err)
+		}
+	}
+
+	// Resultado precisa ser gravado mesmo se o worker estiver desligando
+	finishCtx := context.WithoutCancel(ctx)
+	if runErr == nil {
+		return true, r.Store.FinishJob(finishCtx, job, storage.JobSucceeded, resultJSON, nil, nil)
+	}
+
+	msg := runErr.Error()
+	log.Printf("job %s (%s) attempt %d failed: %v", job.JobID, job.JobType, job.Attempts, runErr)
+
+	var retryAt *time.Time
+	switch {
+	case ctx.Err() != nil:
+		// Worker desligando: devolve o job para a fila imediatamente
+		t := time.Now()
+		retryAt = &t
+	case job.Attempts < jobMaxAttempts && !errors.Is(runErr, ErrPermanent) && !meta.IsPermanent(runErr):
+		t := time.Now().Add(jobRetryDelay << (job.Attempts - 1))
+		retryAt = &t
+	}
+	return true, r.Store.FinishJob(finishCtx, job, storage.JobFailed, resultJSON, &msg, retryAt)
+}
@@ -0,0 +1,603 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"creative-service/internal/auth"
+	"creative-service/internal/meta"
+	"creative-service/internal/storage"
+	"creative-service/internal/targeting"
+
+	"github.com/google/uuid"
+)
+
+// Status possíveis de um launch (ver migration 006)
+const (
+	LaunchRunning    = "running"
+	LaunchSucceeded  = "succeeded"
+	LaunchRolledBack = "rolled_back"
+	LaunchFailed     = "failed"
+	LaunchUndone     = "undone"
+)
+
+// LaunchService cria campaign → adsets → (creatives) → ads em ordem,
+// reaproveitando os services existentes. Se um passo falha, os objetos
+// já criados são removidos (soft delete) na ordem inversa.
+type LaunchService struct {
+	Store     *storage.Store
+	Campaigns *CampaignService
+	AdSets    *AdSetService
+	Ads       *AdService
+	Creatives *CreativeSyncService
+}
+
+type LaunchCampaignSpec struct {
+	Name                        string   `json:"name"`
+	Objective                   string   `json:"objective"`
+	Status                      string   `json:"status"`
+	SpecialAdCategories         []string `json:"special_ad_categories"`
+	BuyingType                  string   `json:"buying_type"`
+	IsAdSetBudgetSharingEnabled bool     `json:"is_adset_budget_sharing_enabled"`
+	DailyBudget                 int      `json:"daily_budget,omitempty"`
+	LifetimeBudget              int      `json:"lifetime_budget,omitempty"`
+	SpendCap                    int      `json:"spend_cap,omitempty"`
+	BidStrategy                 string   `json:"bid_strategy,omitempty"`
+}
+
+type LaunchAdSetSpec struct {
+	Name             string          `json:"name"`
+	BillingEvent     string          `json:"billing_event"`
+	OptimizationGoal string          `json:"optimization_goal"`
+	BidStrategy      string          `json:"bid_strategy,omitempty"`
+	BidAmount        int             `json:"bid_amount,omitempty"`
+	BidConstraints   map[string]any  `json:"bid_constraints,omitempty"`
+	DailyBudget      int             `json:"daily_budget,omitempty"`
+	LifetimeBudget   int             `json:"lifetime_budget,omitempty"`
+	StartTime        string          `json:"start_time,omitempty"`
+	EndTime          string          `json:"end_time,omitempty"`
+	AdSetSchedule    []ScheduleBlock `json:"adset_schedule,omitempty"`
+	Targeting        *targeting.Spec `json:"targeting"`
+	Status           string          `json:"status"`
+	Ads              []LaunchAdSpec  `json:"ads"`
+}
+
+// LaunchAdSpec referencia um creative existente (creative_id) ou descreve
+// um creative novo cujos arquivos vêm no multipart da requisição
+type LaunchAdSpec struct {
+	Name       string              `json:"name"`
+	Status     string              `json:"status"`
+	CreativeID string              `json:"creative_id,omitempty"`
+	Creative   *LaunchCreativeSpec `json:"creative,omitempty"`
+}
+
+type LaunchCreativeSpec struct {
+	Type        string `json:"type"` // image ou video
+	Name        string `json:"name"`
+	Link        string `json:"link"`
+	Message     string `json:"message"`
+	Headline    string `json:"headline"`
+	Description string `json:"description"`
+	File        string `json:"file"`      // nome do campo multipart com a imagem/vídeo
+	Thumbnail   string `json:"thumbnail"` // nome do campo multipart com o thumbnail (vídeo)
+}
+
+type LaunchSpec struct {
+	AdAccountID string             `json:"ad_account_id"`
+	Campaign    LaunchCampaignSpec `json:"campaign"`
+	AdSets      []LaunchAdSetSpec  `json:"adsets"`
+}
+
+type LaunchFile struct {
+	Name  string
+	Bytes []byte
+}
+
+type LaunchInput struct {
+	Spec  LaunchSpec
+	Files map[string]LaunchFile // chave: nome do campo multipart
+}
+
+// ErrLaunchFailed indica que o launch foi registrado mas não completou;
+// o registro retornado junto traz o resultado do rollback
+var ErrLaunchFailed = errors.New("launch failed")
+
+func (s *LaunchService) Launch(ctx context.Context, in LaunchInput) (storage.Launch, error) {
+	if err := validateLaunchSpec(in); err != nil {
+		return storage.Launch{}, err
+	}
+	applyLaunchDefaults(&in.Spec)
+
+	adAccount, err := getAdAccount(ctx, s.Store, in.Spec.AdAccountID)
+	if err != nil {
+		return storage.Launch{}, fmt.Errorf("get ad account: %w", err)
+	}
+
+	spec, err := json.Marshal(in.Spec)
+	if err != nil {
+		return storage.Launch{}, fmt.Errorf("encode spec: %w", err)
+	}
+
+	launch := storage.Launch{
+		LaunchID:    uuid.New().String(),
+		ClientUUID:  adAccount.ClientUUID,
+		AdAccountID: adAccount.AdAccountID,
+		Status:      LaunchRunning,
+		Spec:        spec,
+	}
+	if err := s.Store.CreateLaunch(ctx, launch); err != nil {
+		return storage.Launch{}, fmt.Errorf("save launch: %w", err)
+	}
+
+	// Persiste o progresso a cada objeto criado: se o processo cair no meio,
+	// o registro ainda aponta para tudo que precisa ser desfeito
+	track := func(objects []storage.LaunchObject) {
+		_ = s.Store.UpdateLaunch(ctx, launch.LaunchID, LaunchRunning, objects, nil)
+	}
+	out := s.execute(ctx, adAccount, in, track)
+	launch.CreatedObjects = out.objects
+	launch.Status = out.status
+
+	// Gravação final não pode ser interrompida pelo cancelamento do caller
+	finishCtx := context.WithoutCancel(ctx)
+	if err := s.Store.UpdateLaunch(finishCtx, launch.LaunchID, out.status, out.objects, out.msg); err != nil {
+		return launch, fmt.Errorf("save launch: %w", err)
+	}
+
+	saved, err := s.Store.GetLaunch(finishCtx, launch.LaunchID)
+	if err != nil {
+		return launch, fmt.Errorf("get launch: %w", err)
+	}
+	return saved, out.err
+}
+
+// LaunchPlan é a resposta do dry run do launch: o payload de cada objeto, na
+// ordem em que o launch os criaria. Nada é criado nem registrado.
+type LaunchPlan struct {
+	DryRun bool             `json:"dry_run"` // sempre true
+	Valid  bool             `json:"valid"`   // todos os passos válidos
+	Steps  []LaunchPlanStep `json:"steps"`
+}
+
+// LaunchPlanStep é um objeto do plano. Ref é a posição dele no spec (campaign,
+// adsets[0], adsets[0].ads[1], adsets[0].ads[1].creative); nos payloads, o ID
+// de um pai que ainda não existe vai como a Ref dele entre chaves.
+type LaunchPlanStep struct {
+	Type      string        `json:"type"`
+	Ref       string        `json:"ref"`
+	Name      string        `json:"name"`
+	CheckedBy string        `json:"checked_by"` // meta (validate_only) ou local
+	DryRun    *DryRunResult `json:"dry_run"`
+}
+
+// Quem validou cada passo do plano
+const (
+	CheckedByMeta  = "meta"
+	CheckedByLocal = "local"
+)
+
+// Plan é o dry run do launch: valida o spec, monta o payload de todos os
+// objetos e manda cada um que a Meta consegue validar para validate_only. A
+// campanha vai para a Meta; adsets, creatives e ads apontam para pais que só
+// existem depois da execução e são validados localmente (adsets contra o spec
+// da campanha). Recusas entram no plano (Valid=false) em vez de interromper;
+// erros em que a validação não aconteceu (token, throttling, Meta fora) voltam.
+func (s *LaunchService) Plan(ctx context.Context, in LaunchInput) (LaunchPlan, error) {
+	if err := validateLaunchSpec(in); err != nil {
+		return LaunchPlan{}, err
+	}
+	applyLaunchDefaults(&in.Spec)
+
+	adAccount, err := getAdAccount(ctx, s.Store, in.Spec.AdAccountID)
+	if err != nil {
+		return LaunchPlan{}, fmt.Errorf("get ad account: %w", err)
+	}
+	return s.plan(ctx, adAccount, in)
+}
+
+// plan monta o plano com a ad account já resolvida
+func (s *LaunchService) plan(ctx context.Context, adAccount storage.AdAccount, in LaunchInput) (LaunchPlan, error) {
+	spec := in.Spec
+	plan := LaunchPlan{DryRun: true, Valid: true}
+	add := func(typ, ref, name, checkedBy string, dr *DryRunResult) {
+		plan.Steps = append(plan.Steps, LaunchPlanStep{Type: typ, Ref: ref, Name: name, CheckedBy: checkedBy, DryRun: dr})
+		plan.Valid = plan.Valid && dr.Valid
+	}
+
+	c := spec.Campaign
+	campaign, err := s.Campaigns.createCampaign(ctx, adAccount, CreateCampaignInput{
+		AdAccountID:                 spec.AdAccountID,
+		Name:                        c.Name,
+		Objective:                   c.Objective,
+		Status:                      c.Status,
+		SpecialAdCategories:         c.SpecialAdCategories,
+		BuyingType:                  c.BuyingType,
+		IsAdSetBudgetSharingEnabled: c.IsAdSetBudgetSharingEnabled,
+		DailyBudget:                 c.DailyBudget,
+		LifetimeBudget:              c.LifetimeBudget,
+		SpendCap:                    c.SpendCap,
+		BidStrategy:                 c.BidStrategy,
+		DryRun:                      true,
+	})
+	if err != nil {
+		return LaunchPlan{}, fmt.Errorf("validate campaign: %w", err)
+	}
+	add("campaign", "campaign", c.Name, CheckedByMeta, campaign.DryRun)
+
+	// Os mesmos campos que createAdSet lê da campanha criada
+	plannedCampaign := map[string]any{
+		"daily_budget":    c.DailyBudget,
+		"lifetime_budget": c.LifetimeBudget,
+		"bid_strategy":    c.BidStrategy,
+	}
+	act := meta.Act(adAccount.AdAccountID)
+
+	for i, as := range spec.AdSets {
+		adsetRef := fmt.Sprintf("adsets[%d]", i)
+		adset, err := s.AdSets.planAdSet(ctx, adAccount, plannedCampaign, CreateAdSetInput{
+			AdAccountID:      spec.AdAccountID,
+			CampaignID:       "{campaign}",
+			Name:             as.Name,
+			BillingEvent:     as.BillingEvent,
+			OptimizationGoal: as.OptimizationGoal,
+			BidStrategy:      as.BidStrategy,
+			BidAmount:        as.BidAmount,
+			BidConstraints:   as.BidConstraints,
+			DailyBudget:      as.DailyBudget,
+			LifetimeBudget:   as.LifetimeBudget,
+			StartTime:        as.StartTime,
+			EndTime:          as.EndTime,
+			AdSetSchedule:    as.AdSetSchedule,
+			Targeting:        as.Targeting,
+			Status:           as.Status,
+		})
+		if err != nil {
+			return LaunchPlan{}, fmt.Errorf("validate adset %d (%s): %w", i, as.Name, err)
+		}
+		add("adset", adsetRef, as.Name, CheckedByLocal, adset)
+
+		for j, ad := range as.Ads {
+			adRef := fmt.Sprintf("%s.ads[%d]", adsetRef, j)
+			creativeID := ad.CreativeID
+			if ad.Creative != nil {
+				creativeRef := adRef + ".creative"
+				creativeID = "{" + creativeRef + "}"
+				add("creative", creativeRef, ad.Creative.Name, CheckedByLocal, localDryRun(act+"/adcreatives", launchCreativePayload(*ad.Creative, in.Files)))
+			}
+
+			payload := adPayload(CreateAdInput{
+				AdSetID:    "{" + adsetRef + "}",
+				CreativeID: creativeID,
+				Name:       ad.Name,
+				Status:     ad.Status,
+			})
+			add("ad", adRef, ad.Name, CheckedByLocal, localDryRun(act+"/ads", payload))
+		}
+	}
+
+	return plan, nil
+}
+
+// launchCreativePayload descreve o creative novo do plano: o upload (e o hash
+// ou ID do vídeo que a Meta devolveria) só acontece na execução
+func launchCreativePayload(cr LaunchCreativeSpec, files map[string]LaunchFile) map[string]any {
+	payload := map[string]any{
+		"type":        cr.Type,
+		"name":        cr.Name,
+		"link":        cr.Link,
+		"message":     cr.Message,
+		"headline":    cr.Headline,
+		"description": cr.Description,
+		"file":        files[cr.File].Name,
+	}
+	if cr.Type == "video" {
+		payload["thumbnail"] = files[cr.Thumbnail].Name
+	}
+	return payload
+}
+
+// launchOutcome é o resultado de execute: objetos criados, status final,
+// mensagem gravada no registro e o erro devolvido ao caller
+type launchOutcome struct {
+	objects []storage.LaunchObject
+	status  string
+	msg     *string
+	err     error
+}
+
+// execute roda os passos e, se algum falhar, faz o rollback do que foi criado
+func (s *LaunchService) execute(ctx context.Context, adAccount storage.AdAccount, in LaunchInput, track func([]storage.LaunchObject)) launchOutcome {
+	objects, runErr := s.run(ctx, adAccount, in, track)
+	if runErr == nil {
+		return launchOutcome{objects: objects, status: LaunchSucceeded}
+	}
+
+	// O erro devolvido mantém runErr na cadeia para o handler responder com o
+	// status do erro da Meta. O rollback não pode ser interrompido pelo
+	// cancelamento do caller.
+	out := launchOutcome{objects: objects, status: LaunchRolledBack, err: fmt.Errorf("%w: %w", ErrLaunchFailed, runErr)}
+	msg := runErr.Error()
+	if rbErr := s.rollback(context.WithoutCancel(ctx), adAccount, objects); rbErr != nil {
+		out.status = LaunchFailed
+		msg = fmt.Sprintf("%s; rollback: %s", msg, rbErr.Error())
+		out.err = fmt.Errorf("%w: %w; rollback: %v", ErrLaunchFailed, runErr, rbErr)
+	}
+	out.msg = &msg
+	return out
+}
+
+// run executa os passos em ordem e devolve tudo que foi criado até o ponto de falha
+func (s *LaunchService) run(ctx context.Context, adAccount storage.AdAccount, in LaunchInput, track func([]storage.LaunchObject)) ([]storage.LaunchObject, error) {
+	spec := in.Spec
+	var objects []storage.LaunchObject
+	add := func(obj storage.LaunchObject) {
+		objects = append(objects, obj)
+		track(objects)
+	}
+
+	c := spec.Campaign
+	campaign, err := s.Campaigns.createCampaign(ctx, adAccount, CreateCampaignInput{
+		AdAccountID:                 spec.AdAccountID,
+		Name:                        c.Name,
+		Objective:                   c.Objective,
+		Status:                      c.Status,
+		SpecialAdCategories:         c.SpecialAdCategories,
+		BuyingType:                  c.BuyingType,
+		IsAdSetBudgetSharingEnabled: c.IsAdSetBudgetSharingEnabled,
+		DailyBudget:                 c.DailyBudget,
+		LifetimeBudget:              c.LifetimeBudget,
+		SpendCap:                    c.SpendCap,
+		BidStrategy:                 c.BidStrategy,
+	})
+	if err != nil {
+		return objects, fmt.Errorf("create campaign: %w", err)
+	}
+	add(storage.LaunchObject{Type: "campaign", ID: campaign.CampaignID, Name: c.Name})
+
+	for i, as := range spec.AdSets {
+		adset, err := s.AdSets.createAdSet(ctx, adAccount, CreateAdSetInput{
+			AdAccountID:      spec.AdAccountID,
+			CampaignID:       campaign.CampaignID,
+			Name:             as.Name,
+			BillingEvent:     as.BillingEvent,
+			OptimizationGoal: as.OptimizationGoal,
+			BidStrategy:      as.BidStrategy,
+			BidAmount:        as.BidAmount,
+			BidConstraints:   as.BidConstraints,
+			DailyBudget:      as.DailyBudget,
+			LifetimeBudget:   as.LifetimeBudget,
+			StartTime:        as.StartTime,
+			EndTime:          as.EndTime,
+			AdSetSchedule:    as.AdSetSchedule,
+			Targeting:        as.Targeting,
+			Status:           as.Status,
+		})
+		if err != nil {
+			return objects, fmt.Errorf("create adset %d (%s): %w", i, as.Name, err)
+		}
+		add(storage.LaunchObject{Type: "adset", ID: adset.AdSetID, Name: as.Name, ParentID: campaign.CampaignID})
+
+		for j, ad := range as.Ads {
+			creativeID := ad.CreativeID
+			if ad.Creative != nil {
+				creativeID, err = s.createCreative(ctx, spec.AdAccountID, *ad.Creative, in.Files)
+				if err != nil {
+					return objects, fmt.Errorf("create creative for adset %d ad %d: %w", i, j, err)
+				}
+				add(storage.LaunchObject{Type: "creative", ID: creativeID, Name: ad.Creative.Name})
+			}
+
+			created, err := s.Ads.createAd(ctx, adAccount, CreateAdInput{
+				AdAccountID: spec.AdAccountID,
+				AdSetID:     adset.AdSetID,
+				CreativeID:  creativeID,
+				Name:        ad.Name,
+				Status:      ad.Status,
+			})
+			if err != nil {
+				return objects, fmt.Errorf("create ad %d in adset %d (%s): %w", j, i, ad.Name, err)
+			}
+			add(storage.LaunchObject{Type: "ad", ID: created.AdID, Name: ad.Name, ParentID: adset.AdSetID})
+		}
+	}
+
+	return objects, nil
+}
+
+func (s *LaunchService) createCreative(ctx context.Context, adAccountID string, cr LaunchCreativeSpec, files map[string]LaunchFile) (string, error) {
+	file := files[cr.File]
+
+	if cr.Type == "video" {
+		thumb := files[cr.Thumbnail]
+		out, err := s.Creatives.CreateVideoCreative(ctx, VideoCreativeInput{
+			AdAccountID: adAccountID,
+			Name:        cr.Name,
+			Link:        cr.Link,
+			Message:     cr.Message,
+			Headline:    cr.Headline,
+			Description: cr.Description,
+			VideoName:   file.Name,
+			VideoBytes:  file.Bytes,
+			ThumbName:   thumb.Name,
+			ThumbBytes:  thumb.Bytes,
+		})
+		if err != nil {
+			return "", err
+		}
+		return out.CreativeID, nil
+	}
+
+	out, err := s.Creatives.CreateImageCreative(ctx, ImageCreativeInput{
+		AdAccountID: adAccountID,
+		Name:        cr.Name,
+		Link:        cr.Link,
+		Message:     cr.Message,
+		Headline:    cr.Headline,
+		Description: cr.Description,
+		ImageName:   file.Name,
+		ImageBytes:  file.Bytes,
+	})
+	if err != nil {
+		return "", err
+	}
+	return out.CreativeID, nil
+}
+
+// rollback remove ads, adsets e a campaign na ordem inversa de criação.
+// Creatives não são removidos: ficam na biblioteca e podem ser reaproveitados.
+func (s *LaunchService) rollback(ctx context.Context, adAccount storage.AdAccount, objects []storage.LaunchObject) error {
+	adAccountID := adAccount.AdAccountID
+	var errs []error
+	for i := len(objects) - 1; i >= 0; i-- {
+		obj := objects[i]
+		var err error
+		switch obj.Type {
+		case "ad":
+			_, err = s.Ads.deleteAd(ctx, adAccount, DeleteAdInput{AdAccountID: adAccountID, AdID: obj.ID})
+		case "adset":
+			_, err = s.AdSets.deleteAdSet(ctx, adAccount, DeleteAdSetInput{AdAccountID: adAccountID, AdSetID: obj.ID})
+		case "campaign":
+			_, err = s.Campaigns.deleteCampaign(ctx, adAccount, DeleteCampaignInput{AdAccountID: adAccountID, CampaignID: obj.ID})
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("delete %s %s: %w", obj.Type, obj.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *LaunchService) GetLaunch(ctx context.Context, launchID string) (storage.Launch, error) {
+	launch, err := s.Store.GetLaunch(ctx, launchID)
+	if err != nil {
+		return storage.Launch{}, err
+	}
+	if err := auth.CheckClient(ctx, launch.ClientUUID); err != nil {
+		return storage.Launch{}, err
+	}
+	return launch, nil
+}
+
+func (s *LaunchService) ListLaunches(ctx context.Context, adAccountID string) ([]storage.Launch, error) {
+	if err := checkAdAccount(ctx, s.Store, adAccountID); err != nil {
+		return nil, err
+	}
+	return s.Store.ListLaunches(ctx, adAccountID)
+}
+
+// Undo desfaz um launch concluído removendo todos os objetos criados
+func (s *LaunchService) Undo(ctx context.Context, launchID string) (storage.Launch, error) {
+	launch, err := s.Store.GetLaunch(ctx, launchID)
+	if err != nil {
+		return storage.Launch{}, fmt.Errorf("get launch: %w", err)
+	}
+	if err := auth.CheckClient(ctx, launch.ClientUUID); err != nil {
+		return storage.Launch{}, err
+	}
+	if launch.Status != LaunchSucceeded && launch.Status != LaunchFailed {
+		return launch, fmt.Errorf("launch cannot be undone in status %s", launch.Status)
+	}
+
+	adAccount, err := getAdAccount(ctx, s.Store, launch.AdAccountID)
+	if err != nil {
+		return launch, fmt.Errorf("get ad account: %w", err)
+	}
+	if err := s.rollback(ctx, adAccount, launch.CreatedObjects); err != nil {
+		msg := fmt.Sprintf("undo: %s", err.Error())
+		_ = s.Store.UpdateLaunch(ctx, launch.LaunchID, LaunchFailed, launch.CreatedObjects, &msg)
+		return launch, err
+	}
+
+	if err := s.Store.UpdateLaunch(ctx, launch.LaunchID, LaunchUndone, launch.CreatedObjects, launch.Error); err != nil {
+		return launch, fmt.Errorf("save launch: %w", err)
+	}
+	return s.Store.GetLaunch(ctx, launch.LaunchID)
+}
+
+// applyLaunchDefaults usa os mesmos defaults dos endpoints individuais
+func applyLaunchDefaults(spec *LaunchSpec) {
+	if spec.Campaign.Status == "" {
+		spec.Campaign.Status = "PAUSED"
+	}
+	if spec.Campaign.SpecialAdCategories == nil {
+		spec.Campaign.SpecialAdCategories = []string{}
+	}
+	if spec.Campaign.BuyingType == "" {
+		spec.Campaign.BuyingType = "AUCTION"
+	}
+	for i := range spec.AdSets {
+		if spec.AdSets[i].Status == "" {
+			spec.AdSets[i].Status = "PAUSED"
+		}
+		for j := range spec.AdSets[i].Ads {
+			if spec.AdSets[i].Ads[j].Status == "" {
+				spec.AdSets[i].Ads[j].Status = "PAUSED"
+			}
+		}
+	}
+}
+
+func validateLaunchSpec(in LaunchInput) error {
+	spec := in.Spec
+	if spec.AdAccountID == "" {
+		return fmt.Errorf("missing ad_account_id")
+	}
+	if spec.Campaign.Name == "" {
+		return fmt.Errorf("missing campaign.name")
+	}
+	if spec.Campaign.Objective == "" {
+		return fmt.Errorf("missing campaign.objective")
+	}
+	c := spec.Campaign
+	if err := validateCampaignBudget(c.DailyBudget, c.LifetimeBudget, c.SpendCap, c.BidStrategy); err != nil {
+		return fmt.Errorf("campaign: %w", err)
+	}
+	if len(spec.AdSets) == 0 {
+		return fmt.Errorf("missing adsets")
+	}
+
+	for i, as := range spec.AdSets {
+		if as.Name == "" {
+			return fmt.Errorf("adsets[%d]: missing name", i)
+		}
+		if as.BillingEvent == "" {
+			return fmt.Errorf("adsets[%d]: missing billing_event", i)
+		}
+		if as.OptimizationGoal == "" {
+			return fmt.Errorf("adsets[%d]: missing optimization_goal", i)
+		}
+		if err := validateAdSetBudget(as.DailyBudget, as.LifetimeBudget, as.EndTime); err != nil {
+			return fmt.Errorf("adsets[%d]: %w", i, err)
+		}
+		if err := validateTargeting(as.Targeting); err != nil {
+			return fmt.Errorf("adsets[%d]: %w", i, err)
+		}
+
+		for j, ad := range as.Ads {
+			if ad.Name == "" {
+				return fmt.Errorf("adsets[%d].ads[%d]: missing name", i, j)
+			}
+			if (ad.CreativeID == "") == (ad.Creative == nil) {
+				return fmt.Errorf("adsets[%d].ads[%d]: exactly one of creative_id or creative is required", i, j)
+			}
+			if ad.Creative == nil {
+				continue
+			}
+
+			cr := ad.Creative
+			if cr.Type != "image" && cr.Type != "video" {
+				return fmt.Errorf("adsets[%d].ads[%d]: invalid creative type %q", i, j, cr.Type)
+			}
+			if _, ok := in.Files[cr.File]; !ok || cr.File == "" {
+				return fmt.Errorf("adsets[%d].ads[%d]: missing creative file %q", i, j, cr.File)
+			}
+			if cr.Type == "video" {
+				if _, ok := in.Files[cr.Thumbnail]; !ok || cr.Thumbnail == "" {
+					return fmt.Errorf("adsets[%d].ads[%d]: missing creative thumbnail %q", i, j, cr.Thumbnail)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"creative-service/internal/meta/metatest"
+	"creative-service/internal/storage"
+	"creative-service/internal/targeting"
+)
+
+// testLaunchSpec monta um launch válido com um adset e um ad de creative existente
+func testLaunchSpec(adAccountID string) LaunchSpec {
+	return LaunchSpec{
+		AdAccountID: adAccountID,
+		Campaign:    LaunchCampaignSpec{Name: "launch", Objective: "OUTCOME_TRAFFIC"},
+		AdSets: []LaunchAdSetSpec{{
+			Name:             "adset",
+			BillingEvent:     "IMPRESSIONS",
+			OptimizationGoal: "LINK_CLICKS",
+			DailyBudget:      2000,
+			Targeting:        &targeting.Spec{GeoLocations: &targeting.GeoLocations{Countries: []string{"BR"}}},
+			Ads:              []LaunchAdSpec{{Name: "ad", CreativeID: "123"}},
+		}},
+	}
+}
+
+func TestValidateLaunchSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(in *LaunchInput)
+		wantErr string
+	}{
+		{name: "valid", edit: func(in *LaunchInput) {}},
+		{name: "missing ad account", edit: func(in *LaunchInput) { in.Spec.AdAccountID = "" }, wantErr: "missing ad_account_id"},
+		{name: "missing objective", edit: func(in *LaunchInput) { in.Spec.Campaign.Objective = "" }, wantErr: "missing campaign.objective"},
+		{name: "campaign budget", edit: func(in *LaunchInput) { in.Spec.Campaign.SpendCap = 100; in.Spec.Campaign.LifetimeBudget = 500 }, wantErr: "campaign: spend_cap"},
+		{name: "no adsets", edit: func(in *LaunchInput) { in.Spec.AdSets = nil }, wantErr: "missing adsets"},
+		{name: "adset budget", edit: func(in *LaunchInput) { in.Spec.AdSets[0].LifetimeBudget = 1000 }, wantErr: "adsets[0]: daily_budget and lifetime_budget"},
+		{name: "missing targeting", edit: func(in *LaunchInput) { in.Spec.AdSets[0].Targeting = nil }, wantErr: "adsets[0]: missing targeting"},
+		{name: "invalid targeting", edit: func(in *LaunchInput) { in.Spec.AdSets[0].Targeting.AgeMin = 10 }, wantErr: "age_min"},
+		{
+			name:    "creative id and creative",
+			edit:    func(in *LaunchInput) { in.Spec.AdSets[0].Ads[0].Creative = &LaunchCreativeSpec{Type: "image"} },
+			wantErr: "adsets[0].ads[0]: exactly one of creative_id or creative",
+		},
+		{
+			name: "creative file missing from multipart",
+			edit: func(in *LaunchInput) {
+				in.Spec.AdSets[0].Ads[0] = LaunchAdSpec{Name: "ad", Creative: &LaunchCreativeSpec{Type: "image", File: "image"}}
+			},
+			wantErr: `missing creative file "image"`,
+		},
+		{
+			name: "video without thumbnail",
+			edit: func(in *LaunchInput) {
+				in.Spec.AdSets[0].Ads[0] = LaunchAdSpec{Name: "ad", Creative: &LaunchCreativeSpec{Type: "video", File: "video"}}
+				in.Files = map[string]LaunchFile{"video": {Name: "v.mp4"}}
+			},
+			wantErr: "missing creative thumbnail",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := LaunchInput{Spec: testLaunchSpec("act_1")}
+			tt.edit(&in)
+			checkErr(t, validateLaunchSpec(in), tt.wantErr)
+		})
+	}
+}
+
+// staticTokens resolve qualquer token_ref para o mesmo token
+type staticTokens string
+
+func (t staticTokens) Resolve(string) (string, error) { return string(t), nil }
+
+func TestLaunchExecute(t *testing.T) {
+	tests := []struct {
+		name        string
+		failOn      string // edge do POST que falha; "" não injeta falha
+		wantStatus  string
+		wantDeleted []string // tipos removidos no rollback
+		wantLive    []string // tipos que continuam ativos
+	}{
+		{name: "success", wantStatus: LaunchSucceeded, wantLive: []string{metatest.TypeCampaign, metatest.TypeAdSet, metatest.TypeAd}},
+		{name: "ad fails", failOn: "ads", wantStatus: LaunchRolledBack, wantDeleted: []string{metatest.TypeCampaign, metatest.TypeAdSet}},
+		{name: "adset fails", failOn: "adsets", wantStatus: LaunchRolledBack, wantDeleted: []string{metatest.TypeCampaign}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := metatest.NewServer()
+			defer srv.Close()
+			if tt.failOn != "" {
+				srv.Inject(metatest.Fault{Method: "POST", Path: tt.failOn, Error: metatest.ErrInvalidParameter})
+			}
+
+			tokens, factory, sched := staticTokens("token"), srv.ClientFactory(), NewScheduler(4, 0, 0)
+			s := &LaunchService{
+				Campaigns: &CampaignService{Tokens: tokens, Meta: factory, Sched: sched},
+				AdSets:    &AdSetService{Tokens: tokens, Meta: factory, Sched: sched},
+				Ads:       &AdService{Tokens: tokens, Meta: factory, Sched: sched},
+			}
+
+			in := LaunchInput{Spec: testLaunchSpec("act_1")}
+			applyLaunchDefaults(&in.Spec)
+			var tracked int
+			out := s.execute(context.Background(), storage.AdAccount{AdAccountID: "act_1", TokenRef: "ENV:TOKEN"}, in, func(objects []storage.LaunchObject) {
+				tracked = len(objects)
+			})
+
+			if tt.wantStatus == LaunchSucceeded && (out.err != nil || out.msg != nil) {
+				t.Fatalf("err = %v", out.err)
+			}
+			if tt.wantStatus != LaunchSucceeded && (!errors.Is(out.err, ErrLaunchFailed) || out.msg == nil) {
+				t.Fatalf("err = %v, want ErrLaunchFailed", out.err)
+			}
+			if out.status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", out.status, tt.wantStatus)
+			}
+
+			for _, typ := range tt.wantDeleted {
+				for _, o := range srv.Objects(typ, "act_1") {
+					if o.Fields["status"] != "DELETED" {
+						t.Errorf("%s %s status = %v, want DELETED", typ, o.ID, o.Fields["status"])
+					}
+				}
+			}
+			for _, typ := range tt.wantLive {
+				objects := srv.Objects(typ, "act_1")
+				if len(objects) != 1 || objects[0].Fields["status"] == "DELETED" {
+					t.Errorf("%s: %d objects, want 1 live", typ, len(objects))
+				}
+			}
+			want := len(tt.wantDeleted) + len(tt.wantLive)
+			if len(out.objects) != want || tracked != want {
+				t.Errorf("objects = %d tracked = %d, want %d", len(out.objects), tracked, want)
+			}
+		})
+	}
+}
+
+func TestLaunchPlan(t *testing.T) {
+	tests := []struct {
+		name      string
+		edit      func(in *LaunchInput)
+		fault     *metatest.Fault
+		wantTypes []string
+		wantValid bool
+		invalid   string // ref do passo recusado
+		creative  string // creative_id esperado no payload do ad
+	}{
+		{name: "valid", edit: func(in *LaunchInput) {}, wantTypes: []string{"campaign", "adset", "ad"}, wantValid: true, creative: "123"},
+		{
+			name: "new creative",
+			edit: func(in *LaunchInput) {
+				in.Spec.AdSets[0].Ads[0] = LaunchAdSpec{Name: "ad", Creative: &LaunchCreativeSpec{Type: "image", Name: "cr", File: "img"}}
+				in.Files = map[string]LaunchFile{"img": {Name: "a.jpg", Bytes: []byte("jpg")}}
+			},
+			wantTypes: []string{"campaign", "adset", "creative", "ad"},
+			wantValid: true,
+			creative:  "{adsets[0].ads[0].creative}",
+		},
+		{
+			name:      "meta rejects campaign",
+			edit:      func(in *LaunchInput) {},
+			fault:     &metatest.Fault{Method: "POST", Path: "campaigns", Error: metatest.ErrInvalidParameter},
+			wantTypes: []string{"campaign", "adset", "ad"},
+			invalid:   "campaign",
+		},
+		{
+			name:      "adset budget conflicts with campaign budget",
+			edit:      func(in *LaunchInput) { in.Spec.Campaign.DailyBudget = 5000 },
+			wantTypes: []string{"campaign", "adset", "ad"},
+			invalid:   "adsets[0]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := metatest.NewServer()
+			defer srv.Close()
+			if tt.fault != nil {
+				srv.Inject(*tt.fault)
+			}
+
+			tokens, factory, sched := staticTokens("token"), srv.ClientFactory(), NewScheduler(4, 0, 0)
+			s := &LaunchService{
+				Campaigns: &CampaignService{Tokens: tokens, Meta: factory, Sched: sched},
+				AdSets:    &AdSetService{Tokens: tokens, Meta: factory, Sched: sched},
+				Ads:       &AdService{Tokens: tokens, Meta: factory, Sched: sched},
+			}
+
+			in := LaunchInput{Spec: testLaunchSpec("act_1")}
+			tt.edit(&in)
+			applyLaunchDefaults(&in.Spec)
+			plan, err := s.plan(context.Background(), storage.AdAccount{AdAccountID: "act_1", TokenRef: "ENV:TOKEN"}, in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !plan.DryRun || plan.Valid != tt.wantValid || len(plan.Steps) != len(tt.wantTypes) {
+				t.Fatalf("plan = dry_run %t valid %t steps %d, want valid %t steps %d", plan.DryRun, plan.Valid, len(plan.Steps), tt.wantValid, len(tt.wantTypes))
+			}
+			for i, step := range plan.Steps {
+				if step.Type != tt.wantTypes[i] {
+					t.Errorf("steps[%d] = %s, want %s", i, step.Type, tt.wantTypes[i])
+				}
+				if wantInvalid := step.Ref == tt.invalid; step.DryRun.Valid == wantInvalid || (step.DryRun.Error != nil) != wantInvalid {
+					t.Errorf("%s: valid = %t error = %+v", step.Ref, step.DryRun.Valid, step.DryRun.Error)
+				}
+			}
+
+			// Só a campanha vai para a Meta, com validate_only; nada é criado
+			if n := srv.Count("POST", "campaigns"); n != 1 {
+				t.Errorf("campaign validations = %d, want 1", n)
+			}
+			if n := srv.Count("POST", "adsets") + srv.Count("POST", "ads") + srv.Count("POST", "adcreatives"); n != 0 {
+				t.Errorf("child requests = %d, want 0", n)
+			}
+			for _, typ := range []string{metatest.TypeCampaign, metatest.TypeAdSet, metatest.TypeAd} {
+				if objects := srv.Objects(typ, "act_1"); len(objects) != 0 {
+					t.Errorf("%s: %d objects created", typ, len(objects))
+				}
+			}
+
+			// Pais que ainda não existem vão como a ref deles entre chaves
+			adset, ad := plan.Steps[1], plan.Steps[len(plan.Steps)-1]
+			if adset.CheckedBy != CheckedByLocal || ad.CheckedBy != CheckedByLocal || plan.Steps[0].CheckedBy != CheckedByMeta {
+				t.Errorf("checked_by = %s, %s, %s", plan.Steps[0].CheckedBy, adset.CheckedBy, ad.CheckedBy)
+			}
+			if adset.DryRun.Payload != nil && adset.DryRun.Payload["campaign_id"] != "{campaign}" {
+				t.Errorf("adset campaign_id = %v", adset.DryRun.Payload["campaign_id"])
+			}
+			if ad.DryRun.Payload["adset_id"] != "{adsets[0]}" {
+				t.Errorf("ad adset_id = %v", ad.DryRun.Payload["adset_id"])
+			}
+			if creative := ad.DryRun.Payload["creative"].(map[string]any); tt.creative != "" && creative["creative_id"] != tt.creative {
+				t.Errorf("ad creative = %v, want %s", creative, tt.creative)
+			}
+		})
+	}
+}
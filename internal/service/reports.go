@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/secrets"
+	"creative-service/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// ======= ASYNC INSIGHTS REPORTS =======
+// Reports grandes rodam como job no worker: o job dispara o report run na Meta,
+// acompanha async_status e grava as linhas em report_rows quando termina.
+
+// ErrReportNotFound indica que o report não existe (ou o job não é um report)
+var ErrReportNotFound = errors.New("report not found")
+
+const (
+	JobTypeInsightsReport = "insights_report"
+
+	reportPageSize        = 500
+	reportMaxRowsPerPage  = 1000
+	defaultReportPoll     = 10 * time.Second
+	defaultReportMaxWait  = time.Hour
+	defaultReportRowsPage = 100
+	reportRenewEvery      = 30 * time.Second // renovação do lease do job durante a paginação
+)
+
+type ReportService struct {
+	Store  *storage.Store
+	Tokens secrets.Resolver
+
+	Meta *meta.ClientFactory
+
+	Sched *Scheduler
+
+	PollInterval time.Duration // default 10s
+	MaxWait      time.Duration // default 1h por tentativa
+}
+
+// ReportProgress é o result_json do job: permite retomar o mesmo report run
+// se o worker reiniciar no meio do polling
+type ReportProgress struct {
+	ReportRunID string `json:"report_run_id,omitempty"`
+	AsyncStatus string `json:"async_status,omitempty"`
+	Percent     int    `json:"percent"`
+	RowCount    int    `json:"row_count"`
+}
+
+type ReportOutput struct {
+	ReportID  string            `json:"report_id"`
+	Status    string            `json:"status"` // queued, running, succeeded, failed
+	Input     json.RawMessage   `json:"input"`
+	Progress  ReportProgress    `json:"progress"`
+	Error     *string           `json:"error,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Total     int               `json:"total"`
+	Limit     int               `json:"limit,omitempty"`
+	Offset    int               `json:"offset,omitempty"`
+	Rows      []json.RawMessage `json:"rows,omitempty"` // só quando succeeded
+}
+
+// CreateReport valida o input e enfileira o job
+func (s *ReportService) CreateReport(ctx context.Context, in InsightsInput) (ReportOutput, error) {
+	if _, err := insightsParams(&in); err != nil {
+		return ReportOutput{}, err
+	}
+
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return ReportOutput{}, fmt.Errorf("get ad account: %w", err)
+	}
+	in.AdAccountID = adAccount.AdAccountID
+
+	input, err := json.Marshal(in)
+	if err != nil {
+		return ReportOutput{}, fmt.Errorf("encode input: %w", err)
+	}
+
+	job, err := s.Store.CreateJob(ctx, storage.Job{
+		JobID:       uuid.New().String(),
+		ClientUUID:  &adAccount.ClientUUID,
+		AdAccountID: &adAccount.AdAccountID,
+		JobType:     JobTypeInsightsReport,
+		Input:       input,
+	})
+	if err != nil {
+		return ReportOutput{}, fmt.Errorf("save job: %w", err)
+	}
+	return reportOutput(job), nil
+}
+
+// GetReport devolve o status do report e, quando concluído, uma página das linhas
+func (s *ReportService) GetReport(ctx context.Context, reportID string, limit, offset int) (ReportOutput, error) {
+	job, err := s.Store.GetJob(ctx, reportID)
+	if errors.Is(err, storage.ErrJobNotFound) {
+		return ReportOutput{}, ErrReportNotFound
+	}
+	if err != nil {
+		return ReportOutput{}, fmt.Errorf("get report: %w", err)
+	}
+	// Outros tipos de job não são reports: mesmo 404 de um ID inexistente
+	if job.JobType != JobTypeInsightsReport {
+		return ReportOutput{}, ErrReportNotFound
+	}
+	if err := checkJobClient(ctx, job); err != nil {
+		return ReportOutput{}, err
+	}
+
+	out := reportOutput(job)
+	if job.Status != storage.JobSucceeded {
+		return out, nil
+	}
+
+	if limit <= 0 {
+		limit = defaultReportRowsPage
+	}
+	if limit > reportMaxRowsPerPage {
+		limit = reportMaxRowsPerPage
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, total, err := s.Store.ListReportRows(ctx, reportID, limit, offset)
+	if err != nil {
+		return out, fmt.Errorf("list report rows: %w", err)
+	}
+	out.Rows, out.Total, out.Limit, out.Offset = rows, total, limit, offset
+	return out, nil
+}
+
+// EachReportRow percorre todas as linhas de um report concluído, em páginas,
+// sem carregar o report inteiro em memória (export CSV/XLSX)
+func (s *ReportService) EachReportRow(ctx context.Context, reportID string, fn func(row json.RawMessage) error) error {
+	for offset := 0; ; offset += reportMaxRowsPerPage {
+		rows, _, err := s.Store.ListReportRows(ctx, reportID, reportMaxRowsPerPage, offset)
+		if err != nil {
+			return fmt.Errorf("list report rows: %w", err)
+		}
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if len(rows) < reportMaxRowsPerPage {
+			return nil
+		}
+	}
+}
+
+func reportOutput(job storage.Job) ReportOutput {
+	out := ReportOutput{
+		ReportID:  job.JobID,
+		Status:    job.Status,
+		Input:     job.Input,
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+	if len(job.Result) > 0 {
+		_ = json.Unmarshal(job.Result, &out.Progress)
+	}
+	out.Total = out.Progress.RowCount
+	return out
+}
+
+// RunReportJob é o JobHandler de insights_report
+func (s *ReportService) RunReportJob(ctx context.Context, job storage.Job, progress func(any) error) (any, error) {
+	var in InsightsInput
+	if err := json.Unmarshal(job.Input, &in); err != nil {
+		return nil, fmt.Errorf("%w: decode input: %v", ErrPermanent, err)
+	}
+	params, err := insightsParams(&in)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+
+	var p ReportProgress
+	if len(job.Result) > 0 {
+		_ = json.Unmarshal(job.Result, &p)
+	}
+
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("get ad account: %w", err)
+	}
+	token, err := s.Tokens.Resolve(adAccount.TokenRef)
+	if err != nil {
+		return nil, fmt.Errorf("resolve token: %w", err)
+	}
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	// Cada chamada à Meta pega vaga no scheduler; o polling em si não ocupa vaga
+	call := func(fn func() error) error {
+		lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityBackground))
+		if err != nil {
+			return err
+		}
+		defer lease.Release()
+		return fn()
+	}
+
+	if p.ReportRunID == "" {
+		objectID := meta.Act(adAccount.AdAccountID)
+		if in.ObjectID != "" {
+			objectID = in.ObjectID
+		}
+		if err := call(func() (err error) {
+			p.ReportRunID, err = mc.StartAsyncInsights(ctx, objectID, params)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("start report run: %w", err)
+		}
+		if err := progress(p); err != nil {
+			return nil, fmt.Errorf("save progress: %w", err)
+		}
+	}
+
+	poll, maxWait := s.PollInterval, s.MaxWait
+	if poll <= 0 {
+		poll = defaultReportPoll
+	}
+	if maxWait <= 0 {
+		maxWait = defaultReportMaxWait
+	}
+	deadline := time.Now().Add(maxWait)
+
+	for {
+		var report meta.AsyncReport
+		if err := call(func() (err error) {
+			report, err = mc.GetAsyncReport(ctx, p.ReportRunID)
+			return err
+		}); err != nil {
+			return p, fmt.Errorf("get report run: %w", err)
+		}
+		p.AsyncStatus, p.Percent = report.AsyncStatus, report.AsyncPercentCompletion
+
+		switch report.AsyncStatus {
+		case meta.AsyncJobCompleted:
+			// Reports grandes levam vários minutos paginando: o progresso renova o
+			// lease do job para outro worker não retomá-lo no meio
+			lastRenew := time.Now()
+			onPage := func(fetched int) error {
+				if time.Since(lastRenew) < reportRenewEvery {
+					return nil
+				}
+				lastRenew = time.Now()
+				p.RowCount = fetched
+				if err := progress(p); err != nil {
+					return fmt.Errorf("save progress: %w", err)
+				}
+				return nil
+			}
+			var rows []map[string]any
+			if err := call(func() (err error) {
+				rows, err = mc.GetAsyncReportResults(ctx, p.ReportRunID, reportPageSize, onPage)
+				return err
+			}); err != nil {
+				return p, fmt.Errorf("get report results: %w", err)
+			}
+			if err := s.Store.ReplaceReportRows(ctx, job.JobID, rows); err != nil {
+				return p, fmt.Errorf("save report rows: %w", err)
+			}
+			p.RowCount = len(rows)
+			return p, nil
+		case meta.AsyncJobFailed, meta.AsyncJobSkipped:
+			// Próxima tentativa dispara um report run novo
+			status := report.AsyncStatus
+			p = ReportProgress{}
+			return p, fmt.Errorf("report run %s", status)
+		}
+
+		if err := progress(p); err != nil {
+			return nil, fmt.Errorf("save progress: %w", err)
+		}
+		if time.Now().After(deadline) {
+			return p, fmt.Errorf("report run %s not completed after %s", p.ReportRunID, maxWait)
+		}
+
+		select {
+		case <-ctx.Done():
+			return p, ctx.Err()
+		case <-time.After(poll):
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+	_ "time/tzdata" // a imagem final (alpine) não tem zoneinfo
+
+	"creative-service/internal/meta"
+)
+
+// ======= SCHEDULE (start/end time e dayparting) =======
+// Horários sem offset ("2026-11-28T00:00:00") são interpretados no fuso da ad
+// account, que é o mesmo fuso usado pela Meta para entregar o adset.
+
+const (
+	scheduleMinLifetime = 24 * time.Hour // lifetime budget exige ao menos 24h de veiculação
+	minutesPerDay       = 24 * 60
+)
+
+// Layouts aceitos em start_time/end_time, do mais para o menos específico
+var (
+	offsetTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05-0700"}
+	localTimeLayouts  = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+)
+
+// ScheduleBlock é um bloco de dayparting da Meta: minutos desde 00:00 (múltiplos
+// de 60) nos dias indicados (0 = domingo ... 6 = sábado)
+type ScheduleBlock struct {
+	StartMinute  int    `json:"start_minute"`
+	EndMinute    int    `json:"end_minute"`
+	Days         []int  `json:"days"`
+	TimezoneType string `json:"timezone_type,omitempty"` // USER (fuso de quem vê o anúncio) ou ADVERTISER
+}
+
+// adSetSchedule é o estado efetivo do agendamento de um adset
+type adSetSchedule struct {
+	StartTime      string
+	EndTime        string
+	Blocks         []ScheduleBlock
+	LifetimeBudget int
+	KeepTimes      bool // horários atuais do adset, não alterados no update
+}
+
+// normalizedSchedule traz os horários convertidos para RFC3339 com offset
+type normalizedSchedule struct {
+	StartTime string
+	EndTime   string
+	Blocks    []ScheduleBlock
+}
+
+// accountLocation busca o fuso da ad account na Meta
+func accountLocation(ctx context.Context, mc *meta.Client, adAccountID string) (*time.Location, error) {
+	info, err := mc.GetAdAccount(ctx, adAccountID)
+	if err != nil {
+		return nil, fmt.Errorf("get ad account timezone: %w", err)
+	}
+	if info.TimezoneName != "" {
+		if loc, err := time.LoadLocation(info.TimezoneName); err == nil {
+			return loc, nil
+		}
+	}
+	// Sem zoneinfo para o nome, só o offset é conhecido: a zona leva o nome da
+	// conta (ou nenhum), nunca "UTC" com um offset diferente de zero
+	return time.FixedZone(info.TimezoneName, int(math.Round(info.TimezoneOffsetHoursUTC*3600))), nil
+}
+
+// parseScheduleTime aceita horários com offset ou locais (no fuso loc)
+func parseScheduleTime(v string, loc *time.Location) (time.Time, error) {
+	for _, layout := range offsetTimeLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a valid time (use RFC3339 or YYYY-MM-DDTHH:MM:SS in the ad account timezone)", v)
+}
+
+// validateAdSetSchedule valida o agendamento efetivo do adset no fuso da conta
+// e devolve os horários normalizados
+func validateAdSetSchedule(sc adSetSchedule, loc *time.Location, now time.Time) (normalizedSchedule, error) {
+	var out normalizedSchedule
+	var start, end time.Time
+
+	if sc.StartTime != "" {
+		t, err := parseScheduleTime(sc.StartTime, loc)
+		if err != nil {
+			return out, fmt.Errorf("invalid start_time: %w", err)
+		}
+		start = t
+		out.StartTime = t.In(loc).Format(time.RFC3339)
+	}
+	if sc.EndTime != "" {
+		t, err := parseScheduleTime(sc.EndTime, loc)
+		if err != nil {
+			return out, fmt.Errorf("invalid end_time: %w", err)
+		}
+		if !sc.KeepTimes && !t.After(now) {
+			return out, fmt.Errorf("end_time must be in the future")
+		}
+		end = t
+		out.EndTime = t.In(loc).Format(time.RFC3339)
+	}
+
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		return out, fmt.Errorf("end_time must be after start_time")
+	}
+	if sc.LifetimeBudget > 0 && end.IsZero() {
+		return out, fmt.Errorf("lifetime_budget requires end_time")
+	}
+	if sc.LifetimeBudget > 0 && !sc.KeepTimes {
+		from := start
+		if from.IsZero() || from.Before(now) {
+			from = now
+		}
+		if end.Sub(from) < scheduleMinLifetime {
+			return out, fmt.Errorf("lifetime_budget requires end_time at least 24h after start_time")
+		}
+	}
+
+	if len(sc.Blocks) > 0 {
+		if sc.LifetimeBudget == 0 {
+			return out, fmt.Errorf("adset_schedule requires lifetime_budget")
+		}
+		blocks, err := validateScheduleBlocks(sc.Blocks)
+		if err != nil {
+			return out, err
+		}
+		out.Blocks = blocks
+	}
+	return out, nil
+}
+
+func validateScheduleBlocks(blocks []ScheduleBlock) ([]ScheduleBlock, error) {
+	out := make([]ScheduleBlock, 0, len(blocks))
+	for i, b := range blocks {
+		if b.StartMinute < 0 || b.EndMinute > minutesPerDay || b.StartMinute >= b.EndMinute {
+			return nil, fmt.Errorf("adset_schedule[%d]: start_minute must be before end_minute, both within 0-%d", i, minutesPerDay)
+		}
+		if b.StartMinute%60 != 0 || b.EndMinute%60 != 0 {
+			return nil, fmt.Errorf("adset_schedule[%d]: start_minute and end_minute must be multiples of 60", i)
+		}
+		if len(b.Days) == 0 {
+			return nil, fmt.Errorf("adset_schedule[%d]: days is required", i)
+		}
+		seen := map[int]struct{}{}
+		for _, d := range b.Days {
+			if d < 0 || d > 6 {
+				return nil, fmt.Errorf("adset_schedule[%d]: days must be between 0 (sunday) and 6 (saturday)", i)
+			}
+			if _, dup := seen[d]; dup {
+				return nil, fmt.Errorf("adset_schedule[%d]: duplicate day %d", i, d)
+			}
+			seen[d] = struct{}{}
+		}
+		switch b.TimezoneType {
+		case "":
+			b.TimezoneType = "USER"
+		case "USER", "ADVERTISER":
+		default:
+			return nil, fmt.Errorf("adset_schedule[%d]: timezone_type must be USER or ADVERTISER", i)
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"creative-service/internal/auth"
+	"creative-service/internal/meta"
+	"creative-service/internal/secrets"
+	"creative-service/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// ======= SCHEDULED ACTIONS =======
+// Mudanças futuras de status e orçamento executadas pelo worker através dos
+// mesmos services da API (a Meta não tem agendamento nativo para campanhas).
+
+const (
+	ActionSetStatus         = "set_status"
+	ActionSetDailyBudget    = "set_daily_budget"
+	ActionSetLifetimeBudget = "set_lifetime_budget"
+
+	scheduledMaxAttempts = 3
+	scheduledRetryDelay  = time.Minute // dobra a cada tentativa
+	scheduledLease       = 5 * time.Minute
+)
+
+var scheduledStatuses = map[string]struct{}{
+	"ACTIVE":   {},
+	"PAUSED":   {},
+	"ARCHIVED": {},
+}
+
+type ScheduledActionService struct {
+	Store  *storage.Store
+	Tokens secrets.Resolver
+
+	Meta *meta.ClientFactory
+
+	Sched *Scheduler
+
+	Campaigns *CampaignService
+	AdSets    *AdSetService
+	Ads       *AdService
+}
+
+type ScheduledActionParams struct {
+	Status string `json:"status,omitempty"` // set_status
+	Amount int    `json:"amount,omitempty"` // set_daily_budget / set_lifetime_budget (centavos)
+}
+
+type CreateScheduledActionInput struct {
+	AdAccountID    string
+	ObjectType     string // campaign, adset ou ad
+	ObjectID       string
+	Action         string
+	Params         ScheduledActionParams
+	RunAt          string // RFC3339, ou sem offset no fuso da ad account
+	IdempotencyKey string // opcional: repetir a chamada devolve a mesma ação
+}
+
+// Create agenda a ação; created=false quando a idempotency key já existia
+func (s *ScheduledActionService) Create(ctx context.Context, in CreateScheduledActionInput) (storage.ScheduledAction, bool, error) {
+	if err := validateScheduledAction(in); err != nil {
+		return storage.ScheduledAction{}, false, err
+	}
+
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return storage.ScheduledAction{}, false, fmt.Errorf("get ad account: %w", err)
+	}
+
+	runAt, err := s.parseRunAt(ctx, adAccount, in.RunAt)
+	if err != nil {
+		return storage.ScheduledAction{}, false, err
+	}
+	if !runAt.After(time.Now()) {
+		return storage.ScheduledAction{}, false, fmt.Errorf("run_at must be in the future")
+	}
+	if err := s.checkObject(ctx, adAccount, in.ObjectID); err != nil {
+		return storage.ScheduledAction{}, false, err
+	}
+
+	params, err := json.Marshal(in.Params)
+	if err != nil {
+		return storage.ScheduledAction{}, false, fmt.Errorf("encode params: %w", err)
+	}
+
+	a := storage.ScheduledAction{
+		ActionID:    uuid.New().String(),
+		ClientUUID:  adAccount.ClientUUID,
+		AdAccountID: adAccount.AdAccountID,
+		ObjectType:  in.ObjectType,
+		ObjectID:    in.ObjectID,
+		Action:      in.Action,
+		Params:      params,
+		RunAt:       runAt,
+		MaxAttempts: scheduledMaxAttempts,
+	}
+	if in.IdempotencyKey != "" {
+		a.IdempotencyKey = &in.IdempotencyKey
+	}
+
+	saved, created, err := s.Store.CreateScheduledAction(ctx, a)
+	if err != nil {
+		return storage.ScheduledAction{}, false, fmt.Errorf("save scheduled action: %w", err)
+	}
+	return saved, created, nil
+}
+
+// checkObject confere na Meta que o objeto é da ad account: o worker executa a
+// ação com o token da conta, que também enxerga outras contas do business
+func (s *ScheduledActionService) checkObject(ctx context.Context, adAccount storage.AdAccount, objectID string) error {
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
+	if err != nil {
+		return err
+	}
+	defer lease.Release()
+
+	token, err := s.Tokens.Resolve(adAccount.TokenRef)
+	if err != nil {
+		return fmt.Errorf("resolve token: %w", err)
+	}
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	return checkObjectsInAdAccount(ctx, mc, adAccount.AdAccountID, objectID)
+}
+
+// parseRunAt só consulta o fuso da conta na Meta quando run_at vem sem offset
+func (s *ScheduledActionService) parseRunAt(ctx context.Context, adAccount storage.AdAccount, v string) (time.Time, error) {
+	for _, layout := range offsetTimeLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer lease.Release()
+
+	token, err := s.Tokens.Resolve(adAccount.TokenRef)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("resolve token: %w", err)
+	}
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	loc, err := accountLocation(ctx, mc, adAccount.AdAccountID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := parseScheduleTime(v, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid run_at: %w", err)
+	}
+	return t, nil
+}
+
+func (s *ScheduledActionService) Get(ctx context.Context, actionID string) (storage.ScheduledAction, error) {
+	a, err := s.Store.GetScheduledAction(ctx, actionID)
+	if err != nil {
+		return storage.ScheduledAction{}, err
+	}
+	if err := auth.CheckClient(ctx, a.ClientUUID); err != nil {
+		return storage.ScheduledAction{}, err
+	}
+	return a, nil
+}
+
+func (s *ScheduledActionService) List(ctx context.Context, adAccountID, status string) ([]storage.ScheduledAction, error) {
+	if err := checkAdAccount(ctx, s.Store, adAccountID); err != nil {
+		return nil, err
+	}
+	return s.Store.ListScheduledActions(ctx, adAccountID, status)
+}
+
+// Cancel cancela uma ação pendente; ações já executadas ou em execução não mudam
+func (s *ScheduledActionService) Cancel(ctx context.Context, actionID string) (storage.ScheduledAction, error) {
+	if _, err := s.Get(ctx, actionID); err != nil {
+		return storage.ScheduledAction{}, err
+	}
+	return s.Store.CancelScheduledAction(ctx, actionID)
+}
+
+// RunDue executa até limit ações vencidas e devolve quantas foram processadas.
+// Chamado periodicamente pelo worker; vários workers podem rodar em paralelo.
+// As ações são reivindicadas uma a uma: o lease só começa a contar quando a ação
+// vai executar, e as da fila continuam livres para outros workers.
+func (s *ScheduledActionService) RunDue(ctx context.Context, limit int) (int, error) {
+	n := 0
+	for n < limit && ctx.Err() == nil {
+		actions, err := s.Store.ClaimDueScheduledActions(ctx, 1, scheduledLease)
+		if err != nil {
+			return n, fmt.Errorf("claim scheduled actions: %w", err)
+		}
+		if len(actions) == 0 {
+			break
+		}
+		s.run(ctx, actions[0])
+		n++
+	}
+	return n, nil
+}
+
+// run executa uma ação reivindicada e grava o resultado da tentativa
+func (s *ScheduledActionService) run(ctx context.Context, a storage.ScheduledAction) {
+	startedAt := time.Now()
+	runErr := s.execute(ctx, a)
+
+	var errText *string
+	var retryAt *time.Time
+	if runErr != nil {
+		msg := runErr.Error()
+		errText = &msg
+		if a.Attempts < a.MaxAttempts && ctx.Err() == nil && !meta.IsPermanent(runErr) {
+			t := time.Now().Add(scheduledRetryDelay << (a.Attempts - 1))
+			retryAt = &t
+		}
+		log.Printf("scheduled action %s (%s %s %s) attempt %d failed: %v", a.ActionID, a.Action, a.ObjectType, a.ObjectID, a.Attempts, runErr)
+	}
+
+	// Resultado precisa ser gravado mesmo se o worker estiver desligando
+	if err := s.Store.FinishScheduledAction(context.WithoutCancel(ctx), a, startedAt, errText, retryAt); err != nil {
+		log.Printf("scheduled action %s: save result: %v", a.ActionID, err)
+	}
+}
+
+func (s *ScheduledActionService) execute(ctx context.Context, a storage.ScheduledAction) error {
+	var p ScheduledActionParams
+	if err := json.Unmarshal(a.Params, &p); err != nil {
+		return fmt.Errorf("decode params: %w", err)
+	}
+
+	switch a.ObjectType {
+	case "campaign":
+		in := UpdateCampaignInput{AdAccountID: a.AdAccountID, CampaignID: a.ObjectID}
+		switch a.Action {
+		case ActionSetStatus:
+			in.Status = &p.Status
+		case ActionSetDailyBudget:
+			in.DailyBudget = &p.Amount
+		case ActionSetLifetimeBudget:
+			in.LifetimeBudget = &p.Amount
+		}
+		_, err := s.Campaigns.UpdateCampaign(ctx, in)
+		return err
+	case "adset":
+		in := UpdateAdSetInput{AdAccountID: a.AdAccountID, AdSetID: a.ObjectID}
+		switch a.Action {
+		case ActionSetStatus:
+			in.Status = &p.Status
+		case ActionSetDailyBudget:
+			in.DailyBudget = &p.Amount
+		case ActionSetLifetimeBudget:
+			in.LifetimeBudget = &p.Amount
+		}
+		_, err := s.AdSets.UpdateAdSet(ctx, in)
+		return err
+	case "ad":
+		if a.Action != ActionSetStatus {
+			return fmt.Errorf("action %s is not supported for ads", a.Action)
+		}
+		_, err := s.Ads.UpdateAd(ctx, UpdateAdInput{AdAccountID: a.AdAccountID, AdID: a.ObjectID, Status: &p.Status})
+		return err
+	}
+	return fmt.Errorf("unknown object_type: %q", a.ObjectType)
+}
+
+func validateScheduledAction(in CreateScheduledActionInput) error {
+	if in.AdAccountID == "" {
+		return fmt.Errorf("missing ad_account_id")
+	}
+	if in.ObjectID == "" {
+		return fmt.Errorf("missing object_id")
+	}
+	if in.RunAt == "" {
+		return fmt.Errorf("missing run_at")
+	}
+
+	switch in.ObjectType {
+	case "campaign", "adset", "ad":
+	default:
+		return fmt.Errorf("invalid object_type: %q (use campaign, adset or ad)", in.ObjectType)
+	}
+
+	switch in.Action {
+	case ActionSetStatus:
+		if _, ok := scheduledStatuses[in.Params.Status]; !ok {
+			return fmt.Errorf("set_status requires params.status ACTIVE, PAUSED or ARCHIVED")
+		}
+		if in.Params.Amount != 0 {
+			return fmt.Errorf("params.amount is not allowed with set_status")
+		}
+	case ActionSetDailyBudget, ActionSetLifetimeBudget:
+		if in.ObjectType == "ad" {
+			return fmt.Errorf("%s is not supported for ads", in.Action)
+		}
+		if in.Params.Amount <= 0 {
+			return fmt.Errorf("%s requires a positive params.amount", in.Action)
+		}
+		if in.Params.Status != "" {
+			return fmt.Errorf("params.status is not allowed with %s", in.Action)
+		}
+	default:
+		return fmt.Errorf("invalid action: %q", in.Action)
+	}
+	return nil
+}
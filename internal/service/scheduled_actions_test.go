@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"creative-service/internal/meta/metatest"
+	"creative-service/internal/storage"
+)
+
+func TestValidateScheduledAction(t *testing.T) {
+	base := CreateScheduledActionInput{
+		AdAccountID: "act_1",
+		ObjectType:  "adset",
+		ObjectID:    "1",
+		Action:      ActionSetStatus,
+		Params:      ScheduledActionParams{Status: "PAUSED"},
+		RunAt:       "2030-01-01T08:00:00-03:00",
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(in *CreateScheduledActionInput)
+		wantErr string
+	}{
+		{name: "set status", edit: func(in *CreateScheduledActionInput) {}},
+		{name: "daily budget", edit: func(in *CreateScheduledActionInput) {
+			in.Action, in.Params = ActionSetDailyBudget, ScheduledActionParams{Amount: 5000}
+		}},
+		{name: "missing run_at", edit: func(in *CreateScheduledActionInput) { in.RunAt = "" }, wantErr: "missing run_at"},
+		{name: "invalid object type", edit: func(in *CreateScheduledActionInput) { in.ObjectType = "creative" }, wantErr: "invalid object_type"},
+		{name: "deleted is not a scheduled status", edit: func(in *CreateScheduledActionInput) { in.Params.Status = "DELETED" }, wantErr: "requires params.status"},
+		{name: "status with amount", edit: func(in *CreateScheduledActionInput) { in.Params.Amount = 100 }, wantErr: "params.amount is not allowed"},
+		{name: "budget on ad", edit: func(in *CreateScheduledActionInput) {
+			in.ObjectType, in.Action, in.Params = "ad", ActionSetLifetimeBudget, ScheduledActionParams{Amount: 5000}
+		}, wantErr: "not supported for ads"},
+		{name: "budget without amount", edit: func(in *CreateScheduledActionInput) {
+			in.Action, in.Params = ActionSetDailyBudget, ScheduledActionParams{}
+		}, wantErr: "requires a positive params.amount"},
+		{name: "unknown action", edit: func(in *CreateScheduledActionInput) { in.Action = "delete" }, wantErr: "invalid action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := base
+			tt.edit(&in)
+			checkErr(t, validateScheduledAction(in), tt.wantErr)
+		})
+	}
+}
+
+func TestScheduledActionParseRunAt(t *testing.T) {
+	tests := []struct {
+		name      string
+		runAt     string
+		want      string // RFC3339 em UTC
+		wantCalls int    // leituras da ad account na Meta
+		wantErr   string
+	}{
+		{name: "with offset", runAt: "2030-01-01T08:00:00-03:00", want: "2030-01-01T11:00:00Z"},
+		{name: "utc", runAt: "2030-01-01T08:00:00Z", want: "2030-01-01T08:00:00Z"},
+		{name: "local time uses the account timezone", runAt: "2030-01-01T08:00:00", want: "2030-01-01T11:00:00Z", wantCalls: 1},
+		{name: "invalid", runAt: "tomorrow", wantCalls: 1, wantErr: "invalid run_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := metatest.NewServer()
+			defer srv.Close()
+			srv.SetAdAccount("act_1", map[string]any{"timezone_name": "America/Sao_Paulo", "timezone_offset_hours_utc": -3})
+
+			s := &ScheduledActionService{Tokens: staticTokens("token"), Meta: srv.ClientFactory(), Sched: NewScheduler(4, 0, 0)}
+			got, err := s.parseRunAt(context.Background(), storage.AdAccount{AdAccountID: "act_1", TokenRef: "ENV:TOKEN"}, tt.runAt)
+			checkErr(t, err, tt.wantErr)
+			if n := srv.Count("GET", "act_1"); n != tt.wantCalls {
+				t.Errorf("ad account reads = %d, want %d", n, tt.wantCalls)
+			}
+			if tt.wantErr != "" {
+				return
+			}
+			if s := got.UTC().Format(time.RFC3339); s != tt.want {
+				t.Errorf("run_at = %s, want %s", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduledActionCheckObject(t *testing.T) {
+	srv := metatest.NewServer()
+	defer srv.Close()
+	own := srv.AddObject(metatest.TypeCampaign, "act_1", nil)
+	other := srv.AddObject(metatest.TypeCampaign, "act_2", nil)
+
+	tests := []struct {
+		name     string
+		objectID string
+		wantErr  string
+	}{
+		{name: "owned", objectID: own},
+		{name: "other ad account of the business", objectID: other, wantErr: "object does not belong to ad account: " + other},
+		{name: "missing", objectID: "999", wantErr: "does not exist"},
+	}
+
+	s := &ScheduledActionService{Tokens: staticTokens("token"), Meta: srv.ClientFactory(), Sched: NewScheduler(4, 0, 0)}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.checkObject(context.Background(), storage.AdAccount{AdAccountID: "act_1", TokenRef: "ENV:TOKEN"}, tt.objectID)
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
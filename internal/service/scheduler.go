@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"creative-service/internal/storage"
+)
+
+// ======= SCHEDULER =======
+// Controla quantas chamadas à Meta rodam ao mesmo tempo no processo. Além do
+// limite global há limites por ad account e por token, para que um cliente
+// com dezenas de uploads não ocupe todas as vagas. Quem espera fica numa fila
+// por classe de prioridade; as classes dividem as vagas por peso (stride
+// scheduling), então interativas passam na frente sem matar bulk/background
+// de fome. Dentro da classe a vaga vai para a conta com menos chamadas em
+// andamento e, no empate, para quem chegou primeiro.
+
+type Priority int
+
+const (
+	PriorityInteractive Priority = iota // leituras e ações de usuário na API
+	PriorityBulk                        // uploads, bulk e cópias
+	PriorityBackground                  // jobs do worker (reports, sync de insights)
+	numPriorities
+)
+
+var priorityNames = [numPriorities]string{"interactive", "bulk", "background"}
+
+// Peso de cada classe: de cada 11 vagas disputadas, 8 vão para interativas
+var priorityWeights = [numPriorities]float64{8, 2, 1}
+
+func (p Priority) String() string {
+	if p < 0 || p >= numPriorities {
+		return "unknown"
+	}
+	return priorityNames[p]
+}
+
+// Slot identifica quem pede a vaga
+type Slot struct {
+	AdAccountID string
+	TokenRef    string
+	Priority    Priority
+}
+
+func accountSlot(a storage.AdAccount, p Priority) Slot {
+	return Slot{AdAccountID: a.AdAccountID, TokenRef: a.TokenRef, Priority: p}
+}
+
+type Scheduler struct {
+	global       int
+	perAdAccount int // <= 0: sem limite
+	perToken     int
+
+	mu        sync.Mutex
+	inFlight  int
+	byAccount map[string]int
+	byToken   map[string]int
+	queues    [numPriorities][]*waiter
+	pass      [numPriorities]float64
+	vtime     float64 // pass da última classe atendida
+	stats     [numPriorities]classStats
+}
+
+type waiter struct {
+	slot     Slot
+	enqueued time.Time
+	ready    chan struct{}
+	granted  bool
+}
+
+type classStats struct {
+	inFlight  int
+	acquired  int64
+	canceled  int64
+	waitTotal time.Duration
+	waitMax   time.Duration
+	waitLast  time.Duration
+}
+
+func NewScheduler(global, perAdAccount, perToken int) *Scheduler {
+	if global <= 0 {
+		global = 1
+	}
+	return &Scheduler{
+		global:       global,
+		perAdAccount: perAdAccount,
+		perToken:     perToken,
+		byAccount:    map[string]int{},
+		byToken:      map[string]int{},
+	}
+}
+
+// Lease é a vaga obtida em Acquire; Release pode ser chamado mais de uma vez
+type Lease struct {
+	s    *Scheduler
+	slot Slot
+	once sync.Once
+}
+
+func (l *Lease) Release() {
+	l.once.Do(func() { l.s.release(l.slot) })
+}
+
+// Acquire espera uma vaga para slot. Se ctx terminar antes, a espera sai da
+// fila e devolve ctx.Err().
+func (s *Scheduler) Acquire(ctx context.Context, slot Slot) (*Lease, error) {
+	if slot.Priority < 0 || slot.Priority >= numPriorities {
+		slot.Priority = PriorityInteractive
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	w := &waiter{slot: slot, enqueued: time.Now(), ready: make(chan struct{})}
+
+	s.mu.Lock()
+	p := slot.Priority
+	if len(s.queues[p]) == 0 && s.pass[p] < s.vtime {
+		s.pass[p] = s.vtime // classe ociosa não acumula crédito
+	}
+	s.queues[p] = append(s.queues[p], w)
+	s.dispatch()
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return &Lease{s: s, slot: slot}, nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	if w.granted {
+		// a vaga saiu junto com o cancelamento: devolve para o próximo
+		s.mu.Unlock()
+		s.release(slot)
+		return nil, ctx.Err()
+	}
+	s.remove(w)
+	s.stats[p].canceled++
+	s.mu.Unlock()
+	return nil, ctx.Err()
+}
+
+func (s *Scheduler) release(slot Slot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inFlight--
+	s.stats[slot.Priority].inFlight--
+	if s.byAccount[slot.AdAccountID]--; s.byAccount[slot.AdAccountID] <= 0 {
+		delete(s.byAccount, slot.AdAccountID)
+	}
+	if s.byToken[slot.TokenRef]--; s.byToken[slot.TokenRef] <= 0 {
+		delete(s.byToken, slot.TokenRef)
+	}
+	s.dispatch()
+}
+
+// dispatch entrega vagas livres enquanto houver alguém elegível; chamado com mu
+func (s *Scheduler) dispatch() {
+	for s.inFlight < s.global {
+		class, idx := -1, -1
+		for p := Priority(0); p < numPriorities; p++ {
+			i := s.candidate(p)
+			if i < 0 {
+				continue
+			}
+			if class < 0 || s.pass[p] < s.pass[class] {
+				class, idx = int(p), i
+			}
+		}
+		if class < 0 {
+			return
+		}
+
+		p := Priority(class)
+		w := s.queues[p][idx]
+		s.queues[p] = append(s.queues[p][:idx], s.queues[p][idx+1:]...)
+
+		s.vtime = s.pass[p]
+		s.pass[p] += 1 / priorityWeights[p]
+
+		s.inFlight++
+		s.byAccount[w.slot.AdAccountID]++
+		s.byToken[w.slot.TokenRef]++
+
+		wait := time.Since(w.enqueued)
+		st := &s.stats[p]
+		st.inFlight++
+		st.acquired++
+		st.waitTotal += wait
+		st.waitLast = wait
+		if wait > st.waitMax {
+			st.waitMax = wait
+		}
+
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+// candidate escolhe na fila da classe o elegível cuja conta tem menos
+// chamadas em andamento (FIFO no empate); -1 se ninguém pode rodar agora
+func (s *Scheduler) candidate(p Priority) int {
+	best, bestLoad := -1, 0
+	for i, w := range s.queues[p] {
+		load := s.byAccount[w.slot.AdAccountID]
+		if s.perAdAccount > 0 && load >= s.perAdAccount {
+			continue
+		}
+		if s.perToken > 0 && s.byToken[w.slot.TokenRef] >= s.perToken {
+			continue
+		}
+		if best < 0 || load < bestLoad {
+			best, bestLoad = i, load
+		}
+		if load == 0 {
+			break
+		}
+	}
+	return best
+}
+
+func (s *Scheduler) remove(w *waiter) {
+	q := s.queues[w.slot.Priority]
+	for i, x := range q {
+		if x == w {
+			s.queues[w.slot.Priority] = append(q[:i], q[i+1:]...)
+			return
+		}
+	}
+}
+
+// ======= METRICS =======
+
+type SchedulerClassStats struct {
+	Priority   string  `json:"priority"`
+	Weight     float64 `json:"weight"`
+	InFlight   int     `json:"in_flight"`
+	Queued     int     `json:"queued"`
+	Acquired   int64   `json:"acquired"`
+	Canceled   int64   `json:"canceled"`
+	AvgWaitMs  float64 `json:"avg_wait_ms"`
+	MaxWaitMs  float64 `json:"max_wait_ms"`
+	LastWaitMs float64 `json:"last_wait_ms"`
+}
+
+type SchedulerStats struct {
+	Global       int                   `json:"global_limit"`
+	PerAdAccount int                   `json:"per_ad_account_limit"`
+	PerToken     int                   `json:"per_token_limit"`
+	InFlight     int                   `json:"in_flight"`
+	Queued       int                   `json:"queued"`
+	AdAccounts   int                   `json:"ad_accounts_in_flight"`
+	Classes      []SchedulerClassStats `json:"classes"`
+}
+
+// Stats devolve vagas em uso, filas e tempos de espera por classe desde o início do processo
+func (s *Scheduler) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := SchedulerStats{
+		Global:       s.global,
+		PerAdAccount: s.perAdAccount,
+		PerToken:     s.perToken,
+		InFlight:     s.inFlight,
+		AdAccounts:   len(s.byAccount),
+		Classes:      make([]SchedulerClassStats, 0, numPriorities),
+	}
+	for p := Priority(0); p < numPriorities; p++ {
+		st := s.stats[p]
+		c := SchedulerClassStats{
+			Priority:   p.String(),
+			Weight:     priorityWeights[p],
+			InFlight:   st.inFlight,
+			Queued:     len(s.queues[p]),
+			Acquired:   st.acquired,
+			Canceled:   st.canceled,
+			MaxWaitMs:  ms(st.waitMax),
+			LastWaitMs: ms(st.waitLast),
+		}
+		if st.acquired > 0 {
+			c.AvgWaitMs = ms(st.waitTotal / time.Duration(st.acquired))
+		}
+		out.Queued += c.Queued
+		out.Classes = append(out.Classes, c)
+	}
+	return out
+}
+
+func ms(d time.Duration) float64 {
+	return round(float64(d)/float64(time.Millisecond), 1)
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitQueued espera a fila do scheduler chegar a n (os Acquire rodam em goroutines)
+func waitQueued(t *testing.T, s *Scheduler, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Stats().Queued != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("queued = %d, want %d", s.Stats().Queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSchedulerSharesSlotsByPriority(t *testing.T) {
+	tests := []struct {
+		name    string
+		waiters []Priority // ordem de chegada na fila
+		want    string     // ordem de atendimento (i = interactive, b = bulk, g = background)
+	}{
+		{
+			name:    "interactive passes ahead of queued bulk",
+			waiters: []Priority{PriorityBulk, PriorityBulk, PriorityInteractive, PriorityInteractive},
+			want:    "biib",
+		},
+		{
+			name:    "bulk is not starved by interactive",
+			waiters: append(repeat(PriorityInteractive, 10), repeat(PriorityBulk, 3)...),
+			want:    "biiiibiiiibii",
+		},
+		{
+			name:    "background gets its share",
+			waiters: append(repeat(PriorityInteractive, 12), repeat(PriorityBackground, 2)...),
+			want:    "giiiiiiiigiiii",
+		},
+	}
+
+	labels := map[Priority]byte{PriorityInteractive: 'i', PriorityBulk: 'b', PriorityBackground: 'g'}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(1, 0, 0)
+			ctx := context.Background()
+
+			// Segura a única vaga enquanto a fila se forma
+			holder, err := s.Acquire(ctx, Slot{AdAccountID: "act_0", Priority: PriorityInteractive})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			served := make(chan byte)
+			for i, p := range tt.waiters {
+				go func() {
+					lease, err := s.Acquire(ctx, Slot{AdAccountID: "act_1", Priority: p})
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					served <- labels[p]
+					lease.Release()
+				}()
+				waitQueued(t, s, i+1)
+			}
+
+			holder.Release()
+			got := make([]byte, 0, len(tt.waiters))
+			for range tt.waiters {
+				got = append(got, <-served)
+			}
+			if string(got) != tt.want {
+				t.Errorf("order = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func repeat(p Priority, n int) []Priority {
+	out := make([]Priority, n)
+	for i := range out {
+		out[i] = p
+	}
+	return out
+}
+
+func TestSchedulerPerAccountLimit(t *testing.T) {
+	s := NewScheduler(3, 1, 0)
+	ctx := context.Background()
+
+	a1, err := s.Acquire(ctx, Slot{AdAccountID: "act_a", TokenRef: "t1", Priority: PriorityBulk})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Segunda chamada da mesma conta espera; outra conta passa mesmo tendo chegado depois
+	a2 := make(chan *Lease)
+	go func() {
+		lease, _ := s.Acquire(ctx, Slot{AdAccountID: "act_a", TokenRef: "t1", Priority: PriorityBulk})
+		a2 <- lease
+	}()
+	waitQueued(t, s, 1)
+
+	b1, err := s.Acquire(ctx, Slot{AdAccountID: "act_b", TokenRef: "t2", Priority: PriorityBulk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := s.Stats(); st.InFlight != 2 || st.Queued != 1 {
+		t.Fatalf("in_flight = %d queued = %d, want 2 and 1", st.InFlight, st.Queued)
+	}
+
+	a1.Release()
+	lease := <-a2
+	lease.Release()
+	b1.Release()
+	if st := s.Stats(); st.InFlight != 0 || st.AdAccounts != 0 {
+		t.Errorf("after release: in_flight = %d ad_accounts = %d", st.InFlight, st.AdAccounts)
+	}
+}
+
+func TestSchedulerPerTokenLimit(t *testing.T) {
+	s := NewScheduler(3, 0, 1)
+	ctx := context.Background()
+
+	// Contas diferentes com o mesmo token dividem a vaga do token
+	a, err := s.Acquire(ctx, Slot{AdAccountID: "act_a", TokenRef: "t1", Priority: PriorityInteractive})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := make(chan *Lease)
+	go func() {
+		lease, _ := s.Acquire(ctx, Slot{AdAccountID: "act_b", TokenRef: "t1", Priority: PriorityInteractive})
+		b <- lease
+	}()
+	waitQueued(t, s, 1)
+
+	c, err := s.Acquire(ctx, Slot{AdAccountID: "act_c", TokenRef: "t2", Priority: PriorityInteractive})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := s.Stats(); st.InFlight != 2 || st.Queued != 1 {
+		t.Fatalf("in_flight = %d queued = %d, want 2 and 1", st.InFlight, st.Queued)
+	}
+
+	a.Release()
+	(<-b).Release()
+	c.Release()
+	if st := s.Stats(); st.InFlight != 0 || st.Queued != 0 {
+		t.Errorf("after release: in_flight = %d queued = %d", st.InFlight, st.Queued)
+	}
+}
+
+func TestSchedulerLeastLoadedAccountFirst(t *testing.T) {
+	s := NewScheduler(2, 0, 0)
+	ctx := context.Background()
+
+	busy, _ := s.Acquire(ctx, Slot{AdAccountID: "act_busy", Priority: PriorityBulk})
+	holder, _ := s.Acquire(ctx, Slot{AdAccountID: "act_other", Priority: PriorityBulk})
+
+	served := make(chan string)
+	for i, account := range []string{"act_busy", "act_idle"} {
+		go func() {
+			lease, err := s.Acquire(ctx, Slot{AdAccountID: account, Priority: PriorityBulk})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			served <- account
+			lease.Release()
+		}()
+		waitQueued(t, s, i+1)
+	}
+
+	// A conta sem chamadas em andamento passa na frente de quem chegou antes
+	holder.Release()
+	if got := <-served; got != "act_idle" {
+		t.Errorf("first served = %s, want act_idle", got)
+	}
+	busy.Release()
+	<-served
+}
+
+func TestSchedulerAcquireCanceled(t *testing.T) {
+	s := NewScheduler(1, 0, 0)
+	holder, _ := s.Acquire(context.Background(), Slot{AdAccountID: "act_1"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.Acquire(ctx, Slot{AdAccountID: "act_1", Priority: PriorityBackground}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+
+	st := s.Stats()
+	if st.Queued != 0 || st.Classes[PriorityBackground].Canceled != 1 {
+		t.Errorf("queued = %d canceled = %d, want 0 and 1", st.Queued, st.Classes[PriorityBackground].Canceled)
+	}
+
+	// A vaga continua utilizável depois do cancelamento
+	holder.Release()
+	lease, err := s.Acquire(context.Background(), Slot{AdAccountID: "act_1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lease.Release()
+}
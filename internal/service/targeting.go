@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"creative-service/internal/meta"
+	"creative-service/internal/secrets"
+	"creative-service/internal/storage"
+	"creative-service/internal/targeting"
+)
+
+// validateTargeting exige um targeting e valida o spec tipado.
+// Erros de validação voltam como *targeting.ValidationError (com todos os campos).
+func validateTargeting(t *targeting.Spec) error {
+	if t == nil {
+		return fmt.Errorf("missing targeting")
+	}
+	return t.Validate()
+}
+
+// ======= TARGETING SEARCH =======
+
+const (
+	targetingSearchDefaultLimit = 25
+	targetingSearchMaxLimit     = 500
+	targetingCacheMaxEntries    = 5000
+)
+
+// Tipos aceitos em /v1/targeting/search. behavior, demographic, life_event etc.
+// são classes de adTargetingCategory.
+var targetingCategoryClasses = map[string]string{
+	"behavior":    "behaviors",
+	"demographic": "demographics",
+	"life_event":  "life_events",
+	"industry":    "industries",
+	"income":      "income",
+	"family":      "family_statuses",
+}
+
+type TargetingService struct {
+	Store  *storage.Store
+	Tokens secrets.Resolver
+
+	Meta *meta.ClientFactory
+
+	Sched *Scheduler
+
+	// Resultados de busca mudam pouco; 0 desliga o cache
+	CacheTTL time.Duration
+
+	cacheOnce sync.Once
+	cache     *ttlCache
+}
+
+type SearchTargetingInput struct {
+	AdAccountID   string // define o token usado na busca
+	Type          string // interest, location, behavior, demographic, life_event, industry, income, family, sentence_lines
+	Query         string
+	LocationTypes []string // só para location: country, region, city, zip, geo_market...
+	CountryCode   string   // só para location
+	Locale        string   // ex: pt_BR
+	Limit         int
+	Targeting     *targeting.Spec // só para sentence_lines
+}
+
+type SearchTargetingOutput struct {
+	Type    string           `json:"type"`
+	Query   string           `json:"query,omitempty"`
+	Cached  bool             `json:"cached"`
+	Results []map[string]any `json:"results"`
+}
+
+func (s *TargetingService) Search(ctx context.Context, in SearchTargetingInput) (SearchTargetingOutput, error) {
+	if err := validateSearchInput(&in); err != nil {
+		return SearchTargetingOutput{}, err
+	}
+
+	// O acesso à conta é conferido antes do cache: o cache é compartilhado entre
+	// API keys e não pode servir quem não tem acesso ao ad_account_id
+	adAccount, err := getAdAccount(ctx, s.Store, in.AdAccountID)
+	if err != nil {
+		return SearchTargetingOutput{}, fmt.Errorf("get ad account: %w", err)
+	}
+
+	key, err := searchCacheKey(in)
+	if err != nil {
+		return SearchTargetingOutput{}, err
+	}
+	if results, ok := s.getCache().get(key); ok {
+		return SearchTargetingOutput{Type: in.Type, Query: in.Query, Cached: true, Results: results}, nil
+	}
+
+	lease, err := s.Sched.Acquire(ctx, accountSlot(adAccount, PriorityInteractive))
+	if err != nil {
+		return SearchTargetingOutput{}, err
+	}
+	defer lease.Release()
+
+	token, err := s.Tokens.Resolve(adAccount.TokenRef)
+	if err != nil {
+		return SearchTargetingOutput{}, fmt.Errorf("resolve token: %w", err)
+	}
+
+	mc := s.Meta.Client(token)
+	ctx = meta.WithAdAccount(ctx, adAccount.AdAccountID)
+
+	var results []map[string]any
+	switch in.Type {
+	case "interest":
+		results, err = mc.SearchInterests(ctx, in.Query, in.Locale, in.Limit)
+	case "location":
+		results, err = mc.SearchGeoLocations(ctx, in.Query, in.LocationTypes, in.CountryCode, in.Locale, in.Limit)
+	case "sentence_lines":
+		results, err = mc.TargetingSentenceLines(ctx, in.AdAccountID, in.Targeting, in.Locale)
+	default:
+		// Com q, a classe inteira vem da Meta (sem limit) e o limit vale depois do
+		// filtro; senão o filtro só veria os primeiros itens
+		limit := in.Limit
+		if in.Query != "" {
+			limit = 0
+		}
+		results, err = mc.SearchTargetingCategories(ctx, targetingCategoryClasses[in.Type], in.Locale, limit)
+		results = filterByName(results, in.Query)
+		if len(results) > in.Limit {
+			results = results[:in.Limit]
+		}
+	}
+	if err != nil {
+		return SearchTargetingOutput{}, fmt.Errorf("search targeting: %w", err)
+	}
+	if results == nil {
+		results = []map[string]any{}
+	}
+
+	s.getCache().set(key, results)
+	return SearchTargetingOutput{Type: in.Type, Query: in.Query, Results: results}, nil
+}
+
+func (s *TargetingService) getCache() *ttlCache {
+	s.cacheOnce.Do(func() {
+		s.cache = newTTLCache(s.CacheTTL, targetingCacheMaxEntries)
+	})
+	return s.cache
+}
+
+func validateSearchInput(in *SearchTargetingInput) error {
+	if in.AdAccountID == "" {
+		return fmt.Errorf("missing ad_account_id")
+	}
+	in.Query = strings.TrimSpace(in.Query)
+
+	switch in.Type {
+	case "interest", "location":
+		if in.Query == "" {
+			return fmt.Errorf("q is required for type %s", in.Type)
+		}
+	case "sentence_lines":
+		if in.Targeting == nil {
+			return fmt.Errorf("targeting is required for type sentence_lines")
+		}
+	default:
+		if _, ok := targetingCategoryClasses[in.Type]; !ok {
+			return fmt.Errorf("invalid type: %q", in.Type)
+		}
+	}
+
+	if in.Limit < 0 || in.Limit > targetingSearchMaxLimit {
+		return fmt.Errorf("limit must be between 1 and %d", targetingSearchMaxLimit)
+	}
+	if in.Limit == 0 {
+		in.Limit = targetingSearchDefaultLimit
+	}
+	return nil
+}
+
+// searchCacheKey normaliza os parâmetros da busca. Buscas no /search independem
+// da ad account; sentence_lines é por conta.
+func searchCacheKey(in SearchTargetingInput) (string, error) {
+	q := url.Values{}
+	q.Set("type", in.Type)
+	q.Set("q", strings.ToLower(in.Query))
+	q.Set("locale", in.Locale)
+	q.Set("limit", fmt.Sprint(in.Limit))
+	if in.Type == "location" {
+		q.Set("country_code", strings.ToUpper(in.CountryCode))
+		q.Set("location_types", strings.Join(in.LocationTypes, ","))
+	}
+	if in.Type == "sentence_lines" {
+		b, err := json.Marshal(in.Targeting)
+		if err != nil {
+			return "", fmt.Errorf("encode targeting: %w", err)
+		}
+		q.Set("ad_account_id", in.AdAccountID)
+		q.Set("targeting", string(b))
+	}
+	return q.Encode(), nil
+}
+
+// filterByName filtra categorias por nome: adTargetingCategory não tem busca textual
+func filterByName(items []map[string]any, query string) []map[string]any {
+	if query == "" {
+		return items
+	}
+	query = strings.ToLower(query)
+	out := make([]map[string]any, 0, len(items))
+	for _, it := range items {
+		if strings.Contains(strings.ToLower(stringField(it, "name")), query) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// ttlCache é um cache em memória com expiração por entrada. Ao atingir o limite,
+// remove expirados e, se ainda cheio, a entrada mais antiga.
+type ttlCache struct {
+	ttl time.Duration
+	max int
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value     []map[string]any
+	expiresAt time.Time
+}
+
+func newTTLCache(ttl time.Duration, max int) *ttlCache {
+	return &ttlCache{ttl: ttl, max: max, entries: map[string]cacheEntry{}}
+}
+
+func (c *ttlCache) get(key string) ([]map[string]any, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (c *ttlCache) set(key string, value []map[string]any) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= c.max {
+		var oldestKey string
+		var oldest time.Time
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+				continue
+			}
+			if oldestKey == "" || e.expiresAt.Before(oldest) {
+				oldestKey, oldest = k, e.expiresAt
+			}
+		}
+		if len(c.entries) >= c.max {
+			delete(c.entries, oldestKey)
+		}
+	}
+	c.entries[key] = cacheEntry{value: value, expiresAt: now.Add(c.ttl)}
+}
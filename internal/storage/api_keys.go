@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+type APIKey struct {
+	KeyID       string     `json:"key_id"`
+	Name        string     `json:"name"`
+	KeyPrefix   string     `json:"key_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ClientUUIDs []string   `json:"client_uuids"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+const apiKeySelect = `
+	SELECT k.key_id, k.name, k.key_prefix, k.scopes,
+		COALESCE(array_agg(c.client_uuid::text ORDER BY c.client_uuid) FILTER (WHERE c.client_uuid IS NOT NULL), '{}'),
+		k.created_at, k.last_used_at, k.revoked_at
+	FROM api_keys k
+	LEFT JOIN api_key_clients c ON c.key_id = k.key_id`
+
+func scanAPIKey(row pgx.Row) (APIKey, error) {
+	var k APIKey
+	err := row.Scan(&k.KeyID, &k.Name, &k.KeyPrefix, &k.Scopes, &k.ClientUUIDs, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt)
+	return k, err
+}
+
+// CreateAPIKey grava a chave (só o hash) e os clientes liberados numa transação
+func (s *Store) CreateAPIKey(ctx context.Context, k APIKey, keyHash string) (APIKey, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return APIKey{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO api_keys(key_id, name, key_prefix, key_hash, scopes)
+		VALUES($1, $2, $3, $4, $5)
+	`, k.KeyID, k.Name, k.KeyPrefix, keyHash, k.Scopes); err != nil {
+		return APIKey{}, err
+	}
+	for _, clientUUID := range k.ClientUUIDs {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO api_key_clients(key_id, client_uuid) VALUES($1, $2)
+			ON CONFLICT DO NOTHING
+		`, k.KeyID, clientUUID); err != nil {
+			return APIKey{}, err
+		}
+	}
+
+	created, err := scanAPIKey(tx.QueryRow(ctx, apiKeySelect+`
+		WHERE k.key_id = $1
+		GROUP BY k.key_id
+	`, k.KeyID))
+	if err != nil {
+		return APIKey{}, err
+	}
+	return created, tx.Commit(ctx)
+}
+
+// GetActiveAPIKeyByHash busca uma chave não revogada pelo hash; ErrAPIKeyNotFound se não houver
+func (s *Store) GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
+	k, err := scanAPIKey(s.DB.QueryRow(ctx, apiKeySelect+`
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL
+		GROUP BY k.key_id
+	`, keyHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return k, ErrAPIKeyNotFound
+	}
+	return k, err
+}
+
+func (s *Store) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	rows, err := s.DB.Query(ctx, apiKeySelect+`
+		GROUP BY k.key_id
+		ORDER BY k.created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey marca a chave como revogada; revogar de novo não muda revoked_at
+func (s *Store) RevokeAPIKey(ctx context.Context, keyID string) error {
+	tag, err := s.DB.Exec(ctx, `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now()) WHERE key_id = $1
+	`, keyID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey atualiza last_used_at no máximo uma vez por minuto por chave
+func (s *Store) TouchAPIKey(ctx context.Context, keyID string) error {
+	_, err := s.DB.Exec(ctx, `
+		UPDATE api_keys SET last_used_at = now()
+		WHERE key_id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`, keyID)
+	return err
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Status de uma Idempotency-Key (ver migration 010)
+const (
+	IdempotencyInProgress = "in_progress"
+	IdempotencyCompleted  = "completed"
+)
+
+type IdempotencyRecord struct {
+	Key                 string
+	Method              string
+	Path                string
+	RequestHash         string
+	Status              string
+	ResponseStatus      *int
+	ResponseContentType *string
+	ResponseBody        []byte
+	LockedUntil         time.Time
+	ExpiresAt           time.Time
+}
+
+const idempotencyColumns = `
+	idempotency_key, method, path, request_hash, status, response_status,
+	response_content_type, response_body, locked_until, expires_at`
+
+func scanIdempotencyRecord(row pgx.Row) (IdempotencyRecord, error) {
+	var r IdempotencyRecord
+	err := row.Scan(
+		&r.Key, &r.Method, &r.Path, &r.RequestHash, &r.Status, &r.ResponseStatus,
+		&r.ResponseContentType, &r.ResponseBody, &r.LockedUntil, &r.ExpiresAt,
+	)
+	return r, err
+}
+
+// BeginIdempotentRequest reserva a chave para o request com started=true. A
+// chave também é retomada quando expirou ou quando o request anterior, com o
+// mesmo hash, perdeu o lease sem gravar resposta. Nos demais casos devolve o
+// registro existente (em andamento ou concluído) com started=false.
+func (s *Store) BeginIdempotentRequest(ctx context.Context, key, method, path, requestHash string, lease, ttl time.Duration) (IdempotencyRecord, bool, error) {
+	rec, err := scanIdempotencyRecord(s.DB.QueryRow(ctx, `
+		INSERT INTO idempotency_keys(idempotency_key, method, path, request_hash, locked_until, expires_at)
+		VALUES($1, $2, $3, $4, now() + make_interval(secs => $5), now() + make_interval(secs => $6))
+		ON CONFLICT (idempotency_key, method, path) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			status = 'in_progress',
+			response_status = NULL,
+			response_content_type = NULL,
+			response_body = NULL,
+			locked_until = EXCLUDED.locked_until,
+			expires_at = EXCLUDED.expires_at,
+			created_at = now(),
+			completed_at = NULL
+		WHERE idempotency_keys.expires_at < now()
+		   OR (idempotency_keys.status = 'in_progress'
+		       AND idempotency_keys.locked_until < now()
+		       AND idempotency_keys.request_hash = EXCLUDED.request_hash)
+		RETURNING `+idempotencyColumns,
+		key, method, path, requestHash, lease.Seconds(), ttl.Seconds(),
+	))
+	if err == nil {
+		return rec, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return IdempotencyRecord{}, false, err
+	}
+
+	existing, err := scanIdempotencyRecord(s.DB.QueryRow(ctx, `
+		SELECT `+idempotencyColumns+`
+		FROM idempotency_keys
+		WHERE idempotency_key = $1 AND method = $2 AND path = $3
+	`, key, method, path))
+	if errors.Is(err, pgx.ErrNoRows) {
+		// liberada entre o INSERT e o SELECT: quem chamou tenta de novo
+		return IdempotencyRecord{Key: key, Method: method, Path: path, RequestHash: requestHash, Status: IdempotencyInProgress}, false, nil
+	}
+	return existing, false, err
+}
+
+// ExtendIdempotentRequest renova o lease de um request ainda em execução
+func (s *Store) ExtendIdempotentRequest(ctx context.Context, key, method, path string, lease time.Duration) error {
+	_, err := s.DB.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET locked_until = now() + make_interval(secs => $4)
+		WHERE idempotency_key = $1 AND method = $2 AND path = $3 AND status = 'in_progress'
+	`, key, method, path, lease.Seconds())
+	return err
+}
+
+// CompleteIdempotentRequest grava a resposta que será devolvida nos retries
+func (s *Store) CompleteIdempotentRequest(ctx context.Context, key, method, path string, status int, contentType string, body []byte) error {
+	_, err := s.DB.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET status = 'completed', response_status = $4, response_content_type = $5,
+			response_body = $6, completed_at = now()
+		WHERE idempotency_key = $1 AND method = $2 AND path = $3 AND status = 'in_progress'
+	`, key, method, path, status, contentType, body)
+	return err
+}
+
+// ReleaseIdempotentRequest apaga a reserva de um request que terminou sem
+// resposta gravável, liberando a chave para um novo envio
+func (s *Store) ReleaseIdempotentRequest(ctx context.Context, key, method, path string) error {
+	_, err := s.DB.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE idempotency_key = $1 AND method = $2 AND path = $3 AND status = 'in_progress'
+	`, key, method, path)
+	return err
+}
+
+// PurgeIdempotencyKeys remove as chaves expiradas e devolve quantas foram apagadas
+func (s *Store) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	tag, err := s.DB.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < now()`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
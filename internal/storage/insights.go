@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// InsightsDailyRow é uma linha de insights_daily (um ad em um dia)
+type InsightsDailyRow struct {
+	Date          time.Time
+	AdAccountID   string
+	CampaignID    string
+	CampaignName  string
+	AdSetID       string
+	AdSetName     string
+	AdID          string
+	AdName        string
+	Currency      string
+	Spend         float64
+	Impressions   int64
+	Reach         int64
+	Clicks        int64
+	LinkClicks    int64
+	Purchases     float64
+	PurchaseValue float64
+	Leads         float64
+	Actions       json.RawMessage
+	ActionValues  json.RawMessage
+}
+
+type InsightsSyncState struct {
+	AdAccountID   string     `json:"ad_account_id"`
+	SyncedUntil   *time.Time `json:"synced_until,omitempty"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastError     *string    `json:"last_error,omitempty"`
+	RowsUpserted  int64      `json:"rows_upserted"`
+}
+
+// EnsureInsightsPartitions cria as partições mensais que cobrem [from, until].
+// CREATE TABLE IF NOT EXISTS ... PARTITION OF não é seguro entre transações
+// concorrentes (dois syncs no mesmo mês falham com duplicate key), por isso a
+// criação roda sob pg_advisory_xact_lock.
+func (s *Store) EnsureInsightsPartitions(ctx context.Context, from, until time.Time) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('insights_daily_partitions'))`); err != nil {
+		return fmt.Errorf("lock partitions: %w", err)
+	}
+
+	month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for !month.After(until) {
+		next := month.AddDate(0, 1, 0)
+		name := pgx.Identifier{fmt.Sprintf("insights_daily_%04d_%02d", month.Year(), int(month.Month()))}.Sanitize()
+		_, err := tx.Exec(ctx, fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s PARTITION OF insights_daily FOR VALUES FROM ('%s') TO ('%s')`,
+			name, month.Format("2006-01-02"), next.Format("2006-01-02"),
+		))
+		if err != nil {
+			return fmt.Errorf("create partition %s: %w", name, err)
+		}
+		month = next
+	}
+	return tx.Commit(ctx)
+}
+
+// UpsertInsightsDaily grava as linhas (insert ou update por ad_account_id, ad_id, date)
+func (s *Store) UpsertInsightsDaily(ctx context.Context, rows []InsightsDailyRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, r := range rows {
+		actions, actionValues := r.Actions, r.ActionValues
+		if len(actions) == 0 {
+			actions = json.RawMessage("[]")
+		}
+		if len(actionValues) == 0 {
+			actionValues = json.RawMessage("[]")
+		}
+		batch.Queue(`
+			INSERT INTO insights_daily(date, ad_account_id, campaign_id, campaign_name, adset_id, adset_name,
+				ad_id, ad_name, currency, spend, impressions, reach, clicks, link_clicks,
+				purchases, purchase_value, leads, actions, action_values, synced_at)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, now())
+			ON CONFLICT (ad_account_id, ad_id, date) DO UPDATE SET
+				campaign_id = EXCLUDED.campaign_id, campaign_name = EXCLUDED.campaign_name,
+				adset_id = EXCLUDED.adset_id, adset_name = EXCLUDED.adset_name, ad_name = EXCLUDED.ad_name,
+				currency = EXCLUDED.currency, spend = EXCLUDED.spend, impressions = EXCLUDED.impressions,
+				reach = EXCLUDED.reach, clicks = EXCLUDED.clicks, link_clicks = EXCLUDED.link_clicks,
+				purchases = EXCLUDED.purchases, purchase_value = EXCLUDED.purchase_value, leads = EXCLUDED.leads,
+				actions = EXCLUDED.actions, action_values = EXCLUDED.action_values, synced_at = now()
+		`, r.Date, r.AdAccountID, r.CampaignID, r.CampaignName, r.AdSetID, r.AdSetName,
+			r.AdID, r.AdName, r.Currency, r.Spend, r.Impressions, r.Reach, r.Clicks, r.LinkClicks,
+			r.Purchases, r.PurchaseValue, r.Leads, actions, actionValues)
+	}
+
+	return s.DB.SendBatch(ctx, batch).Close()
+}
+
+// GetInsightsSyncState devolve o cursor da conta (zerado se nunca sincronizou)
+func (s *Store) GetInsightsSyncState(ctx context.Context, adAccountID string) (InsightsSyncState, error) {
+	st := InsightsSyncState{AdAccountID: adAccountID}
+	err := s.DB.QueryRow(ctx, `
+		SELECT synced_until, last_run_at, last_success_at, last_error, rows_upserted
+		FROM insights_sync_state
+		WHERE ad_account_id = $1
+	`, adAccountID).Scan(&st.SyncedUntil, &st.LastRunAt, &st.LastSuccessAt, &st.LastError, &st.RowsUpserted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return st, nil
+	}
+	return st, err
+}
+
+func (s *Store) SaveInsightsSyncState(ctx context.Context, st InsightsSyncState) error {
+	_, err := s.DB.Exec(ctx, `
+		INSERT INTO insights_sync_state(ad_account_id, synced_until, last_run_at, last_success_at, last_error, rows_upserted, updated_at)
+		VALUES($1, $2, $3, $4, $5, $6, now())
+		ON CONFLICT (ad_account_id) DO UPDATE SET
+			synced_until = EXCLUDED.synced_until, last_run_at = EXCLUDED.last_run_at,
+			last_success_at = EXCLUDED.last_success_at, last_error = EXCLUDED.last_error,
+			rows_upserted = EXCLUDED.rows_upserted, updated_at = now()
+	`, st.AdAccountID, st.SyncedUntil, st.LastRunAt, st.LastSuccessAt, st.LastError, st.RowsUpserted)
+	return err
+}
+
+// ListAdAccountsDueForInsightsSync lista contas ativas cujo último sync começou
+// há mais de interval (ou nunca rodou)
+func (s *Store) ListAdAccountsDueForInsightsSync(ctx context.Context, interval time.Duration) ([]AdAccount, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT a.ad_account_id, a.client_uuid
+		FROM ad_accounts a
+		LEFT JOIN insights_sync_state st ON st.ad_account_id = a.ad_account_id
+		WHERE a.is_active AND a.deleted_at IS NULL
+			AND (st.last_run_at IS NULL OR st.last_run_at < now() - make_interval(secs => $1))
+		ORDER BY st.last_run_at NULLS FIRST
+	`, interval.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []AdAccount
+	for rows.Next() {
+		var aa AdAccount
+		if err := rows.Scan(&aa.AdAccountID, &aa.ClientUUID); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, aa)
+	}
+	return accounts, rows.Err()
+}
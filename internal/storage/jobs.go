@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrNoJob indica que não há job disponível na fila
+var ErrNoJob = errors.New("no job available")
+
+// ErrJobNotFound indica que não existe job com o ID pedido
+var ErrJobNotFound = errors.New("job not found")
+
+// Status de jobs (enum job_status)
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+type Job struct {
+	JobID       string          `json:"job_id"`
+	ClientUUID  *string         `json:"client_uuid,omitempty"`
+	AdAccountID *string         `json:"ad_account_id,omitempty"`
+	JobType     string          `json:"job_type"`
+	Status      string          `json:"status"`
+	Input       json.RawMessage `json:"input"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       *string         `json:"error,omitempty"`
+	Attempts    int             `json:"attempts"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+}
+
+const jobColumns = `
+	job_id, client_uuid, ad_account_id, job_type, status::text, input_json, result_json,
+	error_text, attempts, created_at, updated_at, started_at, finished_at`
+
+func scanJob(row pgx.Row) (Job, error) {
+	var j Job
+	err := row.Scan(
+		&j.JobID, &j.ClientUUID, &j.AdAccountID, &j.JobType, &j.Status, &j.Input, &j.Result,
+		&j.Error, &j.Attempts, &j.CreatedAt, &j.UpdatedAt, &j.StartedAt, &j.FinishedAt,
+	)
+	return j, err
+}
+
+func (s *Store) CreateJob(ctx context.Context, j Job) (Job, error) {
+	return scanJob(s.DB.QueryRow(ctx, `
+		INSERT INTO jobs(job_id, client_uuid, ad_account_id, job_type, status, input_json)
+		VALUES($1, $2, $3, $4, 'queued', $5)
+		RETURNING `+jobColumns,
+		j.JobID, j.ClientUUID, j.AdAccountID, j.JobType, j.Input,
+	))
+}
+
+func (s *Store) GetJob(ctx context.Context, jobID string) (Job, error) {
+	j, err := scanJob(s.DB.QueryRow(ctx, `
+		SELECT `+jobColumns+`
+		FROM jobs
+		WHERE job_id = $1
+	`, jobID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return j, ErrJobNotFound
+	}
+	return j, err
+}
+
+// ClaimJob reivindica o próximo job dos tipos indicados (queued, ou running com
+// lease expirado). Devolve ErrNoJob quando a fila está vazia.
+func (s *Store) ClaimJob(ctx context.Context, jobTypes []string, lease time.Duration) (Job, error) {
+	j, err := scanJob(s.DB.QueryRow(ctx, `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1,
+			locked_until = now() + make_interval(secs => $2),
+			started_at = COALESCE(started_at, now()), updated_at = now()
+		WHERE job_id = (
+			SELECT job_id
+			FROM jobs
+			WHERE job_type = ANY($1) AND run_after <= now()
+				AND (status = 'queued' OR (status = 'running' AND locked_until < now()))
+			ORDER BY run_after
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+jobColumns,
+		jobTypes, lease.Seconds(),
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return j, ErrNoJob
+	}
+	return j, err
+}
+
+// UpdateJobProgress grava o progresso parcial e renova o lease de um job em
+// execução. Só vale para a tentativa de job.Attempts: se o lease expirou e outro
+// worker retomou o job, devolve erro e o handler deve parar.
+func (s *Store) UpdateJobProgress(ctx context.Context, job Job, result json.RawMessage, lease time.Duration) error {
+	tag, err := s.DB.Exec(ctx, `
+		UPDATE jobs
+		SET result_json = $3, locked_until = now() + make_interval(secs => $4), updated_at = now()
+		WHERE job_id = $1 AND status = 'running' AND attempts = $2
+	`, job.JobID, job.Attempts, result, lease.Seconds())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("job %s: lease lost", job.JobID)
+	}
+	return nil
+}
+
+// FinishJob conclui o job. Com retryAt o job volta para a fila (queued) mantendo o erro.
+// Como UpdateJobProgress, só conclui se o job ainda é desta tentativa.
+func (s *Store) FinishJob(ctx context.Context, job Job, status string, result json.RawMessage, errText *string, retryAt *time.Time) error {
+	var tag pgconn.CommandTag
+	var err error
+	if retryAt != nil {
+		tag, err = s.DB.Exec(ctx, `
+			UPDATE jobs
+			SET status = 'queued', run_after = $3, result_json = COALESCE($4, result_json),
+				error_text = $5, locked_until = NULL, updated_at = now()
+			WHERE job_id = $1 AND status = 'running' AND attempts = $2
+		`, job.JobID, job.Attempts, *retryAt, result, errText)
+	} else {
+		tag, err = s.DB.Exec(ctx, `
+			UPDATE jobs
+			SET status = $3::job_status, result_json = COALESCE($4, result_json), error_text = $5,
+				locked_until = NULL, finished_at = now(), updated_at = now()
+			WHERE job_id = $1 AND status = 'running' AND attempts = $2
+		`, job.JobID, job.Attempts, status, result, errText)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("job %s: lease lost", job.JobID)
+	}
+	return nil
+}
+
+// ======= Report rows =======
+
+// ReplaceReportRows grava as linhas de um report (substitui as de uma tentativa anterior)
+func (s *Store) ReplaceReportRows(ctx context.Context, jobID string, rows []map[string]any) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM report_rows WHERE job_id = $1`, jobID); err != nil {
+		return err
+	}
+
+	copyRows := make([][]any, 0, len(rows))
+	for i, r := range rows {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("encode row %d: %w", i, err)
+		}
+		copyRows = append(copyRows, []any{jobID, i, b})
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"report_rows"}, []string{"job_id", "row_num", "data"}, pgx.CopyFromRows(copyRows)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListReportRows pagina as linhas de um report na ordem original
+func (s *Store) ListReportRows(ctx context.Context, jobID string, limit, offset int) ([]json.RawMessage, int, error) {
+	var total int
+	if err := s.DB.QueryRow(ctx, `SELECT count(*) FROM report_rows WHERE job_id = $1`, jobID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT data
+		FROM report_rows
+		WHERE job_id = $1
+		ORDER BY row_num
+		LIMIT $2 OFFSET $3
+	`, jobID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := []json.RawMessage{}
+	for rows.Next() {
+		var data json.RawMessage
+		if err := rows.Scan(&data); err != nil {
+			return nil, 0, err
+		}
+		out = append(out, data)
+	}
+	return out, total, rows.Err()
+}
+
+// EnqueueJobIfIdle cria o job se não houver outro ativo que viole um índice único
+// parcial (ex: um insights_sync por ad account). Devolve false se já existia.
+func (s *Store) EnqueueJobIfIdle(ctx context.Context, j Job) (bool, error) {
+	tag, err := s.DB.Exec(ctx, `
+		INSERT INTO jobs(job_id, client_uuid, ad_account_id, job_type, status, input_json)
+		VALUES($1, $2, $3, $4, 'queued', $5)
+		ON CONFLICT DO NOTHING
+	`, j.JobID, j.ClientUUID, j.AdAccountID, j.JobType, j.Input)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
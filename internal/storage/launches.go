@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// LaunchObject é um objeto criado na Meta durante um launch
+type LaunchObject struct {
+	Type     string `json:"type"` // campaign, adset, creative ou ad
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+type Launch struct {
+	LaunchID       string          `json:"launch_id"`
+	ClientUUID     string          `json:"client_uuid"`
+	AdAccountID    string          `json:"ad_account_id"`
+	Status         string          `json:"status"`
+	Spec           json.RawMessage `json:"spec"`
+	CreatedObjects []LaunchObject  `json:"created_objects"`
+	Error          *string         `json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+func (s *Store) CreateLaunch(ctx context.Context, l Launch) error {
+	_, err := s.DB.Exec(ctx, `
+		INSERT INTO launches(launch_id, client_uuid, ad_account_id, status, spec_json)
+		VALUES($1, $2, $3, $4, $5)
+	`, l.LaunchID, l.ClientUUID, l.AdAccountID, l.Status, l.Spec)
+	return err
+}
+
+// UpdateLaunch grava status, objetos criados e erro de uma vez
+func (s *Store) UpdateLaunch(ctx context.Context, launchID, status string, objects []LaunchObject, errText *string) error {
+	if objects == nil {
+		objects = []LaunchObject{}
+	}
+	b, err := json.Marshal(objects)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.DB.Exec(ctx, `
+		UPDATE launches
+		SET status = $2, created_objects = $3, error_text = $4, updated_at = now()
+		WHERE launch_id = $1
+	`, launchID, status, b, errText)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("launch not found: %s", launchID)
+	}
+	return nil
+}
+
+func (s *Store) GetLaunch(ctx context.Context, launchID string) (Launch, error) {
+	var l Launch
+	var objects []byte
+	err := s.DB.QueryRow(ctx, `
+		SELECT launch_id, client_uuid, ad_account_id, status, spec_json, created_objects,
+			error_text, created_at, updated_at
+		FROM launches
+		WHERE launch_id = $1
+	`, launchID).Scan(
+		&l.LaunchID, &l.ClientUUID, &l.AdAccountID, &l.Status, &l.Spec, &objects,
+		&l.Error, &l.CreatedAt, &l.UpdatedAt,
+	)
+	if err != nil {
+		return l, err
+	}
+
+	if err := json.Unmarshal(objects, &l.CreatedObjects); err != nil {
+		return l, fmt.Errorf("decode created_objects: %w", err)
+	}
+	return l, nil
+}
+
+// ListLaunches lista os launches mais recentes de uma ad account
+func (s *Store) ListLaunches(ctx context.Context, adAccountID string) ([]Launch, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT launch_id, client_uuid, ad_account_id, status, spec_json, created_objects,
+			error_text, created_at, updated_at
+		FROM launches
+		WHERE ad_account_id = $1
+		ORDER BY created_at DESC
+		LIMIT 100
+	`, adAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var launches []Launch
+	for rows.Next() {
+		var l Launch
+		var objects []byte
+		err := rows.Scan(
+			&l.LaunchID, &l.ClientUUID, &l.AdAccountID, &l.Status, &l.Spec, &objects,
+			&l.Error, &l.CreatedAt, &l.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(objects, &l.CreatedObjects); err != nil {
+			return nil, fmt.Errorf("decode created_objects: %w", err)
+		}
+		launches = append(launches, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return launches, nil
+}
@@ -0,0 +1,26 @@
+-- Migration 006: Launches (criação da hierarquia completa em uma chamada)
+--
+-- Cada launch guarda o spec recebido e todos os IDs da Meta criados,
+-- na ordem de criação, para permitir inspeção e desfazer (soft delete).
+--
+-- status:
+--   running      - em execução
+--   succeeded    - hierarquia criada por completo
+--   rolled_back  - falhou no meio e os objetos criados foram removidos
+--   failed       - falhou e o rollback também falhou (ver error_text)
+--   undone       - desfeito manualmente via DELETE /v1/launches/{id}
+
+CREATE TABLE IF NOT EXISTS launches (
+    launch_id       UUID PRIMARY KEY,
+    client_uuid     UUID NOT NULL REFERENCES clients(client_uuid) ON DELETE CASCADE,
+    ad_account_id   TEXT NOT NULL REFERENCES ad_accounts(ad_account_id) ON DELETE CASCADE,
+    status          TEXT NOT NULL,
+    spec_json       JSONB NOT NULL,
+    created_objects JSONB NOT NULL DEFAULT '[]'::jsonb,
+    error_text      TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_launches_ad_account_id ON launches(ad_account_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_launches_status ON launches(status);
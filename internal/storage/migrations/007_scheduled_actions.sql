@@ -0,0 +1,51 @@
+-- Migration 007: Ações agendadas (mudanças futuras de status e orçamento)
+--
+-- O worker executa as ações vencidas (run_at <= now()) pelos mesmos services da API.
+-- Cada ação é reivindicada com FOR UPDATE SKIP LOCKED e um lease (locked_until),
+-- então só um worker a executa; se o worker morrer no meio, o lease expira e
+-- outro worker retoma (as ações são idempotentes: gravam valores absolutos).
+--
+-- status:
+--   pending    - aguardando run_at (ou nova tentativa)
+--   running    - em execução por um worker
+--   succeeded  - executada
+--   failed     - falhou após todas as tentativas (ver last_error)
+--   canceled   - cancelada via POST /v1/scheduled-actions/{id}/cancel
+
+CREATE TABLE IF NOT EXISTS scheduled_actions (
+    action_id       UUID PRIMARY KEY,
+    client_uuid     UUID NOT NULL REFERENCES clients(client_uuid) ON DELETE CASCADE,
+    ad_account_id   TEXT NOT NULL REFERENCES ad_accounts(ad_account_id) ON DELETE CASCADE,
+    object_type     TEXT NOT NULL,
+    object_id       TEXT NOT NULL,
+    action          TEXT NOT NULL,
+    params          JSONB NOT NULL DEFAULT '{}'::jsonb,
+    run_at          TIMESTAMPTZ NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending',
+    attempts        INT NOT NULL DEFAULT 0,
+    max_attempts    INT NOT NULL DEFAULT 3,
+    locked_until    TIMESTAMPTZ,
+    last_error      TEXT,
+    idempotency_key TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    executed_at     TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scheduled_actions_idempotency
+    ON scheduled_actions(ad_account_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_scheduled_actions_due ON scheduled_actions(run_at) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_scheduled_actions_ad_account_id ON scheduled_actions(ad_account_id, run_at DESC);
+
+-- Histórico de execuções (uma linha por tentativa)
+CREATE TABLE IF NOT EXISTS scheduled_action_runs (
+    run_id      BIGSERIAL PRIMARY KEY,
+    action_id   UUID NOT NULL REFERENCES scheduled_actions(action_id) ON DELETE CASCADE,
+    attempt     INT NOT NULL,
+    status      TEXT NOT NULL, -- succeeded ou failed
+    error_text  TEXT,
+    started_at  TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_action_runs_action_id ON scheduled_action_runs(action_id, run_id);
@@ -0,0 +1,28 @@
+-- Migration 008: Jobs por ad account e resultados de reports assíncronos
+--
+-- A tabela jobs (001) era por client_id; jobs novos (reports de insights, sync)
+-- pertencem a uma ad account. client_id passa a ser opcional.
+--
+-- Fila: o worker reivindica jobs queued (ou running com lease expirado) com
+-- FOR UPDATE SKIP LOCKED. Jobs longos renovam locked_until enquanto rodam.
+
+ALTER TABLE jobs ALTER COLUMN client_id DROP NOT NULL;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS client_uuid UUID REFERENCES clients(client_uuid) ON DELETE CASCADE;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS ad_account_id TEXT REFERENCES ad_accounts(ad_account_id) ON DELETE CASCADE;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS run_after TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS finished_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_queue ON jobs(run_after) WHERE status IN ('queued', 'running');
+CREATE INDEX IF NOT EXISTS idx_jobs_ad_account_id ON jobs(ad_account_id, created_at DESC);
+
+-- Linhas de resultado dos reports de insights (job_type = insights_report),
+-- na ordem devolvida pela Meta
+CREATE TABLE IF NOT EXISTS report_rows (
+    job_id  TEXT NOT NULL REFERENCES jobs(job_id) ON DELETE CASCADE,
+    row_num INT NOT NULL,
+    data    JSONB NOT NULL,
+    PRIMARY KEY (job_id, row_num)
+);
@@ -0,0 +1,50 @@
+-- Migration 009: Warehouse de insights diários (nível ad)
+--
+-- insights_daily é particionada por mês (RANGE em date). As partições
+-- insights_daily_YYYY_MM são criadas pelo worker antes de gravar cada período.
+-- O sync por ad account reprocessa uma janela de atribuição (dias recentes ainda
+-- mudam na Meta) e faz upsert por (ad_account_id, ad_id, date).
+--
+-- Valores monetários na moeda da conta (NUMERIC, não centavos), como na Meta.
+
+CREATE TABLE IF NOT EXISTS insights_daily (
+    date             DATE NOT NULL,
+    ad_account_id    TEXT NOT NULL,
+    campaign_id      TEXT,
+    campaign_name    TEXT,
+    adset_id         TEXT,
+    adset_name       TEXT,
+    ad_id            TEXT NOT NULL,
+    ad_name          TEXT,
+    currency         TEXT,
+    spend            NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    impressions      BIGINT NOT NULL DEFAULT 0,
+    reach            BIGINT NOT NULL DEFAULT 0,
+    clicks           BIGINT NOT NULL DEFAULT 0,
+    link_clicks      BIGINT NOT NULL DEFAULT 0,
+    purchases        NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    purchase_value   NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    leads            NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    actions          JSONB NOT NULL DEFAULT '[]'::jsonb, -- lista completa de actions da Meta
+    action_values    JSONB NOT NULL DEFAULT '[]'::jsonb,
+    synced_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (ad_account_id, ad_id, date)
+) PARTITION BY RANGE (date);
+
+CREATE INDEX IF NOT EXISTS idx_insights_daily_date ON insights_daily(date);
+CREATE INDEX IF NOT EXISTS idx_insights_daily_campaign ON insights_daily(campaign_id, date);
+
+-- Cursor de sync por ad account
+CREATE TABLE IF NOT EXISTS insights_sync_state (
+    ad_account_id     TEXT PRIMARY KEY REFERENCES ad_accounts(ad_account_id) ON DELETE CASCADE,
+    synced_until      DATE,          -- último dia já gravado (inclusive)
+    last_run_at       TIMESTAMPTZ,
+    last_success_at   TIMESTAMPTZ,
+    last_error        TEXT,
+    rows_upserted     BIGINT NOT NULL DEFAULT 0, -- na última execução
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- No máximo um sync pendente/rodando por ad account
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_insights_sync_active
+    ON jobs(ad_account_id) WHERE job_type = 'insights_sync' AND status IN ('queued', 'running');
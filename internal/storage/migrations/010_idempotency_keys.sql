@@ -0,0 +1,31 @@
+-- Migration 010: Idempotency-Key dos endpoints de criação
+--
+-- Cada (idempotency_key, method, path) guarda o hash do request e, depois de
+-- executado, o status e o body da resposta. Um retry com a mesma chave devolve
+-- a resposta gravada em vez de criar o objeto de novo na Meta.
+--
+-- status:
+--   in_progress - request original em execução; locked_until é renovado enquanto
+--                 ele roda. Se o processo morrer, a chave volta a ficar livre
+--                 quando o lease expira
+--   completed   - resposta gravada (response_status, response_body)
+--
+-- Linhas com expires_at vencido podem ser reaproveitadas e são removidas pelo worker.
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key       TEXT NOT NULL,
+    method                TEXT NOT NULL,
+    path                  TEXT NOT NULL,
+    request_hash          TEXT NOT NULL,
+    status                TEXT NOT NULL DEFAULT 'in_progress',
+    response_status       INT,
+    response_content_type TEXT,
+    response_body         BYTEA,
+    locked_until          TIMESTAMPTZ NOT NULL,
+    expires_at            TIMESTAMPTZ NOT NULL,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at          TIMESTAMPTZ,
+    PRIMARY KEY (idempotency_key, method, path)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
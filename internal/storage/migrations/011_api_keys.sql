@@ -0,0 +1,29 @@
+-- Migration 011: API keys com scopes e clientes liberados
+--
+-- Só o sha256 da chave é guardado (key_hash); key_prefix (ex: csk_AbC123) serve
+-- para identificar a chave em listagens. scopes:
+--   read  - GETs, estimativas e validações
+--   write - cria, altera e remove objetos (inclui read)
+--   admin - todos os clientes e gestão de API keys (inclui write)
+--
+-- Chaves sem admin só acessam os clientes listados em api_key_clients.
+-- Revogação é lógica (revoked_at) para manter o histórico.
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    key_id       UUID PRIMARY KEY,
+    name         TEXT NOT NULL,
+    key_prefix   TEXT NOT NULL,
+    key_hash     TEXT NOT NULL UNIQUE,
+    scopes       TEXT[] NOT NULL CHECK (scopes <@ ARRAY['read', 'write', 'admin'] AND cardinality(scopes) > 0),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS api_key_clients (
+    key_id      UUID NOT NULL REFERENCES api_keys(key_id) ON DELETE CASCADE,
+    client_uuid UUID NOT NULL REFERENCES clients(client_uuid) ON DELETE CASCADE,
+    PRIMARY KEY (key_id, client_uuid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_key_clients_client_uuid ON api_key_clients(client_uuid);
@@ -184,6 +184,38 @@ func (s *Store) ListCreatives(ctx context.Context, adAccountID string, typeFilte
 	return creatives, nil
 }
 
+// GetCreativesByIDs devolve os creatives (não deletados) da conta cujos IDs estão na lista
+func (s *Store) GetCreativesByIDs(ctx context.Context, adAccountID string, creativeIDs []string) (map[string]Creative, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT creative_id, client_uuid, ad_account_id, name, type, url, thumb_url, link, message, 
+			COALESCE(meta_data,'{}'::jsonb) AS meta_data, deleted_at, created_at, updated_at
+		FROM creatives
+		WHERE ad_account_id = $1 AND creative_id = ANY($2) AND deleted_at IS NULL
+	`, adAccountID, creativeIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	creatives := map[string]Creative{}
+	for rows.Next() {
+		var c Creative
+		var md []byte
+
+		err := rows.Scan(
+			&c.CreativeID, &c.ClientUUID, &c.AdAccountID, &c.Name, &c.Type, &c.URL,
+			&c.ThumbURL, &c.Link, &c.Message, &md, &c.DeletedAt, &c.CreatedAt, &c.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		c.MetaData = append(json.RawMessage(nil), md...)
+		creatives[c.CreativeID] = c
+	}
+	return creatives, rows.Err()
+}
+
 // SoftDeleteCreative marca um creative como deletado (soft delete)
 func (s *Store) SoftDeleteCreative(ctx context.Context, creativeID string) error {
 	result, err := s.DB.Exec(ctx, `
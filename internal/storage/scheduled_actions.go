@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrScheduledActionNotPending = errors.New("scheduled action is not pending")
+
+type ScheduledAction struct {
+	ActionID       string               `json:"action_id"`
+	ClientUUID     string               `json:"client_uuid"`
+	AdAccountID    string               `json:"ad_account_id"`
+	ObjectType     string               `json:"object_type"` // campaign, adset ou ad
+	ObjectID       string               `json:"object_id"`
+	Action         string               `json:"action"`
+	Params         json.RawMessage      `json:"params"`
+	RunAt          time.Time            `json:"run_at"`
+	Status         string               `json:"status"`
+	Attempts       int                  `json:"attempts"`
+	MaxAttempts    int                  `json:"max_attempts"`
+	LastError      *string              `json:"last_error,omitempty"`
+	IdempotencyKey *string              `json:"idempotency_key,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+	ExecutedAt     *time.Time           `json:"executed_at,omitempty"`
+	Runs           []ScheduledActionRun `json:"runs,omitempty"`
+}
+
+type ScheduledActionRun struct {
+	Attempt    int       `json:"attempt"`
+	Status     string    `json:"status"`
+	Error      *string   `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+const scheduledActionColumns = `
+	action_id, client_uuid, ad_account_id, object_type, object_id, action, params, run_at,
+	status, attempts, max_attempts, last_error, idempotency_key, created_at, updated_at, executed_at`
+
+func scanScheduledAction(row pgx.Row) (ScheduledAction, error) {
+	var a ScheduledAction
+	err := row.Scan(
+		&a.ActionID, &a.ClientUUID, &a.AdAccountID, &a.ObjectType, &a.ObjectID, &a.Action, &a.Params, &a.RunAt,
+		&a.Status, &a.Attempts, &a.MaxAttempts, &a.LastError, &a.IdempotencyKey, &a.CreatedAt, &a.UpdatedAt, &a.ExecutedAt,
+	)
+	return a, err
+}
+
+// CreateScheduledAction insere a ação. Com idempotency_key já usada na mesma ad
+// account devolve a ação existente e created=false.
+func (s *Store) CreateScheduledAction(ctx context.Context, a ScheduledAction) (ScheduledAction, bool, error) {
+	created, err := scanScheduledAction(s.DB.QueryRow(ctx, `
+		INSERT INTO scheduled_actions(action_id, client_uuid, ad_account_id, object_type, object_id,
+			action, params, run_at, max_attempts, idempotency_key)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (ad_account_id, idempotency_key) WHERE idempotency_key IS NOT NULL DO NOTHING
+		RETURNING `+scheduledActionColumns,
+		a.ActionID, a.ClientUUID, a.AdAccountID, a.ObjectType, a.ObjectID,
+		a.Action, a.Params, a.RunAt, a.MaxAttempts, a.IdempotencyKey,
+	))
+	if err == nil {
+		return created, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) || a.IdempotencyKey == nil {
+		return ScheduledAction{}, false, err
+	}
+
+	existing, err := scanScheduledAction(s.DB.QueryRow(ctx, `
+		SELECT `+scheduledActionColumns+`
+		FROM scheduled_actions
+		WHERE ad_account_id = $1 AND idempotency_key = $2
+	`, a.AdAccountID, *a.IdempotencyKey))
+	return existing, false, err
+}
+
+// GetScheduledAction devolve a ação com o histórico de execuções
+func (s *Store) GetScheduledAction(ctx context.Context, actionID string) (ScheduledAction, error) {
+	a, err := scanScheduledAction(s.DB.QueryRow(ctx, `
+		SELECT `+scheduledActionColumns+`
+		FROM scheduled_actions
+		WHERE action_id = $1
+	`, actionID))
+	if err != nil {
+		return a, err
+	}
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT attempt, status, error_text, started_at, finished_at
+		FROM scheduled_action_runs
+		WHERE action_id = $1
+		ORDER BY run_id
+	`, actionID)
+	if err != nil {
+		return a, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r ScheduledActionRun
+		if err := rows.Scan(&r.Attempt, &r.Status, &r.Error, &r.StartedAt, &r.FinishedAt); err != nil {
+			return a, err
+		}
+		a.Runs = append(a.Runs, r)
+	}
+	return a, rows.Err()
+}
+
+// ListScheduledActions lista as ações de uma ad account (status opcional),
+// da maior run_at para a menor: agendadas mais distantes primeiro, histórico no fim
+func (s *Store) ListScheduledActions(ctx context.Context, adAccountID, status string) ([]ScheduledAction, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT `+scheduledActionColumns+`
+		FROM scheduled_actions
+		WHERE ad_account_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY run_at DESC
+		LIMIT 500
+	`, adAccountID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []ScheduledAction
+	for rows.Next() {
+		a, err := scanScheduledAction(rows)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	}
+	return actions, rows.Err()
+}
+
+// CancelScheduledAction cancela uma ação ainda pendente
+func (s *Store) CancelScheduledAction(ctx context.Context, actionID string) (ScheduledAction, error) {
+	a, err := scanScheduledAction(s.DB.QueryRow(ctx, `
+		UPDATE scheduled_actions
+		SET status = 'canceled', locked_until = NULL, updated_at = now()
+		WHERE action_id = $1 AND status = 'pending'
+		RETURNING `+scheduledActionColumns,
+		actionID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, getErr := s.GetScheduledAction(ctx, actionID); getErr != nil {
+			return a, getErr
+		}
+		return a, ErrScheduledActionNotPending
+	}
+	return a, err
+}
+
+// ClaimDueScheduledActions reivindica até limit ações vencidas para este worker,
+// incluindo as que ficaram em running com lease expirado (worker que morreu)
+func (s *Store) ClaimDueScheduledActions(ctx context.Context, limit int, lease time.Duration) ([]ScheduledAction, error) {
+	rows, err := s.DB.Query(ctx, `
+		UPDATE scheduled_actions
+		SET status = 'running', attempts = attempts + 1,
+			locked_until = now() + make_interval(secs => $2), updated_at = now()
+		WHERE action_id IN (
+			SELECT action_id
+			FROM scheduled_actions
+			WHERE run_at <= now()
+				AND (status = 'pending' OR (status = 'running' AND locked_until < now()))
+			ORDER BY run_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+scheduledActionColumns,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []ScheduledAction
+	for rows.Next() {
+		a, err := scanScheduledAction(rows)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	}
+	return actions, rows.Err()
+}
+
+// FinishScheduledAction registra a tentativa no histórico e grava o resultado.
+// Com errText e retryAt a ação volta para pending; com errText sem retryAt vira failed.
+// Só conclui se a ação ainda é desta tentativa (o lease não foi retomado por outro worker).
+func (s *Store) FinishScheduledAction(ctx context.Context, a ScheduledAction, startedAt time.Time, errText *string, retryAt *time.Time) error {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	status, runStatus := "succeeded", "succeeded"
+	runAt := a.RunAt
+	if errText != nil {
+		status, runStatus = "failed", "failed"
+		if retryAt != nil {
+			status, runAt = "pending", *retryAt
+		}
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE scheduled_actions
+		SET status = $3, run_at = $4, last_error = COALESCE($5, last_error), locked_until = NULL,
+			executed_at = CASE WHEN $3 = 'succeeded' THEN now() ELSE executed_at END,
+			updated_at = now()
+		WHERE action_id = $1 AND status = 'running' AND attempts = $2
+	`, a.ActionID, a.Attempts, status, runAt, errText)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("scheduled action %s: lease lost", a.ActionID)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO scheduled_action_runs(action_id, attempt, status, error_text, started_at)
+		VALUES($1, $2, $3, $4, $5)
+	`, a.ActionID, a.Attempts, runStatus, errText, startedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
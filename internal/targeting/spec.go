@@ -0,0 +1,245 @@
+// Package targeting modela o targeting spec da Meta Marketing API.
+//
+// Os campos conhecidos são tipados e validados; campos que o modelo ainda não
+// cobre ficam em Extra e são devolvidos intactos no JSON, então um spec sempre
+// sobrevive ao round-trip decode → encode sem perder nada.
+package targeting
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ID aceita IDs da Meta tanto como string quanto como número no JSON
+// (o Ads Manager exporta interesses como número, a API devolve string)
+type ID string
+
+func (id *ID) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*id = ID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("invalid id: %s", string(b))
+	}
+	*id = ID(n.String())
+	return nil
+}
+
+// Ref referencia um objeto por ID: custom audiences (inclusive lookalikes),
+// interests, behaviors etc.
+type Ref struct {
+	ID   ID     `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// KeyRef referencia regiões e CEPs pela key da Meta (ver /v1/targeting/search)
+type KeyRef struct {
+	Key  string `json:"key"`
+	Name string `json:"name,omitempty"`
+}
+
+type City struct {
+	Key          string `json:"key"`
+	Name         string `json:"name,omitempty"`
+	Radius       int    `json:"radius,omitempty"`
+	DistanceUnit string `json:"distance_unit,omitempty"` // mile ou kilometer
+}
+
+type CustomLocation struct {
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	Radius       float64 `json:"radius"`
+	DistanceUnit string  `json:"distance_unit,omitempty"`
+	Name         string  `json:"name,omitempty"`
+}
+
+type GeoLocations struct {
+	Countries       []string         `json:"countries,omitempty"`
+	Regions         []KeyRef         `json:"regions,omitempty"`
+	Cities          []City           `json:"cities,omitempty"`
+	Zips            []KeyRef         `json:"zips,omitempty"`
+	CustomLocations []CustomLocation `json:"custom_locations,omitempty"`
+	LocationTypes   []string         `json:"location_types,omitempty"` // home, recent, travel_in
+
+	Extra map[string]json.RawMessage `json:"-"` // geo_markets, places, neighborhoods...
+}
+
+// FlexibleSpec é um grupo "OU" de interesses/comportamentos; grupos diferentes
+// dentro de flexible_spec são combinados com "E"
+type FlexibleSpec struct {
+	Interests  []Ref `json:"interests,omitempty"`
+	Behaviors  []Ref `json:"behaviors,omitempty"`
+	LifeEvents []Ref `json:"life_events,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"` // work_positions, industries...
+}
+
+type Spec struct {
+	GeoLocations         *GeoLocations `json:"geo_locations,omitempty"`
+	ExcludedGeoLocations *GeoLocations `json:"excluded_geo_locations,omitempty"`
+
+	AgeMin  int   `json:"age_min,omitempty"`
+	AgeMax  int   `json:"age_max,omitempty"`
+	Genders []int `json:"genders,omitempty"` // 1 = masculino, 2 = feminino
+	Locales []int `json:"locales,omitempty"`
+
+	CustomAudiences         []Ref `json:"custom_audiences,omitempty"`
+	ExcludedCustomAudiences []Ref `json:"excluded_custom_audiences,omitempty"`
+
+	Interests    []Ref          `json:"interests,omitempty"`
+	FlexibleSpec []FlexibleSpec `json:"flexible_spec,omitempty"`
+	Exclusions   *FlexibleSpec  `json:"exclusions,omitempty"`
+
+	PublisherPlatforms       []string `json:"publisher_platforms,omitempty"`
+	FacebookPositions        []string `json:"facebook_positions,omitempty"`
+	InstagramPositions       []string `json:"instagram_positions,omitempty"`
+	AudienceNetworkPositions []string `json:"audience_network_positions,omitempty"`
+	MessengerPositions       []string `json:"messenger_positions,omitempty"`
+	DevicePlatforms          []string `json:"device_platforms,omitempty"`
+
+	// Campos do targeting spec não modelados acima, repassados à Meta sem validação
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Aliases sem os métodos de JSON, para evitar recursão em Marshal/Unmarshal
+type (
+	spec         Spec
+	geoLocations GeoLocations
+	flexibleSpec FlexibleSpec
+)
+
+func (s *Spec) UnmarshalJSON(b []byte) error {
+	var typed spec
+	extra, err := unmarshalWithExtra(b, &typed)
+	if err != nil {
+		return err
+	}
+	typed.Extra = extra
+	*s = Spec(typed)
+	return nil
+}
+
+func (s Spec) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(spec(s), s.Extra)
+}
+
+func (g *GeoLocations) UnmarshalJSON(b []byte) error {
+	var typed geoLocations
+	extra, err := unmarshalWithExtra(b, &typed)
+	if err != nil {
+		return err
+	}
+	typed.Extra = extra
+	*g = GeoLocations(typed)
+	return nil
+}
+
+func (g GeoLocations) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(geoLocations(g), g.Extra)
+}
+
+func (f *FlexibleSpec) UnmarshalJSON(b []byte) error {
+	var typed flexibleSpec
+	extra, err := unmarshalWithExtra(b, &typed)
+	if err != nil {
+		return err
+	}
+	typed.Extra = extra
+	*f = FlexibleSpec(typed)
+	return nil
+}
+
+func (f FlexibleSpec) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(flexibleSpec(f), f.Extra)
+}
+
+// Map converte o spec para o formato genérico usado nos payloads da Meta
+func (s Spec) Map() (map[string]any, error) {
+	b, err := s.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FromMap converte um targeting genérico (ex: lido da Meta) para o modelo tipado
+func FromMap(m map[string]any) (Spec, error) {
+	var s Spec
+	b, err := json.Marshal(m)
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(b, &s)
+	return s, err
+}
+
+// unmarshalWithExtra decodifica os campos tipados em typed e devolve as chaves
+// que não correspondem a nenhuma tag json do struct
+func unmarshalWithExtra(b []byte, typed any) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(b, typed); err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+	for _, k := range jsonFields(typed) {
+		delete(all, k)
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+	return all, nil
+}
+
+// marshalWithExtra codifica typed e acrescenta as chaves extras que não
+// colidem com campos tipados
+func marshalWithExtra(typed any, extra map[string]json.RawMessage) ([]byte, error) {
+	b, err := json.Marshal(typed)
+	if err != nil {
+		return nil, err
+	}
+	if len(extra) == 0 {
+		return b, nil
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+	for k, v := range extra {
+		if _, known := all[k]; !known {
+			all[k] = v
+		}
+	}
+	return json.Marshal(all)
+}
+
+func jsonFields(v any) []string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
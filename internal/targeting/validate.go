@@ -0,0 +1,278 @@
+package targeting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Limites da Meta para idade e raio de localização
+const (
+	MinAge = 13
+	MaxAge = 65
+
+	minCityRadiusMiles = 10
+	maxCityRadiusMiles = 50
+	minCityRadiusKm    = 17
+	maxCityRadiusKm    = 80
+)
+
+var (
+	publisherPlatforms = set("facebook", "instagram", "audience_network", "messenger")
+	devicePlatforms    = set("mobile", "desktop")
+	locationTypes      = set("home", "recent", "travel_in")
+	distanceUnits      = set("mile", "kilometer")
+
+	// Posições válidas por plataforma
+	positions = map[string]struct {
+		platform string
+		values   map[string]struct{}
+	}{
+		"facebook_positions": {"facebook", set(
+			"feed", "right_hand_column", "marketplace", "video_feeds", "story",
+			"search", "instream_video", "facebook_reels", "facebook_reels_overlay", "profile_feed",
+		)},
+		"instagram_positions": {"instagram", set(
+			"stream", "story", "explore", "explore_home", "reels", "profile_feed", "ig_search", "profile_reels",
+		)},
+		"audience_network_positions": {"audience_network", set(
+			"classic", "rewarded_video",
+		)},
+		"messenger_positions": {"messenger", set(
+			"messenger_home", "sponsored_messages", "story",
+		)},
+	}
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError agrega todos os problemas do spec, não só o primeiro
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		parts = append(parts, fe.Field+": "+fe.Message)
+	}
+	return "invalid targeting: " + strings.Join(parts, "; ")
+}
+
+// Validate devolve nil ou um *ValidationError com todos os erros encontrados
+func (s Spec) Validate() error {
+	v := &validator{}
+
+	if s.GeoLocations == nil {
+		v.add("geo_locations", "is required")
+	} else {
+		v.geo("geo_locations", *s.GeoLocations, true)
+	}
+	if s.ExcludedGeoLocations != nil {
+		v.geo("excluded_geo_locations", *s.ExcludedGeoLocations, false)
+	}
+
+	if s.AgeMin != 0 && (s.AgeMin < MinAge || s.AgeMin > MaxAge) {
+		v.add("age_min", fmt.Sprintf("must be between %d and %d", MinAge, MaxAge))
+	}
+	if s.AgeMax != 0 && (s.AgeMax < MinAge || s.AgeMax > MaxAge) {
+		v.add("age_max", fmt.Sprintf("must be between %d and %d", MinAge, MaxAge))
+	}
+	if s.AgeMin != 0 && s.AgeMax != 0 && s.AgeMin > s.AgeMax {
+		v.add("age_min", "must be less than or equal to age_max")
+	}
+
+	for i, g := range s.Genders {
+		if g != 1 && g != 2 {
+			v.add(fmt.Sprintf("genders[%d]", i), "must be 1 (male) or 2 (female)")
+		}
+	}
+	for i, l := range s.Locales {
+		if l <= 0 {
+			v.add(fmt.Sprintf("locales[%d]", i), "must be a positive locale id")
+		}
+	}
+
+	included := v.refs("custom_audiences", s.CustomAudiences)
+	excluded := v.refs("excluded_custom_audiences", s.ExcludedCustomAudiences)
+	for id := range included {
+		if _, ok := excluded[id]; ok {
+			v.add("excluded_custom_audiences", fmt.Sprintf("audience %s is both included and excluded", id))
+		}
+	}
+
+	v.refs("interests", s.Interests)
+	for i, fs := range s.FlexibleSpec {
+		v.flexible(fmt.Sprintf("flexible_spec[%d]", i), fs)
+	}
+	if s.Exclusions != nil {
+		v.flexible("exclusions", *s.Exclusions)
+	}
+
+	for i, p := range s.PublisherPlatforms {
+		if _, ok := publisherPlatforms[p]; !ok {
+			v.add(fmt.Sprintf("publisher_platforms[%d]", i), fmt.Sprintf("unknown platform %q", p))
+		}
+	}
+	for i, d := range s.DevicePlatforms {
+		if _, ok := devicePlatforms[d]; !ok {
+			v.add(fmt.Sprintf("device_platforms[%d]", i), fmt.Sprintf("unknown device platform %q", d))
+		}
+	}
+	v.positions("facebook_positions", s.FacebookPositions, s.PublisherPlatforms)
+	v.positions("instagram_positions", s.InstagramPositions, s.PublisherPlatforms)
+	v.positions("audience_network_positions", s.AudienceNetworkPositions, s.PublisherPlatforms)
+	v.positions("messenger_positions", s.MessengerPositions, s.PublisherPlatforms)
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errs}
+}
+
+type validator struct {
+	errs []FieldError
+}
+
+func (v *validator) add(field, msg string) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: msg})
+}
+
+func (v *validator) geo(field string, g GeoLocations, requireLocation bool) {
+	if requireLocation && len(g.Countries) == 0 && len(g.Regions) == 0 && len(g.Cities) == 0 &&
+		len(g.Zips) == 0 && len(g.CustomLocations) == 0 && len(g.Extra) == 0 {
+		v.add(field, "must include at least one of countries, regions, cities, zips or custom_locations")
+	}
+
+	for i, c := range g.Countries {
+		if len(c) != 2 || strings.ToUpper(c) != c {
+			v.add(fmt.Sprintf("%s.countries[%d]", field, i), fmt.Sprintf("%q is not an ISO 3166 alpha-2 code", c))
+		}
+	}
+	for i, r := range g.Regions {
+		if r.Key == "" {
+			v.add(fmt.Sprintf("%s.regions[%d].key", field, i), "is required")
+		}
+	}
+	for i, z := range g.Zips {
+		if z.Key == "" {
+			v.add(fmt.Sprintf("%s.zips[%d].key", field, i), "is required")
+		}
+	}
+	for i, c := range g.Cities {
+		f := fmt.Sprintf("%s.cities[%d]", field, i)
+		if c.Key == "" {
+			v.add(f+".key", "is required")
+		}
+		if c.DistanceUnit != "" {
+			if _, ok := distanceUnits[c.DistanceUnit]; !ok {
+				v.add(f+".distance_unit", "must be mile or kilometer")
+			}
+		}
+		if c.Radius != 0 {
+			lo, hi := minCityRadiusMiles, maxCityRadiusMiles
+			if c.DistanceUnit == "kilometer" {
+				lo, hi = minCityRadiusKm, maxCityRadiusKm
+			}
+			if c.Radius < lo || c.Radius > hi {
+				v.add(f+".radius", fmt.Sprintf("must be between %d and %d", lo, hi))
+			}
+		}
+	}
+	for i, cl := range g.CustomLocations {
+		f := fmt.Sprintf("%s.custom_locations[%d]", field, i)
+		if cl.Latitude < -90 || cl.Latitude > 90 {
+			v.add(f+".latitude", "must be between -90 and 90")
+		}
+		if cl.Longitude < -180 || cl.Longitude > 180 {
+			v.add(f+".longitude", "must be between -180 and 180")
+		}
+		if cl.Radius <= 0 {
+			v.add(f+".radius", "must be positive")
+		}
+		if cl.DistanceUnit != "" {
+			if _, ok := distanceUnits[cl.DistanceUnit]; !ok {
+				v.add(f+".distance_unit", "must be mile or kilometer")
+			}
+		}
+	}
+	for i, lt := range g.LocationTypes {
+		if _, ok := locationTypes[lt]; !ok {
+			v.add(fmt.Sprintf("%s.location_types[%d]", field, i), fmt.Sprintf("unknown location type %q", lt))
+		}
+	}
+}
+
+// refs valida IDs obrigatórios e sem duplicatas; devolve o conjunto de IDs
+func (v *validator) refs(field string, refs []Ref) map[ID]struct{} {
+	seen := map[ID]struct{}{}
+	for i, r := range refs {
+		f := fmt.Sprintf("%s[%d].id", field, i)
+		if r.ID == "" {
+			v.add(f, "is required")
+			continue
+		}
+		if _, dup := seen[r.ID]; dup {
+			v.add(f, fmt.Sprintf("duplicate id %s", r.ID))
+		}
+		seen[r.ID] = struct{}{}
+	}
+	return seen
+}
+
+// flexible exige conteúdo no bloco; chaves em Extra (work_positions, industries...)
+// contam, desde que não venham vazias
+func (v *validator) flexible(field string, fs FlexibleSpec) {
+	if len(fs.Interests) == 0 && len(fs.Behaviors) == 0 && len(fs.LifeEvents) == 0 && !hasExtra(fs.Extra) {
+		v.add(field, "must include at least one targeting key (interests, behaviors, life_events...)")
+	}
+	v.refs(field+".interests", fs.Interests)
+	v.refs(field+".behaviors", fs.Behaviors)
+	v.refs(field+".life_events", fs.LifeEvents)
+}
+
+func hasExtra(extra map[string]json.RawMessage) bool {
+	for _, raw := range extra {
+		switch string(bytes.TrimSpace(raw)) {
+		case "", "null", "[]", "{}":
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// positions exige valores conhecidos e que a plataforma correspondente esteja
+// em publisher_platforms (quando publisher_platforms foi informado)
+func (v *validator) positions(field string, values, platforms []string) {
+	if len(values) == 0 {
+		return
+	}
+	rule := positions[field]
+	for i, p := range values {
+		if _, ok := rule.values[p]; !ok {
+			v.add(fmt.Sprintf("%s[%d]", field, i), fmt.Sprintf("unknown position %q", p))
+		}
+	}
+	if len(platforms) == 0 {
+		return
+	}
+	for _, p := range platforms {
+		if p == rule.platform {
+			return
+		}
+	}
+	v.add(field, fmt.Sprintf("requires %q in publisher_platforms", rule.platform))
+}
+
+func set(values ...string) map[string]struct{} {
+	m := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		m[v] = struct{}{}
+	}
+	return m
+}
@@ -0,0 +1,140 @@
+package targeting
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSpecValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       string
+		wantFields []string // nil: spec válido
+	}{
+		{
+			name: "minimal",
+			spec: `{"geo_locations": {"countries": ["BR"]}}`,
+		},
+		{
+			name: "full",
+			spec: `{
+				"geo_locations": {"countries": ["BR"], "cities": [{"key": "2430536", "radius": 25, "distance_unit": "kilometer"}], "location_types": ["home"]},
+				"age_min": 18, "age_max": 45, "genders": [2], "locales": [23],
+				"custom_audiences": [{"id": "123"}], "excluded_custom_audiences": [{"id": 456}],
+				"flexible_spec": [{"interests": [{"id": "6003139266461", "name": "Movies"}]}, {"work_positions": [{"id": "105"}]}],
+				"publisher_platforms": ["facebook", "instagram"], "facebook_positions": ["feed"], "instagram_positions": ["stream", "reels"],
+				"device_platforms": ["mobile"]
+			}`,
+		},
+		{
+			name: "geo markets only come through extra keys",
+			spec: `{"geo_locations": {"geo_markets": [{"key": "DMA:501"}]}}`,
+		},
+		{
+			name:       "missing geo_locations",
+			spec:       `{"age_min": 18}`,
+			wantFields: []string{"geo_locations"},
+		},
+		{
+			name:       "empty geo_locations",
+			spec:       `{"geo_locations": {}}`,
+			wantFields: []string{"geo_locations"},
+		},
+		{
+			name:       "invalid country and location type",
+			spec:       `{"geo_locations": {"countries": ["bra"], "location_types": ["work"]}}`,
+			wantFields: []string{"geo_locations.countries[0]", "geo_locations.location_types[0]"},
+		},
+		{
+			name:       "city radius uses the distance unit",
+			spec:       `{"geo_locations": {"cities": [{"key": "1", "radius": 60}, {"key": "2", "radius": 60, "distance_unit": "kilometer"}, {"radius": 10, "distance_unit": "feet"}]}}`,
+			wantFields: []string{"geo_locations.cities[0].radius", "geo_locations.cities[2].key", "geo_locations.cities[2].distance_unit"},
+		},
+		{
+			name:       "custom location bounds",
+			spec:       `{"geo_locations": {"custom_locations": [{"latitude": 91, "longitude": -181, "radius": 0}]}}`,
+			wantFields: []string{"geo_locations.custom_locations[0].latitude", "geo_locations.custom_locations[0].longitude", "geo_locations.custom_locations[0].radius"},
+		},
+		{
+			name:       "excluded geo does not require a location",
+			spec:       `{"geo_locations": {"countries": ["BR"]}, "excluded_geo_locations": {"countries": ["br"]}}`,
+			wantFields: []string{"excluded_geo_locations.countries[0]"},
+		},
+		{
+			name:       "age above maximum",
+			spec:       `{"geo_locations": {"countries": ["BR"]}, "age_min": 50, "age_max": 70}`,
+			wantFields: []string{"age_max"},
+		},
+		{
+			name:       "age_min above age_max",
+			spec:       `{"geo_locations": {"countries": ["BR"]}, "age_min": 40, "age_max": 30}`,
+			wantFields: []string{"age_min"},
+		},
+		{
+			name:       "age below minimum",
+			spec:       `{"geo_locations": {"countries": ["BR"]}, "age_min": 12}`,
+			wantFields: []string{"age_min"},
+		},
+		{
+			name:       "genders and locales",
+			spec:       `{"geo_locations": {"countries": ["BR"]}, "genders": [0, 1, 3], "locales": [-1]}`,
+			wantFields: []string{"genders[0]", "genders[2]", "locales[0]"},
+		},
+		{
+			name:       "audience included and excluded",
+			spec:       `{"geo_locations": {"countries": ["BR"]}, "custom_audiences": [{"id": "1"}, {"id": "1"}], "excluded_custom_audiences": [{"id": 1}]}`,
+			wantFields: []string{"custom_audiences[1].id", "excluded_custom_audiences"},
+		},
+		{
+			name:       "empty flexible block",
+			spec:       `{"geo_locations": {"countries": ["BR"]}, "flexible_spec": [{"interests": []}, {"industries": []}]}`,
+			wantFields: []string{"flexible_spec[0]", "flexible_spec[1]"},
+		},
+		{
+			name:       "exclusions need ids",
+			spec:       `{"geo_locations": {"countries": ["BR"]}, "exclusions": {"interests": [{"name": "Cats"}]}}`,
+			wantFields: []string{"exclusions.interests[0].id"},
+		},
+		{
+			name:       "unknown platforms and positions",
+			spec:       `{"geo_locations": {"countries": ["BR"]}, "publisher_platforms": ["facebook", "tiktok"], "device_platforms": ["tv"], "facebook_positions": ["banner"]}`,
+			wantFields: []string{"publisher_platforms[1]", "device_platforms[0]", "facebook_positions[0]"},
+		},
+		{
+			name:       "position without its platform",
+			spec:       `{"geo_locations": {"countries": ["BR"]}, "publisher_platforms": ["facebook"], "instagram_positions": ["stream"]}`,
+			wantFields: []string{"instagram_positions"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Spec
+			if err := json.Unmarshal([]byte(tt.spec), &s); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+
+			err := s.Validate()
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("Validate() = %v, want *ValidationError", err)
+			}
+			var fields []string
+			for _, fe := range ve.Errors {
+				fields = append(fields, fe.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}